@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -12,28 +18,71 @@ import (
 	"github.com/Educentr/go-project-starter/internal/pkg/config"
 	"github.com/Educentr/go-project-starter/internal/pkg/generator"
 	projinit "github.com/Educentr/go-project-starter/internal/pkg/init"
+	"github.com/Educentr/go-project-starter/internal/pkg/logging"
 	"github.com/Educentr/go-project-starter/internal/pkg/meta"
 	"github.com/Educentr/go-project-starter/internal/pkg/migrate"
 	"github.com/Educentr/go-project-starter/internal/pkg/setup"
+	"github.com/Educentr/go-project-starter/internal/pkg/topology"
+	"github.com/Educentr/go-project-starter/internal/pkg/watch"
 )
 
 const (
-	msgConfig         = "used config path ="
+	msgConfig         = "used config"
 	cmdSetup          = "setup"
 	cmdInit           = "init"
 	cmdMigrate        = "migrate"
 	cmdVersion        = "version"
+	cmdDiff           = "diff"
+	cmdListTemplates  = "list-templates"
+	cmdWatch          = "watch"
+	cmdConfig         = "config"
+	cmdConfigRender   = "render"
+	cmdConfigSchema   = "schema"
+	cmdValidate       = "validate"
+	cmdGraph          = "graph"
 	defaultConfigDir  = ".project-config"
 	defaultConfigFile = "project.yaml"
 	flagConfig        = "config"
 	flagDryRun        = "dry-run"
+	flagDiff          = "diff"
+	flagForce         = "force"
+	flagAllowDirty    = "allow-dirty"
+	flagBackup        = "backup"
+	flagAdopt         = "adopt"
+	flagTemplatesDir  = "templates-dir"
+	flagOnly          = "only"
+	flagDebounce      = "debounce"
+	flagPostGenerate  = "post-generate"
+	flagReport        = "report"
+	flagTo            = "to"
+	flagEnv           = "env"
+	flagFormat        = "format"
+	flagVerbose       = "verbose"
+	flagQuiet         = "quiet"
+	flagLogFormat     = "log-format"
+	usageEnv          = "Apply the overrides.<env> section of the config"
+	usageOnly         = "Regenerate only the selected files: app=<name>, transport=<name> or path=<glob>; repeat to combine"
+	usageTemplatesDir = "directory with templates overriding or extending the embedded ones (overrides main.templates_dir)"
+	usageAdopt        = "Move content of generated files that lost the disclaimer, and user code of stale generated files, to .orphan files instead of failing"
 	usageConfigFile   = "project configuration file"
+	usageConfigDir    = "project configuration directory"
+	usageTargetDir    = "target directory"
+
+	// Exit codes of diff follow diff(1): 0 - no changes, 1 - changes, 2 - error
+	exitCodeChanges = 1
+	exitCodeTrouble = 2
 
 	layoutFailedToBindFlags       = "failed to bind flags: %v"
 	layoutFailedToLoadConfig      = "failed to load config: %v"
 	layoutFailedToLoadMeta        = "failed to load meta: %v"
 	layoutFailedToCreateGenerator = "failed to create generator: %v"
 	layoutFailedToGenerate        = "failed to generate: %v"
+	layoutFailedToDiff            = "failed to diff: %v"
+	layoutFailedToListTemplates   = "failed to list templates: %v"
+	layoutFailedToWatch           = "failed to watch: %v"
+	layoutFailedToRenderConfig    = "failed to render config: %v"
+	layoutFailedToValidate        = "failed to validate config: %v"
+	layoutFailedToGraph           = "failed to draw graph: %v"
 	layoutFailedToSetup           = "failed to run setup: %v"
 	layoutFailedToInit            = "failed to run init: %v"
 	layoutFailedToMigrate         = "failed to migrate config: %v"
@@ -59,6 +108,30 @@ func main() {
 		case cmdMigrate:
 			runMigrate()
 
+			return
+		case cmdDiff:
+			runDiff()
+
+			return
+		case cmdListTemplates:
+			runListTemplates()
+
+			return
+		case cmdWatch:
+			runWatch()
+
+			return
+		case cmdConfig:
+			runConfig()
+
+			return
+		case cmdValidate:
+			runValidate()
+
+			return
+		case cmdGraph:
+			runGraph()
+
 			return
 		case cmdVersion:
 			fmt.Printf("go-project-starter %s\ncommit: %s\nbuilt: %s\n", version, commit, buildDate)
@@ -73,6 +146,7 @@ func main() {
 func runSetup() {
 	// Setup command flags
 	setupFlags := pflag.NewFlagSet(cmdSetup, pflag.ExitOnError)
+	logOpts := addLogFlags(setupFlags)
 
 	var (
 		configDir string
@@ -86,9 +160,11 @@ func runSetup() {
 
 	// Parse flags after "setup" command
 	if err := setupFlags.Parse(os.Args[2:]); err != nil {
-		log.Fatalf("failed to parse setup flags: %v", err)
+		fatalf("failed to parse setup flags: %v", err)
 	}
 
+	setupLogging(logOpts)
+
 	// Determine subcommand (ci, server, deploy, or none for full wizard)
 	args := setupFlags.Args()
 
@@ -103,7 +179,7 @@ func runSetup() {
 		case "deploy":
 			cmd = setup.CommandDeploy
 		default:
-			log.Fatalf("unknown setup subcommand: %s\nUsage: go-project-starter setup [ci|server|deploy] [flags]", args[0])
+			fatalf("unknown setup subcommand: %s, usage: go-project-starter setup [ci|server|deploy] [flags]", args[0])
 		}
 	}
 
@@ -114,18 +190,19 @@ func runSetup() {
 		DryRun:    dryRun,
 	})
 	if err != nil {
-		log.Fatalf(layoutFailedToSetup, err)
+		fatalf(layoutFailedToSetup, err)
 	}
 
 	// Run setup
 	if err := s.Run(cmd); err != nil {
-		log.Fatalf(layoutFailedToSetup, err)
+		fatalf(layoutFailedToSetup, err)
 	}
 }
 
 func runInit() {
 	// Init command flags
 	initFlags := pflag.NewFlagSet(cmdInit, pflag.ExitOnError)
+	logOpts := addLogFlags(initFlags)
 
 	var (
 		configDir string
@@ -137,9 +214,11 @@ func runInit() {
 
 	// Parse flags after "init" command
 	if err := initFlags.Parse(os.Args[2:]); err != nil {
-		log.Fatalf("failed to parse init flags: %v", err)
+		fatalf("failed to parse init flags: %v", err)
 	}
 
+	setupLogging(logOpts)
+
 	// Create init instance
 	i := projinit.New(projinit.Options{
 		ConfigDir: configDir,
@@ -148,29 +227,34 @@ func runInit() {
 
 	// Run init
 	if err := i.Run(); err != nil {
-		log.Fatalf(layoutFailedToInit, err)
+		fatalf(layoutFailedToInit, err)
 	}
 }
 
 func runMigrate() {
 	// Migrate command flags
 	migrateFlags := pflag.NewFlagSet(cmdMigrate, pflag.ExitOnError)
+	logOpts := addLogFlags(migrateFlags)
 
 	var (
 		configDir  string
 		configFile string
 		dryRun     bool
+		toVersion  string
 	)
 
 	migrateFlags.StringVar(&configDir, "configDir", defaultConfigDir, "project configuration directory")
 	migrateFlags.StringVar(&configFile, flagConfig, defaultConfigFile, usageConfigFile)
 	migrateFlags.BoolVar(&dryRun, flagDryRun, false, "Dry run mode - show what would be changed")
+	migrateFlags.StringVar(&toVersion, flagTo, "", "Apply migrations up to this generator version, all of them if not set")
 
 	// Parse flags after "migrate" command
 	if err := migrateFlags.Parse(os.Args[2:]); err != nil {
-		log.Fatalf("failed to parse migrate flags: %v", err)
+		fatalf("failed to parse migrate flags: %v", err)
 	}
 
+	setupLogging(logOpts)
+
 	// Find config file
 	configPath := migrate.FindConfigFile(configDir, configFile)
 
@@ -183,9 +267,15 @@ func runMigrate() {
 	// Create migrator and run
 	m := migrate.New(configPath, dryRun)
 
+	if toVersion != "" {
+		if err := m.SetTargetVersion(toVersion); err != nil {
+			fatalf(layoutFailedToMigrate, err)
+		}
+	}
+
 	result, err := m.Migrate()
 	if err != nil {
-		log.Fatalf(layoutFailedToMigrate, err)
+		fatalf(layoutFailedToMigrate, err)
 	}
 
 	if !result.Modified {
@@ -210,62 +300,617 @@ func runMigrate() {
 	}
 }
 
+func runDiff() {
+	// Diff command flags
+	diffFlags := pflag.NewFlagSet(cmdDiff, pflag.ExitOnError)
+	logOpts := addLogFlags(diffFlags)
+
+	var (
+		configDir    string
+		cfgPath      string
+		targetDir    string
+		templatesDir string
+		adopt        bool
+		only         []string
+		env          string
+	)
+
+	diffFlags.StringVar(&configDir, "configDir", defaultConfigDir, usageConfigDir)
+	diffFlags.StringVar(&cfgPath, flagConfig, defaultConfigFile, usageConfigFile)
+	diffFlags.StringVar(&targetDir, "target", "", usageTargetDir)
+	diffFlags.StringVar(&templatesDir, flagTemplatesDir, "", usageTemplatesDir)
+	diffFlags.BoolVar(&adopt, flagAdopt, false, usageAdopt)
+	diffFlags.StringArrayVar(&only, flagOnly, nil, usageOnly)
+	diffFlags.StringVar(&env, flagEnv, "", usageEnv)
+
+	// Parse flags after "diff" command
+	if err := diffFlags.Parse(os.Args[2:]); err != nil {
+		slog.Error(fmt.Sprintf("failed to parse diff flags: %v", err))
+		os.Exit(exitCodeTrouble)
+	}
+
+	setupLogging(logOpts)
+
+	gen, err := newGenerator(configDir, cfgPath, targetDir, templatesDir, env, true)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(exitCodeTrouble)
+	}
+
+	gen.Adopt = adopt
+
+	if gen.Only, err = parseOnly(only); err != nil {
+		slog.Error(err.Error())
+		os.Exit(exitCodeTrouble)
+	}
+
+	printDiff(gen)
+}
+
+func runListTemplates() {
+	// List-templates command flags
+	listFlags := pflag.NewFlagSet(cmdListTemplates, pflag.ExitOnError)
+	logOpts := addLogFlags(listFlags)
+
+	var (
+		configDir    string
+		cfgPath      string
+		targetDir    string
+		templatesDir string
+	)
+
+	listFlags.StringVar(&configDir, "configDir", defaultConfigDir, usageConfigDir)
+	listFlags.StringVar(&cfgPath, flagConfig, defaultConfigFile, usageConfigFile)
+	listFlags.StringVar(&targetDir, "target", "", usageTargetDir)
+	listFlags.StringVar(&templatesDir, flagTemplatesDir, "", usageTemplatesDir)
+
+	// Parse flags after "list-templates" command
+	if err := listFlags.Parse(os.Args[2:]); err != nil {
+		fatalf("failed to parse list-templates flags: %v", err)
+	}
+
+	setupLogging(logOpts)
+
+	gen, err := newGenerator(configDir, cfgPath, targetDir, templatesDir, "", true)
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := gen.ListTemplates(os.Stdout); err != nil {
+		fatalf(layoutFailedToListTemplates, err)
+	}
+}
+
+func runWatch() {
+	// Watch command flags
+	watchFlags := pflag.NewFlagSet(cmdWatch, pflag.ExitOnError)
+	logOpts := addLogFlags(watchFlags)
+
+	var (
+		configDir    string
+		cfgPath      string
+		targetDir    string
+		templatesDir string
+		adopt        bool
+		allowDirty   bool
+		only         []string
+		postGenerate []string
+		debounce     time.Duration
+		env          string
+	)
+
+	watchFlags.StringVar(&configDir, "configDir", defaultConfigDir, usageConfigDir)
+	watchFlags.StringVar(&cfgPath, flagConfig, defaultConfigFile, usageConfigFile)
+	watchFlags.StringVar(&targetDir, "target", "", usageTargetDir)
+	watchFlags.StringVar(&templatesDir, flagTemplatesDir, "", usageTemplatesDir)
+	watchFlags.BoolVar(&adopt, flagAdopt, false, usageAdopt)
+	watchFlags.BoolVar(&allowDirty, flagAllowDirty, false, "Start watching even if the target has uncommitted git changes")
+	watchFlags.StringArrayVar(&only, flagOnly, nil, usageOnly)
+	watchFlags.StringSliceVar(&postGenerate, flagPostGenerate, nil, "post_generate steps of the config to run after each regeneration (none by default)")
+	watchFlags.StringVar(&env, flagEnv, "", usageEnv)
+	watchFlags.DurationVar(&debounce, flagDebounce, watch.DefaultDebounce, "Wait for the files to stay unchanged this long before regenerating")
+
+	// Parse flags after "watch" command
+	if err := watchFlags.Parse(os.Args[2:]); err != nil {
+		fatalf("failed to parse watch flags: %v", err)
+	}
+
+	setupLogging(logOpts)
+
+	filters, err := parseOnly(only)
+	if err != nil {
+		fatal(err)
+	}
+
+	cfgDir := configDir
+	if !filepath.IsAbs(configDir) {
+		cfgDir = filepath.Join(targetDir, configDir)
+	}
+
+	// The config file is watched even if it does not load, the last good specs are watched with it
+	sources := []string{config.ConfigFile(cfgDir, cfgPath)}
+
+	cycle := func(changed []string) []string {
+		if len(changed) > 0 {
+			slog.Info("changed", "files", changed)
+		}
+
+		start := time.Now()
+
+		gen, err := newGenerator(configDir, cfgPath, targetDir, templatesDir, env, false)
+		if err != nil {
+			slog.Error(err.Error())
+
+			return sources
+		}
+
+		sources = gen.SourcePaths
+
+		// The first generation checks the target as usual, after it the target changes with every cycle
+		// and is not committed in between
+		gen.AllowDirty = allowDirty
+		gen.Adopt = adopt
+		gen.Only = filters
+
+		if err = gen.SelectPostGenerate(postGenerate); err != nil {
+			slog.Error(err.Error())
+
+			return sources
+		}
+
+		if err = gen.Generate(); err != nil {
+			slog.Error(fmt.Sprintf(layoutFailedToGenerate, err))
+
+			return sources
+		}
+
+		allowDirty = true
+
+		printChanges(gen.Report.Changes, time.Since(start))
+		slog.Info("watching", "files", len(sources))
+
+		return sources
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := watch.Run(ctx, debounce, cycle); err != nil {
+		fatalf(layoutFailedToWatch, err)
+	}
+}
+
+func runConfig() {
+	if len(os.Args) < 3 || (os.Args[2] != cmdConfigRender && os.Args[2] != cmdConfigSchema) {
+		fatalf("Usage: go-project-starter %s [%s|%s] [flags]", cmdConfig, cmdConfigRender, cmdConfigSchema)
+	}
+
+	if os.Args[2] == cmdConfigSchema {
+		out, err := config.Schema()
+		if err != nil {
+			fatalf("failed to generate schema: %v", err)
+		}
+
+		if _, err = os.Stdout.Write(out); err != nil {
+			fatalf("failed to generate schema: %v", err)
+		}
+
+		return
+	}
+
+	// Config render command flags
+	renderFlags := pflag.NewFlagSet(cmdConfig+" "+cmdConfigRender, pflag.ExitOnError)
+	logOpts := addLogFlags(renderFlags)
+
+	var (
+		configDir string
+		cfgPath   string
+		targetDir string
+		env       string
+	)
+
+	renderFlags.StringVar(&configDir, "configDir", defaultConfigDir, usageConfigDir)
+	renderFlags.StringVar(&cfgPath, flagConfig, defaultConfigFile, usageConfigFile)
+	renderFlags.StringVar(&targetDir, "target", "", usageTargetDir)
+	renderFlags.StringVar(&env, flagEnv, "", usageEnv)
+
+	// Parse flags after "config render" command
+	if err := renderFlags.Parse(os.Args[3:]); err != nil {
+		fatalf("failed to parse config render flags: %v", err)
+	}
+
+	setupLogging(logOpts)
+
+	cfgDir := configDir
+	if !filepath.IsAbs(configDir) {
+		cfgDir = filepath.Join(targetDir, configDir)
+	}
+
+	out, err := config.Render(cfgDir, cfgPath, env)
+	if err != nil {
+		fatalf(layoutFailedToRenderConfig, err)
+	}
+
+	if _, err = os.Stdout.Write(out); err != nil {
+		fatalf(layoutFailedToRenderConfig, err)
+	}
+}
+
+func runValidate() {
+	// Validate command flags
+	validateFlags := pflag.NewFlagSet(cmdValidate, pflag.ExitOnError)
+	logOpts := addLogFlags(validateFlags)
+
+	var (
+		configDir string
+		cfgPath   string
+		targetDir string
+		env       string
+		format    string
+	)
+
+	validateFlags.StringVar(&configDir, "configDir", defaultConfigDir, usageConfigDir)
+	validateFlags.StringVar(&cfgPath, flagConfig, defaultConfigFile, usageConfigFile)
+	validateFlags.StringVar(&targetDir, "target", "", usageTargetDir)
+	validateFlags.StringVar(&env, flagEnv, "", usageEnv)
+	validateFlags.StringVar(&format, flagFormat, "text", "Output format: text or json")
+
+	// Parse flags after "validate" command
+	if err := validateFlags.Parse(os.Args[2:]); err != nil {
+		fatalf("failed to parse validate flags: %v", err)
+	}
+
+	setupLogging(logOpts)
+
+	if format != "text" && format != "json" {
+		fatalf("unknown --%s %q, expected text or json", flagFormat, format)
+	}
+
+	cfgDir := configDir
+	if !filepath.IsAbs(configDir) {
+		cfgDir = filepath.Join(targetDir, configDir)
+	}
+
+	problems, err := config.Validate(cfgDir, cfgPath, env)
+	if err != nil {
+		fatalf(layoutFailedToValidate, err)
+	}
+
+	for i := range problems {
+		problems[i].File = relativePath(problems[i].File)
+	}
+
+	if format == "json" {
+		if problems == nil {
+			problems = []config.Problem{}
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		if err = enc.Encode(problems); err != nil {
+			fatalf(layoutFailedToValidate, err)
+		}
+	} else {
+		printProblems(problems)
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runGraph() {
+	// Graph command flags
+	graphFlags := pflag.NewFlagSet(cmdGraph, pflag.ExitOnError)
+	logOpts := addLogFlags(graphFlags)
+
+	var (
+		configDir string
+		cfgPath   string
+		targetDir string
+		env       string
+		format    string
+	)
+
+	graphFlags.StringVar(&configDir, "configDir", defaultConfigDir, usageConfigDir)
+	graphFlags.StringVar(&cfgPath, flagConfig, defaultConfigFile, usageConfigFile)
+	graphFlags.StringVar(&targetDir, "target", "", usageTargetDir)
+	graphFlags.StringVar(&env, flagEnv, "", usageEnv)
+	graphFlags.StringVar(&format, flagFormat, topology.FormatMermaid,
+		"Output format: "+strings.Join(topology.Formats, ", "))
+
+	// Parse flags after "graph" command
+	if err := graphFlags.Parse(os.Args[2:]); err != nil {
+		fatalf("failed to parse graph flags: %v", err)
+	}
+
+	setupLogging(logOpts)
+
+	gen, err := newGenerator(configDir, cfgPath, targetDir, "", env, true)
+	if err != nil {
+		fatal(err)
+	}
+
+	if err = gen.Topology().Write(os.Stdout, format); err != nil {
+		fatalf(layoutFailedToGraph, err)
+	}
+}
+
+// printProblems writes the problems of the config with their fixes
+func printProblems(problems []config.Problem) {
+	if len(problems) == 0 {
+		fmt.Println("config is valid")
+
+		return
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+
+		if problem.Fix != "" {
+			fmt.Printf("    fix: %s\n", problem.Fix)
+		}
+	}
+
+	fmt.Printf("%d problem(s) found\n", len(problems))
+}
+
+// relativePath returns path relative to the working directory if it is inside it
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil || path == "" {
+		return path
+	}
+
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+
+	return rel
+}
+
+// printChanges writes the summary of a watch cycle: counts and the changed files
+func printChanges(changes generator.Changes, elapsed time.Duration) {
+	fmt.Printf("regenerated in %s: %s\n", elapsed.Round(time.Millisecond), changes)
+
+	renamed := make([]string, 0, len(changes.Renamed))
+	for _, rename := range changes.Renamed {
+		renamed = append(renamed, rename.String())
+	}
+
+	for _, group := range []struct {
+		mark  string
+		files []string
+	}{
+		{"+", changes.Created},
+		{"~", changes.Updated},
+		{">", renamed},
+		{"-", changes.Removed},
+	} {
+		for _, file := range group.files {
+			fmt.Printf("  %s %s\n", group.mark, file)
+		}
+	}
+}
+
+// printDiff writes the pending changes to stdout and exits with 1 if there are any
+func printDiff(gen *generator.Generator) {
+	changed, err := gen.Diff(os.Stdout)
+	if err != nil {
+		slog.Error(fmt.Sprintf(layoutFailedToDiff, err))
+		os.Exit(exitCodeTrouble)
+	}
+
+	if changed {
+		os.Exit(exitCodeChanges)
+	}
+}
+
+// parseOnly parses the --only filters
+func parseOnly(values []string) ([]generator.ScopeFilter, error) {
+	filters := make([]generator.ScopeFilter, 0, len(values))
+
+	for _, value := range values {
+		filter, err := generator.ParseScopeFilter(value)
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %w", flagOnly, err)
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// newGenerator loads config and meta the same way for generation and diff
+func newGenerator(baseConfigDir, cfgPath, targetDir, templatesDir, env string, dryRun bool) (*generator.Generator, error) {
+	slog.Debug(msgConfig, "path", cfgPath, "dir", baseConfigDir, "env", env)
+
+	cfgDir := baseConfigDir
+	if !filepath.IsAbs(baseConfigDir) {
+		cfgDir = filepath.Join(targetDir, baseConfigDir)
+	}
+
+	cfg, err := config.GetConfigEnv(cfgDir, cfgPath, env)
+	cfg.LogDeprecations(slog.Default())
+
+	if err != nil {
+		return nil, fmt.Errorf(layoutFailedToLoadConfig, err)
+	}
+
+	slog.Debug("load config", "file", cfg.ConfigFilePath, "includes", cfg.IncludePaths, "env", env)
+
+	// Meta is always stored in target directory's .project-config
+	metaDir := filepath.Join(targetDir, ".project-config")
+
+	genMeta, err := meta.GetMeta(metaDir, "meta.yaml")
+	if err != nil {
+		return nil, fmt.Errorf(layoutFailedToLoadMeta, err)
+	}
+
+	if targetDir != "" {
+		cfg.SetTargetDir(targetDir)
+	}
+
+	appInfo := fmt.Sprintf("go-project-starter-%s", version)
+
+	gen, err := generator.New(appInfo, cfg, genMeta, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf(layoutFailedToCreateGenerator, err)
+	}
+
+	if templatesDir != "" {
+		gen.TemplatesDir = templatesDir
+	}
+
+	return gen, nil
+}
+
 func runGenerator() {
 	var (
 		gen           *generator.Generator
-		cfg           config.Config
-		genMeta       meta.Meta
 		cfgPath       string
 		targetDir     string
 		baseConfigDir string
 		err           error
 		dryRun        bool
+		diff          bool
+		force         bool
+		allowDirty    bool
+		backup        string
+		adopt         bool
+		templatesDir  string
+		only          []string
+		report        string
+		env           string
 	)
 
-	pflag.StringVar(&baseConfigDir, "configDir", defaultConfigDir, "project configuration directory")
+	pflag.StringVar(&baseConfigDir, "configDir", defaultConfigDir, usageConfigDir)
 	pflag.StringVar(&cfgPath, flagConfig, defaultConfigFile, usageConfigFile)
-	pflag.StringVar(&targetDir, "target", "", "target directory")
+	pflag.StringVar(&targetDir, "target", "", usageTargetDir)
 	pflag.BoolVar(&dryRun, flagDryRun, false, "Dry run")
+	pflag.BoolVar(&diff, flagDiff, false, "With --dry-run print a unified diff instead of the list of changes")
+	pflag.BoolVar(&force, flagForce, false, "Overwrite hand-edited generated files instead of merging, saving copies to .project-config/backup")
+	pflag.BoolVar(&allowDirty, flagAllowDirty, false, "Regenerate even if the target has uncommitted git changes")
+	pflag.StringVar(&templatesDir, flagTemplatesDir, "", usageTemplatesDir)
+	pflag.BoolVar(&adopt, flagAdopt, false, usageAdopt+" (implied by --force)")
+	pflag.StringArrayVar(&only, flagOnly, nil, usageOnly)
+	pflag.StringVar(&env, flagEnv, "", usageEnv)
+	pflag.StringVar(&report, flagReport, "", "Write a report of the generation to stdout: json")
+	pflag.StringVar(&backup, flagBackup, "", "Back up the target before writing: archive (.project-config/backup/*.tar.gz) or branch (git branch psg-backup/*)")
+
+	logOpts := addLogFlags(pflag.CommandLine)
 
 	pflag.Parse()
 
+	setupLogging(logOpts)
+
 	if err = viper.BindPFlags(pflag.CommandLine); err != nil {
-		log.Fatalf(layoutFailedToBindFlags, err)
+		fatalf(layoutFailedToBindFlags, err)
 	}
 
-	log.Println(msgConfig, cfgPath)
+	if diff && !dryRun {
+		fatalf("--%s requires --%s", flagDiff, flagDryRun)
+	}
 
-	cfgDir := baseConfigDir
-	if !filepath.IsAbs(baseConfigDir) {
-		cfgDir = filepath.Join(targetDir, baseConfigDir)
+	if report != "" && report != generator.ReportFormatJSON {
+		fatalf("--%s: unknown format %q, expected %s", flagReport, report, generator.ReportFormatJSON)
 	}
 
-	if cfg, err = config.GetConfig(cfgDir, cfgPath); err != nil {
-		log.Fatalf(layoutFailedToLoadConfig, err)
+	if report != "" && diff {
+		fatalf("--%s can not be used with --%s", flagReport, flagDiff)
 	}
 
-	// Meta is always stored in target directory's .project-config
-	metaDir := filepath.Join(targetDir, ".project-config")
-	if genMeta, err = meta.GetMeta(metaDir, "meta.yaml"); err != nil {
-		log.Fatalf(layoutFailedToLoadMeta, err)
+	if gen, err = newGenerator(baseConfigDir, cfgPath, targetDir, templatesDir, env, dryRun); err != nil {
+		fatal(err)
 	}
 
-	if targetDir != "" {
-		cfg.SetTargetDir(targetDir)
+	gen.Force = force
+	gen.AllowDirty = allowDirty
+	gen.Backup = backup
+	gen.Adopt = adopt || force
+
+	if gen.Only, err = parseOnly(only); err != nil {
+		fatal(err)
 	}
 
-	appInfo := fmt.Sprintf("go-project-starter-%s", version)
-	if gen, err = generator.New(appInfo, cfg, genMeta, dryRun); err != nil {
-		log.Fatalf(layoutFailedToCreateGenerator, err)
+	slog.Debug("generator",
+		"target", gen.TargetDir,
+		"dry_run", gen.DryRun,
+		"force", gen.Force,
+		"allow_dirty", gen.AllowDirty,
+		"adopt", gen.Adopt,
+		"backup", gen.Backup,
+		"only", only,
+		"templates_dir", gen.TemplatesDir,
+		"post_generate", len(gen.PostGenerate),
+	)
+
+	if diff {
+		printDiff(gen)
+
+		return
 	}
 
-	// ToDo debug log
-	// Прикрутить логгер, сделать уровни логирования и добавить эту секцию как Debug
-	// log.Printf("Generator: %+v", gen)
+	if report != "" {
+		writeReport(gen)
+
+		return
+	}
 
 	if err = gen.Generate(); err != nil {
-		log.Fatalf(layoutFailedToGenerate, err)
+		fatalf(layoutFailedToGenerate, err)
+	}
+}
+
+// addLogFlags adds the flags of the log shared by all commands
+func addLogFlags(flags *pflag.FlagSet) *logging.Options {
+	opts := &logging.Options{}
+
+	flags.BoolVar(&opts.Verbose, flagVerbose, false, "Log debug messages: config loading, every file rendered, copied or skipped")
+	flags.BoolVar(&opts.Quiet, flagQuiet, false, "Log warnings and errors only")
+	flags.StringVar(&opts.Format, flagLogFormat, logging.FormatText, "Log format: "+strings.Join(logging.Formats, " or "))
+
+	return opts
+}
+
+// setupLogging makes the logger of opts the default one, the log goes to stderr
+func setupLogging(opts *logging.Options) {
+	logger, err := logging.New(os.Stderr, *opts)
+	if err != nil {
+		fatalf("--%s, --%s, --%s: %v", flagVerbose, flagQuiet, flagLogFormat, err)
+	}
+
+	slog.SetDefault(logger)
+}
+
+// fatalf logs the error and exits with 1
+func fatalf(format string, args ...any) {
+	slog.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+func fatal(err error) {
+	fatalf("%v", err)
+}
+
+// writeReport generates and writes the JSON report to stdout, the dry run listing goes to stderr.
+// A failed generation is reported too, with the error, and exits with 1.
+func writeReport(gen *generator.Generator) {
+	gen.Output = os.Stderr
+
+	genErr := gen.Generate()
+	if genErr != nil {
+		gen.Report.Error = genErr.Error()
+	}
+
+	if err := gen.Report.WriteJSON(os.Stdout); err != nil {
+		fatalf("failed to write report: %v", err)
 	}
 
-	log.Println("done")
+	if genErr != nil {
+		fatalf(layoutFailedToGenerate, genErr)
+	}
 }
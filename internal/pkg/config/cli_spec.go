@@ -2,9 +2,10 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 	"strings"
 
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
@@ -47,9 +48,9 @@ var validFlagTypes = map[string]bool{
 	"duration": true,
 }
 
-// ParseCLISpec reads and parses a CLI spec YAML file
-func ParseCLISpec(path string) (*CLISpec, error) {
-	data, err := os.ReadFile(path)
+// ParseCLISpec reads and parses a CLI spec YAML file, from fsys or the OS file system if fsys is nil
+func ParseCLISpec(fsys fs.FS, path string) (*CLISpec, error) {
+	data, err := tools.ReadFile(fsys, path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read CLI spec file: %s", path)
 	}
@@ -0,0 +1,383 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of project.yaml handled while composing the config, they are not part of the result
+const (
+	keyInclude   = "include"
+	keyOverrides = "overrides"
+)
+
+// rawKeys are the keys whose values are not interpolated: ${VAR} in them is a placeholder
+// the templates pass on, e.g. documentation.headers become GitHub secrets in the CI workflow
+var rawKeys = map[string]struct{}{
+	"documentation.headers": {},
+}
+
+// interpolationRe matches $$, ${VAR} and ${VAR:-default}
+var interpolationRe = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// composedConfig is project.yaml with its includes, environment variables and environment overlay applied
+type composedConfig struct {
+	fsys     fs.FS                 // file system the files are read from, nil for the OS one
+	root     *yaml.Node            // mapping node of the whole config
+	includes []string              // included files, in load order
+	files    map[*yaml.Node]string // file of every node, for positions of validation problems
+}
+
+// composeConfig reads the config file, merges the files it includes, interpolates environment variables
+// and applies overrides.<env> if env is set.
+//
+// Included files are merged under the including one: its values win, lists of both are concatenated.
+// The overlay wins over the config: its lists replace the lists of the config, except lists of named
+// items (applications, rest...) where items are merged by name.
+func composeConfig(fsys fs.FS, path, env string) (composedConfig, error) {
+	composed := composedConfig{fsys: fsys, files: map[*yaml.Node]string{}}
+
+	root, err := composed.load(path, nil)
+	if err != nil {
+		return composed, err
+	}
+
+	overrides := tools.MappingValue(root, keyOverrides)
+	tools.RemoveMappingKey(root, keyOverrides)
+
+	if env != "" {
+		overlay := tools.MappingValue(overrides, env)
+		if overlay == nil {
+			return composed, fmt.Errorf("%s: no %s.%s section", path, keyOverrides, env)
+		}
+
+		if overlay.Kind != yaml.MappingNode {
+			return composed, fmt.Errorf("%s: %s.%s must be a mapping", path, keyOverrides, env)
+		}
+
+		if err := interpolate(overlay, ""); err != nil {
+			return composed, errors.Wrapf(err, "%s: %s.%s", path, keyOverrides, env)
+		}
+
+		mergeOverlay(root, overlay)
+	}
+
+	composed.root = root
+
+	return composed, nil
+}
+
+// load reads a config file with its includes, stack holds the files including it to catch cycles
+func (c *composedConfig) load(path string, stack []string) (*yaml.Node, error) {
+	absPath := filepath.Clean(path)
+	if c.fsys == nil {
+		var err error
+
+		if absPath, err = filepath.Abs(path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, parent := range stack {
+		if parent == absPath {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), absPath)
+		}
+	}
+
+	source, err := tools.ReadFile(c.fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: config must be a mapping", path)
+	}
+
+	c.record(root, path)
+
+	// Overlays are interpolated once selected, variables of other environments need not be set
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == keyOverrides {
+			continue
+		}
+
+		if err := interpolate(root.Content[i+1], root.Content[i].Value); err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+	}
+
+	patterns, err := includePatterns(tools.MappingValue(root, keyInclude))
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+
+	tools.RemoveMappingKey(root, keyInclude)
+
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+
+		matches, err := c.glob(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: include %s", path, pattern)
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: include %s: no such file", path, pattern)
+		}
+
+		for _, match := range matches {
+			included, err := c.load(match, append(stack, absPath))
+			if err != nil {
+				return nil, err
+			}
+
+			c.includes = append(c.includes, match)
+
+			mergeInclude(root, included)
+		}
+	}
+
+	return root, nil
+}
+
+// glob returns the files matching pattern
+func (c *composedConfig) glob(pattern string) ([]string, error) {
+	if c.fsys == nil {
+		return filepath.Glob(pattern)
+	}
+
+	return fs.Glob(c.fsys, filepath.ToSlash(pattern))
+}
+
+// record remembers path as the file of node and its children
+func (c *composedConfig) record(node *yaml.Node, path string) {
+	c.files[node] = path
+
+	for _, child := range node.Content {
+		c.record(child, path)
+	}
+}
+
+// includePatterns returns the files of the include key: a path or a list of paths, globs allowed
+func includePatterns(node *yaml.Node) ([]string, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}, nil
+	case yaml.SequenceNode:
+		patterns := make([]string, 0, len(node.Content))
+
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: %s must be a list of paths", item.Line, keyInclude)
+			}
+
+			patterns = append(patterns, item.Value)
+		}
+
+		return patterns, nil
+	}
+
+	return nil, fmt.Errorf("line %d: %s must be a path or a list of paths", node.Line, keyInclude)
+}
+
+// interpolate replaces ${VAR} and ${VAR:-default} in scalar values with environment variables, $$ with $.
+// A plain scalar is resolved again after interpolation, so `port: ${PORT:-8080}` is a number.
+// Path is the dot-separated key of node in the config, values of rawKeys are kept as is.
+func interpolate(node *yaml.Node, path string) error {
+	if _, raw := rawKeys[path]; raw {
+		return nil
+	}
+
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "$") {
+		var missing []string
+
+		value := interpolationRe.ReplaceAllStringFunc(node.Value, func(match string) string {
+			if match == "$$" {
+				return "$"
+			}
+
+			groups := interpolationRe.FindStringSubmatch(match)
+
+			if value, ok := os.LookupEnv(groups[1]); ok && (value != "" || groups[2] == "") {
+				return value
+			}
+
+			if groups[2] != "" {
+				return groups[3]
+			}
+
+			missing = append(missing, groups[1])
+
+			return match
+		})
+
+		if len(missing) > 0 {
+			return fmt.Errorf("line %d: environment variable %s is not set, use ${%s:-default} for a default",
+				node.Line, missing[0], missing[0])
+		}
+
+		if value != node.Value && node.Style == 0 {
+			node.Tag = ""
+		}
+
+		node.Value = value
+	}
+
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+
+			if err := interpolate(node.Content[i+1], key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, child := range node.Content {
+		if err := interpolate(child, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeInclude merges an included mapping into dst: values of dst win, lists are concatenated
+func mergeInclude(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		existing := tools.MappingValue(dst, key.Value)
+
+		switch {
+		case existing == nil:
+			dst.Content = append(dst.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeInclude(existing, value)
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			existing.Content = append(existing.Content, value.Content...)
+		}
+	}
+}
+
+// mergeOverlay merges an environment overlay into dst: values of the overlay win, lists replace lists
+// except lists of named items, merged by name
+func mergeOverlay(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		existing := tools.MappingValue(dst, key.Value)
+
+		switch {
+		case existing == nil:
+			dst.Content = append(dst.Content, key, value)
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeOverlay(existing, value)
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode && namedItems(existing) && namedItems(value):
+			mergeNamedItems(existing, value)
+		default:
+			// The node itself is replaced: it keeps the position of the overlay value
+			tools.SetMappingValue(dst, key.Value, value)
+		}
+	}
+}
+
+// mergeNamedItems merges items of src into the items of dst with the same name, other items are appended
+func mergeNamedItems(dst, src *yaml.Node) {
+	for _, item := range src.Content {
+		name := tools.MappingValue(item, "name").Value
+
+		var target *yaml.Node
+
+		for _, existing := range dst.Content {
+			if tools.MappingValue(existing, "name").Value == name {
+				target = existing
+
+				break
+			}
+		}
+
+		if target == nil {
+			dst.Content = append(dst.Content, item)
+
+			continue
+		}
+
+		mergeOverlay(target, item)
+	}
+}
+
+// namedItems reports whether every item of a sequence is a mapping with a name
+func namedItems(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if name := tools.MappingValue(item, "name"); name == nil || name.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+
+	return true
+}
+
+// settings returns the composed config as the map viper reads
+func (c composedConfig) settings() (map[string]any, error) {
+	settings := map[string]any{}
+	if err := c.root.Decode(&settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// Render returns the config as GetConfigEnv sees it: with includes, environment variables and
+// the overlay of env applied. Keys and comments of the config file keep their order.
+func Render(baseDir, configPath, env string) ([]byte, error) {
+	composed, err := composeConfig(nil, ConfigFile(baseDir, configPath), env)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(composed.root); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestRender_Include(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(dir, "project.yaml"), `# main config
+main:
+  name: shop # project name
+include:
+  - apps/*.yaml
+applications:
+  - name: api
+`)
+	writeConfigFile(t, filepath.Join(dir, "apps", "a_worker.yaml"), `main:
+  name: ignored
+  author: Team
+applications:
+  - name: worker
+`)
+	writeConfigFile(t, filepath.Join(dir, "apps", "b_cron.yaml"), `include: ../shared/kafka.yaml
+applications:
+  - name: cron
+`)
+	writeConfigFile(t, filepath.Join(dir, "shared", "kafka.yaml"), `kafka:
+  - name: events
+`)
+
+	out, err := Render(dir, "project.yaml", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, `# main config
+main:
+  name: shop # project name
+  author: Team
+applications:
+  - name: api
+  - name: worker
+  - name: cron
+kafka:
+  - name: events
+`, string(out))
+
+	composed, err := composeConfig(nil, filepath.Join(dir, "project.yaml"), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "apps", "a_worker.yaml"),
+		filepath.Join(dir, "shared", "kafka.yaml"),
+		filepath.Join(dir, "apps", "b_cron.yaml"),
+	}, composed.includes)
+}
+
+func TestRender_IncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(dir, "missing.yaml"), "include: nothing/*.yaml\n")
+	writeConfigFile(t, filepath.Join(dir, "a.yaml"), "include: b.yaml\n")
+	writeConfigFile(t, filepath.Join(dir, "b.yaml"), "include: a.yaml\n")
+
+	_, err := Render(dir, "missing.yaml", "")
+	assert.ErrorContains(t, err, "no such file")
+
+	_, err = Render(dir, "a.yaml", "")
+	assert.ErrorContains(t, err, "include cycle")
+}
+
+func TestRender_Interpolation(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv("PSG_TEST_NAME", "shop")
+	t.Setenv("PSG_TEST_EMPTY", "")
+
+	writeConfigFile(t, filepath.Join(dir, "project.yaml"), `main:
+  name: ${PSG_TEST_NAME}
+  registry: ${PSG_TEST_UNSET:-ghcr.io}/${PSG_TEST_NAME}
+  author: ${PSG_TEST_EMPTY:-Team}
+  port: ${PSG_TEST_PORT:-8080}
+  quoted: "${PSG_TEST_PORT:-8080}"
+  price: $$5
+documentation:
+  headers:
+    - "Authorization: Bearer ${PSG_TEST_NAME}"
+overrides:
+  prod:
+    main:
+      token: ${PSG_TEST_TOKEN}
+`)
+
+	out, err := Render(dir, "project.yaml", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, `main:
+  name: shop
+  registry: ghcr.io/shop
+  author: Team
+  port: 8080
+  quoted: "8080"
+  price: $5
+documentation:
+  headers:
+    - "Authorization: Bearer ${PSG_TEST_NAME}"
+`, string(out))
+
+	// Variables of an overlay are needed only when it is applied
+	_, err = Render(dir, "project.yaml", "prod")
+	assert.ErrorContains(t, err, "PSG_TEST_TOKEN is not set")
+}
+
+func TestRender_Overrides(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(dir, "project.yaml"), `main:
+  name: shop
+  registry: ghcr.io
+post_generate:
+  - git_install
+  - tidy
+applications:
+  - name: api
+    transport:
+      - name: public
+  - name: worker
+overrides:
+  staging:
+    main:
+      registry: registry.staging
+    post_generate:
+      - tidy
+    applications:
+      - name: api
+        transport:
+          - name: public
+            config:
+              optional: true
+      - name: debug
+`)
+
+	out, err := Render(dir, "project.yaml", "staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, `main:
+  name: shop
+  registry: registry.staging
+post_generate:
+  - tidy
+applications:
+  - name: api
+    transport:
+      - name: public
+        config:
+          optional: true
+  - name: worker
+  - name: debug
+`, string(out))
+
+	_, err = Render(dir, "project.yaml", "prod")
+	assert.ErrorContains(t, err, "no overrides.prod section")
+}
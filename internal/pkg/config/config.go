@@ -1,365 +1,136 @@
 package config
 
 import (
-	"fmt"
+	"io/fs"
+	"log/slog"
 	"path/filepath"
-	"strings"
 
 	"github.com/Educentr/go-project-starter/internal/pkg/loggers"
+	"github.com/Educentr/go-project-starter/internal/pkg/migrate"
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
 
 func GetConfig(baseDir, configPath string) (Config, error) { // конструктор, принимает две строки конфигурации и отдает структуру Config и ошибку
-	var config Config // объявлена переменная типа Config
-
-	realConfigPath := configPath
-
-	if !strings.Contains(configPath, "/") { // если строка с конфигурацией "configPath" не содержит "/", то
-		realConfigPath = filepath.Join(baseDir, configPath) // объединяем пути "baseDir" и "configPath" в один "realConfigPath" в правильном формате для текущей операционной системы
-	}
-
-	viper.SetConfigFile(realConfigPath)          // Указываем путь к файлу конфигурации, который будет использоваться для загрузки настроек приложения
-	if err := viper.ReadInConfig(); err != nil { // если получили ошибку при чтении конфигурации, то
-		return config, err // останавливаем программу и отдаем структуру «config типа Config» и саму ошибку
-	}
-
-	viper.SetDefault("docker.image_prefix", "educentr") // устанавливаем значения по умолчанию для "docker.image_prefix"
-
-	// post_generate defaults removed - now uses []string format, users must explicitly specify steps
-
-	viper.SetDefault("tools.protobuf_version", defaultProtobufVersion)          // устанавливаем значения по умолчанию для "tools.protobuf_version"
-	viper.SetDefault("tools.golang_version", defaultGolangVersion)              // устанавливаем значения по умолчанию для "tools.golang_version"
-	viper.SetDefault("tools.ogen_version", defaultOgenVersion)                  // устанавливаем значения по умолчанию для "tools.ogen_version"
-	viper.SetDefault("tools.argen_version", defaultArgenVersion)                // устанавливаем значения по умолчанию для "tools.argen_version"
-	viper.SetDefault("tools.golangci_version", defaultGolangciVersion)          // устанавливаем значения по умолчанию для "tools.golangci_version"
-	viper.SetDefault("tools.go_jsonschema_version", defaultGoJSONSchemaVersion) // устанавливаем значения по умолчанию для "tools.go_jsonschema_version"
-
-	viper.SetDefault("main.author", "Unknown author") // устанавливаем значения по умолчанию для "main.author"
-
-	viper.SetDefault("m.RegistryType", "github") // устанавливаем значения по умолчанию для "github"
-
-	if err := viper.Unmarshal(&config); err != nil { // если при преобразовании данных из Viper в структуру config получили ошибку, то
-		return config, err // останавливаем программу и отдаем структуру «config типа Config» и саму ошибку
-	}
-
-	config.Main.CISet = viper.IsSet("main.ci")
-
-	if ok, msg := config.Main.IsValid(); !ok { // проверяем валидность конфигурации
-		return config, errors.WithMessage(ErrInvalidConfig, "invalid config main section: "+msg)
-	}
-
-	// Валидация ArgenVersion когда use_active_record включен
-	if config.Main.UseActiveRecord && len(config.Tools.ArgenVersion) == 0 {
-		return config, errors.WithMessage(ErrInvalidConfig, "ArgenVersion required when use_active_record is true")
-	}
-
-	config.Main.LoggerObj = loggers.LoggerMapping[config.Main.Logger]
-
-	// создаем мапки
-	config.RestMap = make(map[string]Rest)
-	config.GrpcMap = make(map[string]Grpc)
-	config.DriverMap = make(map[string]Driver)
-	config.WorkerMap = make(map[string]Worker)
-	config.CLIMap = make(map[string]CLI)
-	config.JSONSchemaMap = make(map[string]JSONSchema)
-	config.KafkaMap = make(map[string]Kafka)
-	config.GrafanaDatasourceMap = make(map[string]GrafanaDatasource)
-
-	for i, rest := range config.RestList { // "rest" названия полей
-		if ok, msg := rest.IsValid(baseDir); !ok { // проверяем валидность конфигурации
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config rest section: "+msg)
-		}
-
-		if _, ex := config.RestMap[rest.Name]; ex { // проверка, есть ли уже такое имя
-			return config, errors.WithMessage(ErrInvalidConfig, "duplicate rest name: "+rest.Name)
-		}
-
-		if rest.Version == "" { // если в переменной "rest" типа Rest поле "Version" типа string не задано (пустая строка)
-			config.RestList[i].Version = "v1" // в переменную "config" типа Config в срез RestList по ключу [i] полю "Version" типа string присваиваем значение "v1"
-		}
-
-		config.RestMap[rest.Name] = rest // в переменной "config" типа Config в мапку "RestMap" по ключу [rest.Name] ложим переменную "rest" типа Rest
-	}
-
-	for _, grpc := range config.GrpcList {
-		if ok, msg := grpc.IsValid(baseDir); !ok {
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config grpc section: "+msg)
-		}
-
-		if _, ex := config.GrpcMap[grpc.Name]; ex {
-			return config, errors.WithMessage(ErrInvalidConfig, "duplicate grpc name: "+grpc.Name)
-		}
+	return GetConfigEnv(baseDir, configPath, "")
+}
 
-		config.GrpcMap[grpc.Name] = grpc
-	}
+// GetConfigEnv loads the config with its includes and environment variables; if env is set,
+// the overrides.<env> section of the config is applied on top
+func GetConfigEnv(baseDir, configPath, env string) (Config, error) {
+	realConfigPath := ConfigFile(baseDir, configPath) // если "configPath" не содержит "/", файл ищется в "baseDir"
 
-	for _, driver := range config.DriverList {
-		if ok, msg := driver.IsValid(); !ok {
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config driver section: "+msg)
-		}
+	return getConfig(nil, baseDir, realConfigPath, env)
+}
 
-		if _, ex := config.DriverMap[driver.Name]; ex {
-			return config, errors.WithMessage(ErrInvalidConfig, "duplicate driver name: "+driver.Name)
-		}
+// GetConfigFS is GetConfigEnv for a config read from fsys: configPath is a path of fsys, files it includes
+// and spec files it references are read from fsys too, relative to the directory of configPath
+func GetConfigFS(fsys fs.FS, configPath, env string) (Config, error) {
+	return getConfig(fsys, filepath.Dir(configPath), configPath, env)
+}
 
-		config.DriverMap[driver.Name] = driver
+func getConfig(fsys fs.FS, baseDir, configPath, env string) (Config, error) {
+	composed, err := composeConfig(fsys, configPath, env)
+	if err != nil {
+		return Config{}, err
 	}
 
-	for _, worker := range config.WorkerList {
-		if ok, msg := worker.IsValid(baseDir); !ok {
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config worker section: "+msg)
-		}
+	var deprecations []Deprecations
 
-		if _, ex := config.WorkerMap[worker.Name]; ex {
-			return config, errors.WithMessage(ErrInvalidConfig, "duplicate worker name: "+worker.Name)
+	for _, path := range append([]string{configPath}, composed.includes...) {
+		if warnings := collectDeprecationWarnings(fsys, path); len(warnings) > 0 {
+			deprecations = append(deprecations, Deprecations{File: path, Warnings: warnings})
 		}
-
-		config.WorkerMap[worker.Name] = worker
 	}
 
-	for _, cli := range config.CLIList {
-		if ok, msg := cli.IsValid(); !ok {
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config cli section: "+msg)
-		}
-
-		if _, ex := config.CLIMap[cli.Name]; ex {
-			return config, errors.WithMessage(ErrInvalidConfig, "duplicate cli name: "+cli.Name)
-		}
+	// Устаревшие возможности возвращаются и с ошибкой: часто именно они мешают загрузить конфиг
+	config, err := decodeConfig(composed)
+	config.Deprecations = deprecations
 
-		config.CLIMap[cli.Name] = cli
+	if err != nil {
+		return config, err
 	}
 
-	for _, js := range config.JSONSchemaList {
-		if ok, msg := js.IsValid(baseDir); !ok {
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config jsonschema section: "+msg)
-		}
-
-		if _, ex := config.JSONSchemaMap[js.Name]; ex {
-			return config, errors.WithMessage(ErrInvalidConfig, "duplicate jsonschema name: "+js.Name)
-		}
-
-		config.JSONSchemaMap[js.Name] = js
+	// Конфигурация валидна, только если нет ни одной проблемы; в ошибке первая из них
+	if problems := validateConfig(fsys, baseDir, &config); len(problems) > 0 {
+		return config, errors.WithMessage(ErrInvalidConfig, problems[0].Message)
 	}
 
-	for _, kafka := range config.KafkaList {
-		if ok, msg := kafka.IsValid(config.JSONSchemaMap); !ok {
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config kafka section: "+msg)
-		}
-
-		if _, ex := config.KafkaMap[kafka.Name]; ex {
-			return config, errors.WithMessage(ErrInvalidConfig, "duplicate kafka name: "+kafka.Name)
-		}
-
-		config.KafkaMap[kafka.Name] = kafka
-	}
+	config.BasePath = baseDir
+	config.ConfigFilePath = configPath
+	config.IncludePaths = composed.includes
+	config.Env = env
+	config.FS = fsys
 
-	// Validate Grafana configuration
-	if ok, msg := config.Grafana.IsValid(); !ok {
-		return config, errors.WithMessage(ErrInvalidConfig, "invalid config grafana section: "+msg)
-	}
+	return config, nil
+}
 
-	for _, ds := range config.Grafana.Datasources {
-		config.GrafanaDatasourceMap[ds.Name] = ds
-	}
+// decodeConfig reads the composed config into Config with the defaults applied
+func decodeConfig(composed composedConfig) (Config, error) {
+	var config Config // объявлена переменная типа Config
 
-	// Validate documentation configuration
-	if ok, msg := config.Documentation.IsValid(); !ok {
-		return config, errors.WithMessage(ErrInvalidConfig, "invalid config documentation section: "+msg)
+	settings, err := composed.settings()
+	if err != nil {
+		return config, err
 	}
 
-	for i := range config.Applications {
-		// Normalize transport list (supports both old string[] and new object[] format)
-		if err := config.Applications[i].NormalizeTransports(); err != nil {
-			appName := config.Applications[i].Name
-
-			return config, errors.WithMessage(ErrInvalidConfig,
-				fmt.Sprintf("application[%d] '%s': %s", i, appName, err.Error()))
-		}
-
-		// Normalize kafka list (supports both old string[] and new object[] format)
-		if err := config.Applications[i].NormalizeKafka(); err != nil {
-			appName := config.Applications[i].Name
-
-			return config, errors.WithMessage(ErrInvalidConfig,
-				fmt.Sprintf("application[%d] '%s': %s", i, appName, err.Error()))
-		}
-
-		app := config.Applications[i]
-
-		if ok, msg := app.IsValid(); !ok {
-			return config, errors.WithMessage(ErrInvalidConfig, "invalid config application section: "+msg)
-		}
-
-		// Валидация use_active_record: может быть только false (для отключения AR)
-		if app.UseActiveRecord != nil && *app.UseActiveRecord == true {
-			return config, errors.WithMessage(ErrInvalidConfig,
-				"application '"+app.Name+"': use_active_record can only be set to false (to disable AR for specific app)")
-		}
-
-		// Валидация use_envs: может быть только true или nil, false запрещен
-		if app.UseEnvs != nil && !*app.UseEnvs {
-			return config, errors.WithMessage(ErrInvalidConfig,
-				fmt.Sprintf("application[%d] '%s': use_envs can only be true or omitted, false is not allowed", i, app.Name))
-		}
-
-		for _, transport := range app.TransportList {
-			_, exRest := config.RestMap[transport.Name]
-			_, exGrpc := config.GrpcMap[transport.Name]
-
-			if !exRest && !exGrpc {
-				return config, errors.WithMessage(ErrInvalidConfig,
-					"unknown transport: "+transport.Name+" in application: "+app.Name)
-			}
-
-			// Validate instantiation is only allowed for ogen_client
-			if transport.Config.Instantiation != "" {
-				rest, isRest := config.RestMap[transport.Name]
-				if !isRest || rest.GeneratorType != GeneratorTypeOgenClient {
-					return config, errors.WithMessage(ErrInvalidConfig,
-						fmt.Sprintf("transport '%s' in application '%s': %s",
-							transport.Name, app.Name, errInstantiationOnlyOgenClient))
-				}
-			}
-		}
-
-		for _, driver := range app.DriverList {
-			if _, ex := config.DriverMap[driver.Name]; !ex {
-				return config, errors.WithMessage(ErrInvalidConfig, "unknown driver: "+driver.Name+" in application: "+app.Name)
-			}
-		}
-
-		for _, worker := range app.WorkerList {
-			if _, ex := config.WorkerMap[worker]; !ex {
-				return config, errors.WithMessage(ErrInvalidConfig, "unknown worker: "+worker+" in application: "+app.Name)
-			}
-		}
-
-		// Validate CLI reference
-		if app.CLI != "" {
-			if _, ex := config.CLIMap[app.CLI]; !ex {
-				return config, errors.WithMessage(ErrInvalidConfig, "unknown cli: "+app.CLI+" in application: "+app.Name)
-			}
-		}
-
-		// Validate Kafka references
-		for _, appKafka := range app.KafkaList {
-			if _, ex := config.KafkaMap[appKafka.Name]; !ex {
-				return config, errors.WithMessage(ErrInvalidConfig, "unknown kafka: "+appKafka.Name+" in application: "+app.Name)
-			}
-		}
-
-		// Validate Grafana datasource references
-		for _, dsName := range app.Grafana.Datasources {
-			if _, ex := config.GrafanaDatasourceMap[dsName]; !ex {
-				return config, errors.WithMessage(ErrInvalidConfig, "unknown grafana datasource: "+dsName+" in application: "+app.Name)
-			}
-		}
+	// Свой экземпляр viper: конфигурации нескольких проектов не смешиваются
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return config, err
 	}
 
-	// ToDo ws, ...
+	v.SetDefault("docker.image_prefix", "educentr") // устанавливаем значения по умолчанию для "docker.image_prefix"
 
-	// Validate dev_stand requires git_install in post_generate
-	if config.Main.DevStand {
-		hasGitInstall := false
+	// post_generate defaults removed - now uses []string format, users must explicitly specify steps
 
-		for _, pg := range config.PostGenerate {
-			if pg == "git_install" {
-				hasGitInstall = true
+	v.SetDefault("tools.protobuf_version", defaultProtobufVersion)          // устанавливаем значения по умолчанию для "tools.protobuf_version"
+	v.SetDefault("tools.golang_version", defaultGolangVersion)              // устанавливаем значения по умолчанию для "tools.golang_version"
+	v.SetDefault("tools.ogen_version", defaultOgenVersion)                  // устанавливаем значения по умолчанию для "tools.ogen_version"
+	v.SetDefault("tools.argen_version", defaultArgenVersion)                // устанавливаем значения по умолчанию для "tools.argen_version"
+	v.SetDefault("tools.golangci_version", defaultGolangciVersion)          // устанавливаем значения по умолчанию для "tools.golangci_version"
+	v.SetDefault("tools.go_jsonschema_version", defaultGoJSONSchemaVersion) // устанавливаем значения по умолчанию для "tools.go_jsonschema_version"
 
-				break
-			}
-		}
+	v.SetDefault("main.author", "Unknown author") // устанавливаем значения по умолчанию для "main.author"
 
-		if !hasGitInstall {
-			return config, errors.WithMessage(ErrInvalidConfig, "dev_stand requires 'git_install' in post_generate section")
-		}
-	}
+	v.SetDefault("m.RegistryType", "github") // устанавливаем значения по умолчанию для "github"
 
-	// Validate that all defined entities are used in at least one application
-	if err := validateEntityUsage(&config); err != nil {
-		return config, err
+	if err := v.Unmarshal(&config); err != nil { // если при преобразовании данных из Viper в структуру config получили ошибку, то
+		return config, err // останавливаем программу и отдаем структуру «config типа Config» и саму ошибку
 	}
 
-	config.BasePath = baseDir
-	config.ConfigFilePath = realConfigPath
+	config.Main.CISet = v.IsSet("main.ci")
+	config.Main.LoggerObj = loggers.LoggerMapping[config.Main.Logger]
 
 	return config, nil
 }
 
+// Deprecations are the uses of deprecated features in a file of the config
+type Deprecations struct {
+	File     string
+	Warnings []migrate.DeprecationWarning
+}
 
-// validateEntityUsage checks that all defined entities (rest, grpc, kafka, drivers, workers)
-// are referenced in at least one application
-func validateEntityUsage(config *Config) error {
-	usedTransports := make(map[string]bool)
-	usedKafka := make(map[string]bool)
-	usedDrivers := make(map[string]bool)
-	usedWorkers := make(map[string]bool)
-	usedCLI := make(map[string]bool)
-
-	for _, app := range config.Applications {
-		for _, t := range app.TransportList {
-			usedTransports[t.Name] = true
-		}
-
-		for _, k := range app.KafkaList {
-			usedKafka[k.Name] = true
-		}
-
-		for _, d := range app.DriverList {
-			usedDrivers[d.Name] = true
-		}
-
-		for _, w := range app.WorkerList {
-			usedWorkers[w] = true
-		}
-
-		if app.CLI != "" {
-			usedCLI[app.CLI] = true
-		}
-	}
-
-	// Check rest
-	for name := range config.RestMap {
-		if !usedTransports[name] {
-			return errors.WithMessage(ErrInvalidConfig, fmt.Sprintf("rest '%s' is not used in any application", name))
-		}
-	}
-
-	// Check grpc
-	for name := range config.GrpcMap {
-		if !usedTransports[name] {
-			return errors.WithMessage(ErrInvalidConfig, fmt.Sprintf("grpc '%s' is not used in any application", name))
-		}
-	}
-
-	// Check kafka
-	for name := range config.KafkaMap {
-		if !usedKafka[name] {
-			return errors.WithMessage(ErrInvalidConfig, fmt.Sprintf("kafka '%s' is not used in any application", name))
-		}
-	}
-
-	// Check drivers
-	for name := range config.DriverMap {
-		if !usedDrivers[name] {
-			return errors.WithMessage(ErrInvalidConfig, fmt.Sprintf("driver '%s' is not used in any application", name))
-		}
+// LogDeprecations logs a warning for every use of a deprecated feature found when the config was loaded
+func (c Config) LogDeprecations(log *slog.Logger) {
+	for _, d := range c.Deprecations {
+		migrate.LogWarnings(log, d.File, d.Warnings)
 	}
+}
 
-	// Check workers
-	for name := range config.WorkerMap {
-		if !usedWorkers[name] {
-			return errors.WithMessage(ErrInvalidConfig, fmt.Sprintf("worker '%s' is not used in any application", name))
-		}
+// collectDeprecationWarnings returns the uses of deprecated features in a file of the config.
+// The config has been read already, so errors are not expected here and do not stop loading.
+func collectDeprecationWarnings(fsys fs.FS, configPath string) []migrate.DeprecationWarning {
+	data, err := tools.ReadFile(fsys, configPath)
+	if err != nil {
+		return nil
 	}
 
-	// Check CLI
-	for name := range config.CLIMap {
-		if !usedCLI[name] {
-			return errors.WithMessage(ErrInvalidConfig, fmt.Sprintf("cli '%s' is not used in any application", name))
-		}
+	warnings, err := migrate.CheckDeprecationsData(data)
+	if err != nil {
+		return nil
 	}
 
-	return nil
+	return warnings
 }
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"github.com/pkg/errors"
+)
+
+// ProtoMethod represents a single rpc declaration inside a service
+type ProtoMethod struct {
+	Name            string
+	Request         string
+	Response        string
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+// ProtoService represents a single service declaration in a .proto file
+type ProtoService struct {
+	Name    string
+	Methods []ProtoMethod
+}
+
+// ProtoSpec represents the services declared in a .proto file
+type ProtoSpec struct {
+	Package  string
+	Services []ProtoService
+}
+
+var (
+	protoLineCommentRe  = regexp.MustCompile(`//[^\n]*`)
+	protoBlockCommentRe = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	protoPackageRe      = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	protoServiceRe      = regexp.MustCompile(`service\s+(\w+)\s*\{`)
+	protoRPCRe          = regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)`)
+)
+
+// ParseProtoSpec reads a .proto file and extracts its service declarations.
+// Only the subset needed for server stub generation is parsed: the package,
+// service names and rpc signatures. The file is read from fsys, the OS file system if fsys is nil.
+func ParseProtoSpec(fsys fs.FS, path string) (*ProtoSpec, error) {
+	data, err := tools.ReadFile(fsys, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read proto file: %s", path)
+	}
+
+	spec, err := parseProto(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proto file: %s", path)
+	}
+
+	return spec, nil
+}
+
+func parseProto(src string) (*ProtoSpec, error) {
+	src = protoBlockCommentRe.ReplaceAllString(src, "")
+	src = protoLineCommentRe.ReplaceAllString(src, "")
+
+	spec := &ProtoSpec{}
+
+	if m := protoPackageRe.FindStringSubmatch(src); m != nil {
+		spec.Package = m[1]
+	}
+
+	seen := make(map[string]struct{})
+
+	for _, loc := range protoServiceRe.FindAllStringSubmatchIndex(src, -1) {
+		name := src[loc[2]:loc[3]]
+
+		if _, exists := seen[name]; exists {
+			return nil, fmt.Errorf("duplicate service name: %s", name)
+		}
+		seen[name] = struct{}{}
+
+		body, err := protoBlockBody(src, loc[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "service '%s'", name)
+		}
+
+		service := ProtoService{Name: name}
+
+		for _, m := range protoRPCRe.FindAllStringSubmatch(body, -1) {
+			service.Methods = append(service.Methods, ProtoMethod{
+				Name:            m[1],
+				ClientStreaming: m[2] != "",
+				Request:         m[3],
+				ServerStreaming: m[4] != "",
+				Response:        m[5],
+			})
+		}
+
+		spec.Services = append(spec.Services, service)
+	}
+
+	if len(spec.Services) == 0 {
+		return nil, errors.New("no services defined")
+	}
+
+	return spec, nil
+}
+
+// protoBlockBody returns the text between the opening brace that ends right
+// before start and its matching closing brace.
+func protoBlockBody(src string, start int) (string, error) {
+	depth := 1
+
+	for i := start; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return src[start:i], nil
+			}
+		}
+	}
+
+	return "", errors.New("unterminated block")
+}
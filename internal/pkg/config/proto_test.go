@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProtoSpec_Valid(t *testing.T) {
+	content := `
+syntax = "proto3";
+
+package users.v1;
+
+import "google/protobuf/empty.proto";
+
+/* Users API.
+   service Commented { rpc Skip(A) returns (B); } */
+service UserService {
+  // rpc Hidden(Req) returns (Resp);
+  rpc GetUser(GetUserRequest) returns (GetUserResponse);
+  rpc Ping(google.protobuf.Empty) returns (google.protobuf.Empty) {
+    option deprecated = true;
+  }
+  rpc Watch(WatchRequest) returns (stream WatchEvent);
+  rpc Upload(stream Chunk) returns (UploadResponse);
+}
+
+service AdminService {
+  rpc Ban(BanRequest) returns (BanResponse);
+}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "users.proto")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	spec, err := ParseProtoSpec(nil, path)
+	require.NoError(t, err)
+	assert.Equal(t, "users.v1", spec.Package)
+	require.Len(t, spec.Services, 2)
+
+	users := spec.Services[0]
+	assert.Equal(t, "UserService", users.Name)
+	require.Len(t, users.Methods, 4)
+	assert.Equal(t, ProtoMethod{Name: "GetUser", Request: "GetUserRequest", Response: "GetUserResponse"}, users.Methods[0])
+	assert.Equal(t, "google.protobuf.Empty", users.Methods[1].Request)
+	assert.True(t, users.Methods[2].ServerStreaming)
+	assert.False(t, users.Methods[2].ClientStreaming)
+	assert.True(t, users.Methods[3].ClientStreaming)
+	assert.False(t, users.Methods[3].ServerStreaming)
+
+	assert.Equal(t, "AdminService", spec.Services[1].Name)
+	assert.Len(t, spec.Services[1].Methods, 1)
+}
+
+func TestParseProtoSpec_NoServices(t *testing.T) {
+	content := `
+syntax = "proto3";
+message Foo { string id = 1; }
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo.proto")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	_, err := ParseProtoSpec(nil, path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no services defined")
+}
+
+func TestParseProtoSpec_DuplicateService(t *testing.T) {
+	content := `
+service A { rpc X(Req) returns (Resp); }
+service A { rpc Y(Req) returns (Resp); }
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dup.proto")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	_, err := ParseProtoSpec(nil, path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate service name: A")
+}
+
+func TestParseProtoSpec_Unterminated(t *testing.T) {
+	content := `service A { rpc X(Req) returns (Resp);`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.proto")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	_, err := ParseProtoSpec(nil, path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unterminated block")
+}
+
+func TestParseProtoSpec_FileNotFound(t *testing.T) {
+	_, err := ParseProtoSpec(nil, "/nonexistent/file.proto")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read proto file")
+}
@@ -2,8 +2,9 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
@@ -37,9 +38,9 @@ var validQueueFieldTypes = map[string]bool{
 	"[]int64": true,
 }
 
-// ParseQueueSpec reads and parses a queue contract YAML file
-func ParseQueueSpec(path string) (*QueueSpec, error) {
-	data, err := os.ReadFile(path)
+// ParseQueueSpec reads and parses a queue contract YAML file, from fsys or the OS file system if fsys is nil
+func ParseQueueSpec(fsys fs.FS, path string) (*QueueSpec, error) {
+	data, err := tools.ReadFile(fsys, path)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to read queue spec file: %s", path)
 	}
@@ -29,7 +29,7 @@ queues:
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	spec, err := ParseQueueSpec(path)
+	spec, err := ParseQueueSpec(nil, path)
 	require.NoError(t, err)
 	require.Len(t, spec.Queues, 2)
 	assert.Equal(t, 1, spec.Queues[0].ID)
@@ -45,7 +45,7 @@ func TestParseQueueSpec_NoQueues(t *testing.T) {
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	_, err := ParseQueueSpec(path)
+	_, err := ParseQueueSpec(nil, path)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no queues defined")
 }
@@ -68,7 +68,7 @@ queues:
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	_, err := ParseQueueSpec(path)
+	_, err := ParseQueueSpec(nil, path)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "duplicate queue id: 1")
 }
@@ -91,7 +91,7 @@ queues:
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	_, err := ParseQueueSpec(path)
+	_, err := ParseQueueSpec(nil, path)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "duplicate queue name: same")
 }
@@ -107,7 +107,7 @@ queues:
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	_, err := ParseQueueSpec(path)
+	_, err := ParseQueueSpec(nil, path)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "has no fields")
 }
@@ -125,7 +125,7 @@ queues:
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	_, err := ParseQueueSpec(path)
+	_, err := ParseQueueSpec(nil, path)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid type")
 }
@@ -145,7 +145,7 @@ queues:
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	_, err := ParseQueueSpec(path)
+	_, err := ParseQueueSpec(nil, path)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "duplicate field name: x")
 }
@@ -175,7 +175,7 @@ queues:
 	path := filepath.Join(dir, "queues.yaml")
 	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
 
-	spec, err := ParseQueueSpec(path)
+	spec, err := ParseQueueSpec(nil, path)
 	require.NoError(t, err)
 	assert.Len(t, spec.Queues[0].Fields, 7)
 }
@@ -0,0 +1,358 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// interpolationDef is the schema of a value set by an environment variable, e.g. `port: ${PORT:-8080}`:
+// the file holds a string whatever the type of the value is
+const interpolationDef = "interpolation"
+
+// schema is a JSON Schema (draft 2020-12) node
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // false or *schema
+	Items                *schema            `json:"items,omitempty"`
+	AnyOf                []*schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*schema `json:"$defs,omitempty"`
+}
+
+// configField is a key of project.yaml: a field of a config struct with a mapstructure tag
+type configField struct {
+	key string
+	typ reflect.Type
+	// names reports whether items of the list may be plain names, the old format of
+	// fields like applications[].transport read by the Normalize methods
+	names bool
+}
+
+// configFields returns the keys of a config struct. A raw field (TransportListRaw) is described
+// by the type of the list it is normalized to (TransportList).
+func configFields(t reflect.Type) []configField {
+	fields := make([]configField, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		key, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+
+		cf := configField{key: key, typ: field.Type}
+
+		if field.Type.Kind() == reflect.Interface {
+			if normalized, ok := t.FieldByName(strings.TrimSuffix(field.Name, "Raw")); ok && normalized.Name != field.Name {
+				cf.typ, cf.names = normalized.Type, true
+			}
+		}
+
+		fields = append(fields, cf)
+	}
+
+	return fields
+}
+
+// Schema returns the JSON Schema of project.yaml, generated from Config
+func Schema() ([]byte, error) {
+	root := schemaOf(reflect.TypeOf(Config{}))
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "go-project-starter project.yaml"
+
+	root.Properties[keyInclude] = &schema{
+		Description: "Config files merged into this one, globs allowed",
+		AnyOf:       []*schema{{Type: "string"}, {Type: "array", Items: &schema{Type: "string"}}},
+	}
+	root.Properties[keyOverrides] = &schema{
+		Description:          "Per environment overlays applied with --env",
+		Type:                 "object",
+		AdditionalProperties: &schema{Ref: "#"},
+	}
+	root.Defs = map[string]*schema{
+		interpolationDef: {Type: "string", Pattern: `\$\{[A-Za-z_][A-Za-z0-9_]*(:-[^}]*)?\}`},
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+func schemaOf(t reflect.Type) *schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Struct:
+		s := &schema{Type: "object", Properties: map[string]*schema{}, AdditionalProperties: false}
+
+		for _, field := range configFields(t) {
+			s.Properties[field.key] = fieldSchema(field)
+		}
+
+		return s
+	case reflect.Slice:
+		list := &schema{Type: "array", Items: schemaOf(t.Elem())}
+		if t.Elem().Kind() != reflect.String {
+			return list
+		}
+
+		// A string is split by commas into the list
+		return &schema{AnyOf: []*schema{list, {Type: "string"}}}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return interpolated(&schema{Type: "boolean"})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return interpolated(&schema{Type: "integer"})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0
+
+		return interpolated(&schema{Type: "integer", Minimum: &minimum})
+	case reflect.Float32, reflect.Float64:
+		return interpolated(&schema{Type: "number"})
+	default:
+		return &schema{}
+	}
+}
+
+func fieldSchema(field configField) *schema {
+	s := schemaOf(field.typ)
+	if field.names {
+		s.Items = &schema{AnyOf: []*schema{{Type: "string", Deprecated: true}, s.Items}}
+	}
+
+	return s
+}
+
+func interpolated(s *schema) *schema {
+	return &schema{AnyOf: []*schema{s, {Ref: "#/$defs/" + interpolationDef}}}
+}
+
+// structureCheck finds the values of the config that Config has no field for and the values
+// of a wrong type. Other problems are found by validateConfig on the decoded config.
+type structureCheck struct {
+	files    map[*yaml.Node]string
+	problems []Problem
+	// decodable is reset by a list item of a wrong type: unlike a value of a key, it can't be
+	// dropped without shifting the positions of the other items
+	decodable bool
+}
+
+// checkStructure reports the structure problems of the config. Values of a wrong type are removed
+// from it, so it can be decoded to find the other problems, unless decodable is false.
+func (c composedConfig) checkStructure() ([]Problem, bool) {
+	check := structureCheck{files: c.files, decodable: true}
+	check.node(c.root, reflect.TypeOf(Config{}), "", "")
+
+	return check.problems, check.decodable
+}
+
+func (s *structureCheck) add(node *yaml.Node, path, entity, message, fix string) {
+	s.problems = append(s.problems, Problem{
+		File:    s.files[node],
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Entity:  entity,
+		Message: message,
+		Fix:     fix,
+	})
+}
+
+// node checks a value of type t, it returns false if the value itself is of a wrong type
+func (s *structureCheck) node(node *yaml.Node, t reflect.Type, path, entity string) bool {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.node(node, t.Elem(), path, entity)
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			s.add(node, path, entity, "expected a mapping, got "+describe(node), "")
+
+			return false
+		}
+
+		if name := tools.MappingValue(node, "name"); name != nil && name.Kind == yaml.ScalarNode {
+			entity = name.Value
+		}
+
+		s.fields(node, configFields(t), path, entity)
+	case reflect.Slice:
+		if node.Kind == yaml.ScalarNode && t.Elem().Kind() == reflect.String {
+			return true
+		}
+
+		if node.Kind != yaml.SequenceNode {
+			s.add(node, path, entity, "expected a list, got "+describe(node), "")
+
+			return false
+		}
+
+		for i, item := range node.Content {
+			if !s.node(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), entity) {
+				s.decodable = false
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			s.add(node, path, entity, "expected a mapping, got "+describe(node), "")
+
+			return false
+		}
+
+		content := node.Content[:0]
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			if s.node(value, t.Elem(), joinPath(path, key.Value), entity) {
+				content = append(content, key, value)
+			}
+		}
+
+		node.Content = content
+	case reflect.Interface:
+	default:
+		if want, ok := checkScalar(node, t.Kind()); !ok {
+			s.add(node, path, entity, "expected "+want+", got "+describe(node), "")
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// fields checks the keys of a mapping decoded into a struct with the fields, keys of wrong values are removed
+func (s *structureCheck) fields(node *yaml.Node, fields []configField, path, entity string) {
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.key)
+	}
+
+	content := node.Content[:0]
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keyPath := joinPath(path, key.Value)
+
+		field, ok := findField(fields, key.Value)
+
+		switch {
+		case !ok:
+			fix := "remove it"
+			if match := closest(key.Value, keys); match != "" {
+				fix = fmt.Sprintf("did you mean '%s'?", match)
+			}
+
+			s.add(key, keyPath, entity, fmt.Sprintf("unknown key '%s'", key.Value), fix)
+		case field.names && value.Kind == yaml.SequenceNode:
+			// Plain names are the old format of the list, mappings are checked as items of the new one
+			for j, item := range value.Content {
+				if item.Kind != yaml.ScalarNode && !s.node(item, field.typ.Elem(), fmt.Sprintf("%s[%d]", keyPath, j), entity) {
+					s.decodable = false
+				}
+			}
+
+			content = append(content, key, value)
+		case s.node(value, field.typ, keyPath, entity):
+			content = append(content, key, value)
+		}
+	}
+
+	node.Content = content
+}
+
+// checkScalar reports whether a node can be decoded into a value of kind, the way viper decodes it:
+// strings are converted to booleans and numbers
+func checkScalar(node *yaml.Node, kind reflect.Kind) (string, bool) {
+	var want string
+
+	switch kind {
+	case reflect.Bool:
+		want = "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		want = "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		want = "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		want = "a number"
+	default:
+		want = "a string"
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return want, false
+	}
+
+	var err error
+
+	switch kind {
+	case reflect.Bool:
+		_, err = strconv.ParseBool(node.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(node.Value, 0, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(node.Value, 0, 64)
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(node.Value, 64)
+	}
+
+	return want, err == nil
+}
+
+// findField returns the field of a key, keys are matched case-insensitively as viper does
+func findField(fields []configField, key string) (configField, bool) {
+	for _, field := range fields {
+		if strings.EqualFold(field.key, key) {
+			return field, true
+		}
+	}
+
+	return configField{}, false
+}
+
+func describe(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return strconv.Quote(node.Value)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
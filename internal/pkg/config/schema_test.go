@@ -0,0 +1,45 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	require.NoError(t, err)
+
+	var root struct {
+		Properties map[string]struct {
+			Type       string                     `json:"type"`
+			Items      map[string]json.RawMessage `json:"items"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"properties"`
+		AdditionalProperties bool `json:"additionalProperties"`
+	}
+
+	require.NoError(t, json.Unmarshal(data, &root))
+
+	assert.False(t, root.AdditionalProperties)
+	assert.Contains(t, root.Properties, keyInclude)
+	assert.Contains(t, root.Properties, keyOverrides)
+	assert.Contains(t, root.Properties["main"].Properties, "registry_type")
+	assert.NotContains(t, root.Properties["main"].Properties, "CISet", "fields not read from the config are not in the schema")
+	assert.Equal(t, "array", root.Properties["applications"].Type)
+}
+
+// The published schema is regenerated with `make schema`
+func TestSchema_UpToDate(t *testing.T) {
+	data, err := Schema()
+	require.NoError(t, err)
+
+	published, err := os.ReadFile(filepath.Join("..", "..", "..", "docs", "reference", "project.schema.json"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(data), string(published), "docs/reference/project.schema.json is outdated, run make schema")
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ConfigFile returns the path of the config file GetConfig reads: a bare file name is looked up in baseDir
+func ConfigFile(baseDir, configPath string) string {
+	if !strings.Contains(configPath, "/") {
+		return filepath.Join(baseDir, configPath)
+	}
+
+	return configPath
+}
+
+// SourcePaths returns the config file, the files it includes and every spec file the config references
+// (rest, grpc and ws specs, worker and CLI specs, JSON schemas) without duplicates
+func (c Config) SourcePaths() []string {
+	paths := []string{}
+	seen := make(map[string]struct{})
+
+	add := func(path string, relative bool) {
+		if path == "" {
+			return
+		}
+
+		if relative {
+			path = filepath.Join(c.BasePath, path)
+		}
+
+		if _, ex := seen[path]; ex {
+			return
+		}
+
+		seen[path] = struct{}{}
+		paths = append(paths, path)
+	}
+
+	add(c.ConfigFilePath, false)
+
+	for _, path := range c.IncludePaths {
+		add(path, false)
+	}
+
+	for _, rest := range c.RestList {
+		for _, path := range rest.Path {
+			add(path, true)
+		}
+	}
+
+	for _, grpc := range c.GrpcList {
+		add(grpc.Path, true)
+	}
+
+	for _, ws := range c.WsList {
+		add(ws.Path, true)
+	}
+
+	for _, worker := range c.WorkerList {
+		for _, path := range worker.Path {
+			add(path, true)
+		}
+	}
+
+	for _, cli := range c.CLIList {
+		for _, path := range cli.Path {
+			add(path, true)
+		}
+	}
+
+	for _, js := range c.JSONSchemaList {
+		for _, path := range js.Path {
+			add(path, true)
+		}
+
+		for _, schema := range js.Schemas {
+			add(schema.Path, true)
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFile(t *testing.T) {
+	assert.Equal(t, filepath.Join(".project-config", "project.yaml"), ConfigFile(".project-config", "project.yaml"))
+	assert.Equal(t, "configs/project.yaml", ConfigFile(".project-config", "configs/project.yaml"))
+}
+
+func TestConfig_SourcePaths(t *testing.T) {
+	c := Config{
+		BasePath:       "cfg",
+		ConfigFilePath: "cfg/project.yaml",
+		RestList:       RestList{{Name: "api", Path: []string{"api/api.yaml"}}, {Name: "sys"}},
+		GrpcList:       GrpcList{{Name: "users", Path: "proto/users.proto"}},
+		WsList:         WsList{{Name: "chat", Path: "ws/chat.yaml"}},
+		WorkerList:     WorkerList{{Name: "mailer", Path: []string{"queues.yaml"}}},
+		CLIList:        CLIList{{Name: "admin", Path: []string{"commands.yaml"}}},
+		JSONSchemaList: JSONSchemaList{
+			{Name: "legacy", Path: []string{"schemas/a.json"}},
+			{Name: "events", Schemas: []JSONSchemaItem{{ID: "a", Path: "schemas/a.json"}, {ID: "b", Path: "schemas/b.json"}}},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"cfg/project.yaml",
+		filepath.Join("cfg", "api", "api.yaml"),
+		filepath.Join("cfg", "proto", "users.proto"),
+		filepath.Join("cfg", "ws", "chat.yaml"),
+		filepath.Join("cfg", "queues.yaml"),
+		filepath.Join("cfg", "commands.yaml"),
+		filepath.Join("cfg", "schemas", "a.json"),
+		filepath.Join("cfg", "schemas", "b.json"),
+	}, c.SourcePaths())
+}
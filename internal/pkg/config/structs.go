@@ -1,13 +1,17 @@
 package config
 
 import (
+	"io/fs"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Educentr/go-project-starter/internal/pkg/ds"
 	"github.com/Educentr/go-project-starter/internal/pkg/loggers"
 	"github.com/Educentr/go-project-starter/internal/pkg/tools"
 	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
 )
 
 // ArtifactType represents a build artifact type
@@ -25,6 +29,7 @@ type DocumentationConfig struct {
 	Type     DocumentationType `mapstructure:"type"`      // s3, github_pages, minio
 	SiteName string            `mapstructure:"site_name"` // optional, default: Main.Name
 	Headers  []string          `mapstructure:"headers"`   // custom HTTP headers for mc (minio type only)
+	Topology bool              `mapstructure:"topology"`  // add the diagram of the applications to the site
 }
 
 // PackageUploadConfig contains package upload configuration.
@@ -107,6 +112,9 @@ type (
 		DevStand bool `mapstructure:"dev_stand"`
 		// GenerateLlmsMd enables LLMS.md generation for AI coding agents.
 		GenerateLlmsMd bool `mapstructure:"generate_llms_md"`
+		// TemplatesDir is a directory of templates layered over the embedded ones, relative to the config directory.
+		// Its files mirror the embedded templates tree: same path shadows, new path adds a file.
+		TemplatesDir string `mapstructure:"templates_dir"`
 		// CI specifies which CI providers to generate: "github", "gitlab".
 		// Not set = both (backward compatibility). Empty array = none.
 		CI    []string `mapstructure:"ci"`
@@ -136,8 +144,69 @@ type (
 		Datasources []string `mapstructure:"datasources"` // references by name
 	}
 
+	// Scheduler contains periodic job configuration.
+	// When enabled, a worker named "scheduler" is generated with one method per job;
+	// applications run it by listing it in their worker section.
+	//
+	// YAML example:
+	//
+	//	scheduler:
+	//	  enabled: true
+	//	  jobs:
+	//	    - name: cleanup_sessions
+	//	      cron: "*/5 * * * *"
+	//	      timeout: 1m
+	//	      singleton: true
+	//	    - name: refresh_rates
+	//	      interval: 30s
+	//
+	// See docs/configuration/workers.md for full documentation.
 	Scheduler struct {
+		// Enabled turns on scheduler worker generation.
 		Enabled bool `mapstructure:"enabled"`
+		// Jobs contains periodic job definitions. Required when enabled.
+		Jobs []SchedulerJob `mapstructure:"jobs"`
+	}
+
+	// Plugin is an external generator: an executable that gets the resolved project model as JSON
+	// on stdin and prints the files it generates as JSON on stdout.
+	//
+	// YAML example:
+	//
+	//	plugins:
+	//	  - name: rpc
+	//	    command: ./plugins/rpc-gen  # relative to the config directory or looked up in PATH
+	//	    args: [--strict]
+	//	    timeout: 30s
+	//	    config:                     # passed to the plugin as is
+	//	      apps: [api]
+	//
+	// See docs/configuration/plugins.md for full documentation.
+	Plugin struct {
+		// Name identifies the plugin in logs and generated file origins. Required.
+		Name string `mapstructure:"name"`
+		// Command is the plugin executable. Required.
+		Command string `mapstructure:"command"`
+		// Args are passed to the command.
+		Args []string `mapstructure:"args"`
+		// Timeout limits a plugin run (Go duration). Default: 1m.
+		Timeout string `mapstructure:"timeout"`
+		// Config is plugin specific configuration passed in the request.
+		Config map[string]interface{} `mapstructure:"config"`
+	}
+
+	// SchedulerJob describes a single periodic job. Exactly one of Cron or Interval must be set.
+	SchedulerJob struct {
+		// Name is the unique job name (snake_case). Required.
+		Name string `mapstructure:"name"`
+		// Cron is a standard 5-field cron expression or descriptor (@hourly, @every 1h).
+		Cron string `mapstructure:"cron"`
+		// Interval is a fixed delay between runs (Go duration, e.g. 30s).
+		Interval string `mapstructure:"interval"`
+		// Timeout limits a single run (Go duration). Optional, overridable in OnlineConf.
+		Timeout string `mapstructure:"timeout"`
+		// Singleton skips a tick while the previous run of the job is still in progress.
+		Singleton bool `mapstructure:"singleton"`
 	}
 
 	// Git contains Git repository configuration.
@@ -397,6 +466,10 @@ type (
 	//	    path: ./api/users.proto
 	//	    port: 9000
 	//	    generator_type: buf_client
+	//	  - name: billing
+	//	    path: ./api/billing.proto
+	//	    port: 9001
+	//	    generator_type: buf_server
 	//
 	// See docs/configuration/transports.md for full documentation.
 	Grpc struct {
@@ -406,9 +479,9 @@ type (
 		Path string `mapstructure:"path"`
 		// Short is a short name for package naming. Optional.
 		Short string `mapstructure:"short"`
-		// Port is the gRPC port. Required.
+		// Port is the gRPC port. Required for buf_server.
 		Port uint `mapstructure:"port"`
-		// GeneratorType is the generator type: buf_client. Required.
+		// GeneratorType is the generator type: buf_client or buf_server. Required.
 		GeneratorType string `mapstructure:"generator_type"`
 		// BufLocalPlugins enables local buf plugins instead of remote. Optional.
 		BufLocalPlugins bool `mapstructure:"buf_local_plugins"`
@@ -416,15 +489,43 @@ type (
 		Instantiation string `mapstructure:"instantiation"`
 	}
 
+	// Ws contains WebSocket server transport configuration.
+	//
+	// YAML example:
+	//
+	//	ws:
+	//	  - name: chat
+	//	    path: ./api/ws/chat.yaml   # messages spec
+	//	    port: 8095
+	//
+	// See docs/configuration/transports.md for full documentation.
 	Ws struct {
+		// Name is the unique WebSocket transport name. Required.
 		Name string `mapstructure:"name"`
+		// Path is the path to the messages spec YAML file. Required.
 		Path string `mapstructure:"path"`
-		Port uint   `mapstructure:"port"`
+		// Port is the HTTP port serving WebSocket upgrades. Required.
+		Port uint `mapstructure:"port"`
 	}
 
+	// Repository contains data-access layer configuration.
+	// Each repository generates a typed package in internal/pkg/repository/{name}
+	// with query methods produced by sqlc from db/{name}/queries.sql.
+	//
+	// YAML example:
+	//
+	//	repository:
+	//	  - name: users
+	//	    type_db: postgres
+	//	    driver_db: pgx
+	//
+	// See docs/configuration/infrastructure.md for full documentation.
 	Repository struct {
-		Name     string   `mapstructure:"name"`
-		TypeDB   TypeDB   `mapstructure:"type_db"`
+		// Name is the unique repository name, used as Go package name. Required.
+		Name string `mapstructure:"name"`
+		// TypeDB is the database type. Required. Supported: postgres.
+		TypeDB TypeDB `mapstructure:"type_db"`
+		// DriverDB is the database driver. Required. Supported: pgx.
 		DriverDB DriverDB `mapstructure:"driver_db"`
 	}
 
@@ -469,6 +570,7 @@ type (
 	JSONSchemaList []JSONSchema
 	ConsumerList   []Consumer
 	DriverList     []Driver
+	PluginList     []Plugin
 
 	AppDriver struct {
 		Name     string   `mapstructure:"name"`
@@ -541,6 +643,8 @@ type (
 		DriverList []AppDriver `mapstructure:"driver"`
 		// WorkerList contains worker names for this application.
 		WorkerList []string `mapstructure:"worker"`
+		// RepositoryList contains repository names for this application.
+		RepositoryList []string `mapstructure:"repository"`
 		// KafkaListRaw is raw YAML data (string[] or object[]).
 		KafkaListRaw interface{} `mapstructure:"kafka"`
 		// KafkaList is the normalized kafka list (populated after loading).
@@ -581,6 +685,10 @@ type (
 	Config struct {
 		BasePath       string
 		ConfigFilePath string          // Full path to the config file
+		IncludePaths   []string        // Files included by the config file, in load order
+		Env            string          // Environment whose overrides section is applied, empty for none
+		FS             fs.FS           `mapstructure:"-"` // File system the config was read from by GetConfigFS, nil for the OS one
+		Deprecations   []Deprecations  `mapstructure:"-"` // Deprecated features used by the config files, see LogDeprecations
 		Main           Main            `mapstructure:"main"`
 		Deploy         Deploy          `mapstructure:"deploy"`
 		PostGenerate   []string        `mapstructure:"post_generate"`
@@ -603,9 +711,12 @@ type (
 		Artifacts      []ArtifactType      `mapstructure:"artifacts"`
 		Packaging      PackagingConfig     `mapstructure:"packaging"`
 		Documentation  DocumentationConfig `mapstructure:"documentation"`
+		PluginList     PluginList          `mapstructure:"plugins"`
 
 		RestMap              map[string]Rest
 		GrpcMap              map[string]Grpc
+		WsMap                map[string]Ws
+		RepositoryMap        map[string]Repository
 		DriverMap            map[string]Driver
 		WorkerMap            map[string]Worker
 		CLIMap               map[string]CLI
@@ -621,6 +732,27 @@ type (
 	DriverDB string
 )
 
+const (
+	TypeDBPostgres TypeDB   = "postgres"
+	DriverDBPgx    DriverDB = "pgx"
+)
+
+// repositoryNameRe keeps repository names usable as Go package names
+var repositoryNameRe = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// pluginNameRe keeps plugin names usable in file origins and log messages
+var pluginNameRe = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// schedulerJobNameRe keeps job names usable in metric labels and OnlineConf paths
+var schedulerJobNameRe = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+const (
+	// SchedulerWorkerName is the name of the generated scheduler worker
+	SchedulerWorkerName = "scheduler"
+	// GeneratorTemplateScheduler is the worker template used for the scheduler
+	GeneratorTemplateScheduler = "scheduler"
+)
+
 const (
 	defaultGolangVersion       = "1.26"
 	defaultProtobufVersion     = "1.7.0"
@@ -697,7 +829,7 @@ func (g Git) IsValid() (bool, string) {
 	return true, ""
 }
 
-func (r Rest) IsValid(baseConfigDir string) (bool, string) {
+func (r Rest) IsValid(fsys fs.FS, baseConfigDir string) (bool, string) {
 	if len(r.Name) == 0 {
 		return false, "Empty name"
 	}
@@ -713,7 +845,7 @@ func (r Rest) IsValid(baseConfigDir string) (bool, string) {
 	for _, p := range r.Path {
 		absPath := filepath.Join(baseConfigDir, p)
 
-		if tools.FileExists(absPath) != tools.ErrExist {
+		if tools.FileExistsFS(fsys, absPath) != tools.ErrExist {
 			return false, "Invalid path: " + p
 		}
 	}
@@ -802,6 +934,9 @@ func (w Worker) IsValid(_ string) (bool, string) {
 		if w.GeneratorTemplate == "queue" && len(w.Path) == 0 {
 			return false, "Queue worker requires path to contract file"
 		}
+		if w.GeneratorTemplate == GeneratorTemplateScheduler {
+			return false, "Scheduler worker is generated from the scheduler section"
+		}
 	default:
 		return false, "Invalid generator type"
 	}
@@ -809,7 +944,7 @@ func (w Worker) IsValid(_ string) (bool, string) {
 	return true, ""
 }
 
-func (g Grpc) IsValid(baseConfigDir string) (bool, string) {
+func (g Grpc) IsValid(fsys fs.FS, baseConfigDir string) (bool, string) {
 	if len(g.Name) == 0 {
 		return false, "Empty name"
 	}
@@ -820,7 +955,7 @@ func (g Grpc) IsValid(baseConfigDir string) (bool, string) {
 
 	absPath := filepath.Join(baseConfigDir, g.Path)
 
-	if tools.FileExists(absPath) != tools.ErrExist {
+	if tools.FileExistsFS(fsys, absPath) != tools.ErrExist {
 		return false, "Invalid path: " + g.Path
 	}
 
@@ -833,7 +968,13 @@ func (g Grpc) IsValid(baseConfigDir string) (bool, string) {
 			return false, "instantiation must be 'static' or 'dynamic'"
 		}
 	case "buf_server":
-		return false, "buf_server not yet implemented"
+		if g.Port == 0 {
+			return false, "port is required for buf_server"
+		}
+
+		if g.Instantiation != "" {
+			return false, "instantiation is only supported for buf_client"
+		}
 	case "":
 		return false, "generator_type is required for gRPC"
 	default:
@@ -863,8 +1004,7 @@ func (d Driver) IsValid() (bool, string) {
 	return true, ""
 }
 
-// ToDo WS
-func (w Ws) IsValid(baseConfigDir string) (bool, string) {
+func (w Ws) IsValid(fsys fs.FS, baseConfigDir string) (bool, string) {
 	if len(w.Name) == 0 {
 		return false, "Empty name"
 	}
@@ -873,10 +1013,14 @@ func (w Ws) IsValid(baseConfigDir string) (bool, string) {
 		return false, "Empty path"
 	}
 
-	if tools.FileExists(filepath.Join(baseConfigDir, w.Path)) != tools.ErrExist {
+	if tools.FileExistsFS(fsys, filepath.Join(baseConfigDir, w.Path)) != tools.ErrExist {
 		return false, "Invalid path: " + w.Path
 	}
 
+	if w.Port == 0 {
+		return false, "port is required for ws"
+	}
+
 	return true, ""
 }
 
@@ -995,6 +1139,10 @@ func (a Application) IsValid() (bool, string) {
 			return false, "CLI application cannot have workers"
 		}
 
+		if len(a.RepositoryList) > 0 {
+			return false, "CLI application cannot have repositories"
+		}
+
 		return true, ""
 	}
 
@@ -1029,7 +1177,7 @@ func (c CLI) IsValid() (bool, string) {
 }
 
 // JSONSchema validation
-func (j JSONSchema) IsValid(baseConfigDir string) (bool, string) {
+func (j JSONSchema) IsValid(fsys fs.FS, baseConfigDir string) (bool, string) {
 	if len(j.Name) == 0 {
 		return false, "Empty name"
 	}
@@ -1045,7 +1193,7 @@ func (j JSONSchema) IsValid(baseConfigDir string) (bool, string) {
 			}
 			// Type is optional - will be auto-calculated from filename if empty
 			absPath := filepath.Join(baseConfigDir, s.Path)
-			if !errors.Is(tools.FileExists(absPath), tools.ErrExist) {
+			if !errors.Is(tools.FileExistsFS(fsys, absPath), tools.ErrExist) {
 				return false, "Invalid path: " + s.Path
 			}
 		}
@@ -1057,7 +1205,7 @@ func (j JSONSchema) IsValid(baseConfigDir string) (bool, string) {
 		for _, p := range j.Path {
 			absPath := filepath.Join(baseConfigDir, p)
 
-			if !errors.Is(tools.FileExists(absPath), tools.ErrExist) {
+			if !errors.Is(tools.FileExistsFS(fsys, absPath), tools.ErrExist) {
 				return false, "Invalid path: " + p
 			}
 		}
@@ -1153,16 +1301,121 @@ func (k Kafka) IsValid(jsonSchemaMap map[string]JSONSchema) (bool, string) {
 	return true, ""
 }
 
-// ToDo Repository
 func (r Repository) IsValid() (bool, string) {
 	if len(r.Name) == 0 {
 		return false, "Empty name"
 	}
 
+	if !repositoryNameRe.MatchString(r.Name) {
+		return false, "Invalid name: " + r.Name + " (lowercase letters and digits only)"
+	}
+
 	if len(r.TypeDB) == 0 || len(r.DriverDB) == 0 {
 		return false, "Empty type or driver"
 	}
 
+	if r.TypeDB != TypeDBPostgres {
+		return false, "Unsupported type_db: " + string(r.TypeDB) + " (supported: postgres)"
+	}
+
+	if r.DriverDB != DriverDBPgx {
+		return false, "Unsupported driver_db: " + string(r.DriverDB) + " (supported: pgx)"
+	}
+
+	return true, ""
+}
+
+func (s Scheduler) IsValid() (bool, string) {
+	if !s.Enabled {
+		return true, ""
+	}
+
+	if len(s.Jobs) == 0 {
+		return false, "Enabled scheduler requires at least one job"
+	}
+
+	names := make(map[string]struct{}, len(s.Jobs))
+
+	for _, job := range s.Jobs {
+		if ok, msg := job.IsValid(); !ok {
+			return false, "job " + job.Name + ": " + msg
+		}
+
+		if _, ex := names[job.Name]; ex {
+			return false, "Duplicate job name: " + job.Name
+		}
+
+		names[job.Name] = struct{}{}
+	}
+
+	return true, ""
+}
+
+func (j SchedulerJob) IsValid() (bool, string) {
+	if len(j.Name) == 0 {
+		return false, "Empty name"
+	}
+
+	if !schedulerJobNameRe.MatchString(j.Name) {
+		return false, "Invalid name: " + j.Name + " (snake_case expected)"
+	}
+
+	switch {
+	case len(j.Cron) != 0 && len(j.Interval) != 0:
+		return false, "Only one of cron or interval can be set"
+	case len(j.Cron) != 0:
+		if _, err := cron.ParseStandard(j.Cron); err != nil {
+			return false, "Invalid cron: " + err.Error()
+		}
+	case len(j.Interval) != 0:
+		if ok, msg := isPositiveDuration(j.Interval); !ok {
+			return false, "Invalid interval: " + msg
+		}
+	default:
+		return false, "Either cron or interval is required"
+	}
+
+	if len(j.Timeout) != 0 {
+		if ok, msg := isPositiveDuration(j.Timeout); !ok {
+			return false, "Invalid timeout: " + msg
+		}
+	}
+
+	return true, ""
+}
+
+func (p Plugin) IsValid() (bool, string) {
+	if len(p.Name) == 0 {
+		return false, "Empty name"
+	}
+
+	if !pluginNameRe.MatchString(p.Name) {
+		return false, "Invalid name: " + p.Name + " (lowercase letters, digits, '-' and '_' expected)"
+	}
+
+	if len(p.Command) == 0 {
+		return false, "Empty command for plugin " + p.Name
+	}
+
+	if len(p.Timeout) != 0 {
+		if ok, msg := isPositiveDuration(p.Timeout); !ok {
+			return false, "Invalid timeout: " + msg
+		}
+	}
+
+	return true, ""
+}
+
+func isPositiveDuration(value string) (bool, string) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	if d <= 0 {
+		return false, value + " must be positive"
+	}
+
 	return true, ""
 }
 
@@ -1264,6 +1517,10 @@ func (u PackageUploadConfig) IsEnabled() bool {
 // IsValid validates DocumentationConfig
 func (d DocumentationConfig) IsValid() (bool, string) {
 	if d.Type == "" {
+		if d.Topology {
+			return false, "documentation.topology requires documentation.type"
+		}
+
 		return true, "" // Empty type means documentation is disabled
 	}
 
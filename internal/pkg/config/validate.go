@@ -0,0 +1,672 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/loggers"
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an invalid value of the config
+type Problem struct {
+	File    string `json:"file,omitempty"` // file defining the value, empty if it is not found in the config
+	Line    int    `json:"line,omitempty"` // position of the value in File
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path"`             // path of the value, e.g. applications[0].transport[1]
+	Entity  string `json:"entity,omitempty"` // name of the entity the value belongs to
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"` // suggested fix, empty if there is no obvious one
+}
+
+// String formats the problem as file:line:column: path (entity): message
+func (p Problem) String() string {
+	var b strings.Builder
+
+	if p.File != "" {
+		fmt.Fprintf(&b, "%s:%d:%d: ", p.File, p.Line, p.Column)
+	}
+
+	b.WriteString(p.Path)
+
+	if p.Entity != "" {
+		fmt.Fprintf(&b, " (%s)", p.Entity)
+	}
+
+	b.WriteString(": " + p.Message)
+
+	return b.String()
+}
+
+// validator collects the problems of a config, validation goes on after a problem to report all of them
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(path, entity, message, fix string) {
+	v.problems = append(v.problems, Problem{Path: path, Entity: entity, Message: message, Fix: fix})
+}
+
+// invalid adds a problem found by the IsValid method of a section
+func (v *validator) invalid(section, path, entity, msg string) {
+	v.add(path, entity, "invalid config "+section+" section: "+msg, fixHint(msg))
+}
+
+// fixHints are fixes of common IsValid messages, by message prefix
+var fixHints = []struct {
+	prefix string
+	fix    string
+}{
+	{"Empty name", "add a name"},
+	{"Empty path", "set path to the spec file, relative to the config directory"},
+	{"Invalid path: ", "check that the file exists, paths are relative to the config directory"},
+	{"invalid logger", "set logger to one of: " + strings.Join(sortedKeys(loggers.LoggerMapping), ", ")},
+	{"RegistryType not set", "set main.registry_type: github, digitalocean, aws or selfhosted"},
+	{"Invalid generator type", "check generator_type against the generators supported by the section"},
+	{"Invalid timeout: ", "use a positive Go duration, e.g. 30s or 5m"},
+	{"Invalid interval: ", "use a positive Go duration, e.g. 30s or 5m"},
+	{"Only one of cron or interval can be set", "remove cron or interval"},
+	{"Application must have at least one transport", "add a transport or set cli"},
+	{"CLI application cannot have", "move the transports, workers and repositories to another application"},
+}
+
+func fixHint(msg string) string {
+	for _, hint := range fixHints {
+		if strings.HasPrefix(msg, hint.prefix) {
+			return hint.fix
+		}
+	}
+
+	return ""
+}
+
+// unknownRefFix suggests a fix of a reference to an entity that is not defined
+func unknownRefFix(kind, name string, defined []string) string {
+	if match := closest(name, defined); match != "" {
+		return fmt.Sprintf("did you mean '%s'?", match)
+	}
+
+	return fmt.Sprintf("define %s '%s' or remove the reference", kind, name)
+}
+
+// validateConfig validates the decoded config, fills its maps and defaults. Every problem is returned,
+// in the order of the sections of project.yaml.
+func validateConfig(fsys fs.FS, baseDir string, config *Config) []Problem {
+	v := &validator{}
+
+	if ok, msg := config.Main.IsValid(); !ok { // проверяем валидность конфигурации
+		v.invalid("main", "main", "", msg)
+	}
+
+	// Валидация ArgenVersion когда use_active_record включен
+	if config.Main.UseActiveRecord && len(config.Tools.ArgenVersion) == 0 {
+		v.add("tools.argen_version", "", "ArgenVersion required when use_active_record is true",
+			"set tools.argen_version or disable main.use_active_record")
+	}
+
+	// создаем мапки
+	config.RestMap = make(map[string]Rest)
+	config.GrpcMap = make(map[string]Grpc)
+	config.WsMap = make(map[string]Ws)
+	config.RepositoryMap = make(map[string]Repository)
+	config.DriverMap = make(map[string]Driver)
+	config.WorkerMap = make(map[string]Worker)
+	config.CLIMap = make(map[string]CLI)
+	config.JSONSchemaMap = make(map[string]JSONSchema)
+	config.KafkaMap = make(map[string]Kafka)
+	config.GrafanaDatasourceMap = make(map[string]GrafanaDatasource)
+
+	for i, rest := range config.RestList { // "rest" названия полей
+		path := fmt.Sprintf("rest[%d]", i)
+
+		if ok, msg := rest.IsValid(fsys, baseDir); !ok { // проверяем валидность конфигурации
+			v.invalid("rest", path, rest.Name, msg)
+		}
+
+		if _, ex := config.RestMap[rest.Name]; ex { // проверка, есть ли уже такое имя
+			v.add(path, rest.Name, "duplicate rest name: "+rest.Name, "rename one of the rest transports")
+
+			continue
+		}
+
+		if rest.Version == "" { // если в переменной "rest" типа Rest поле "Version" типа string не задано (пустая строка)
+			config.RestList[i].Version = "v1" // в переменную "config" типа Config в срез RestList по ключу [i] полю "Version" типа string присваиваем значение "v1"
+		}
+
+		config.RestMap[rest.Name] = rest // в переменной "config" типа Config в мапку "RestMap" по ключу [rest.Name] ложим переменную "rest" типа Rest
+	}
+
+	for i, grpc := range config.GrpcList {
+		path := fmt.Sprintf("grpc[%d]", i)
+
+		if ok, msg := grpc.IsValid(fsys, baseDir); !ok {
+			v.invalid("grpc", path, grpc.Name, msg)
+		}
+
+		if _, ex := config.GrpcMap[grpc.Name]; ex {
+			v.add(path, grpc.Name, "duplicate grpc name: "+grpc.Name, "rename one of the grpc transports")
+
+			continue
+		}
+
+		config.GrpcMap[grpc.Name] = grpc
+	}
+
+	for i, ws := range config.WsList {
+		path := fmt.Sprintf("ws[%d]", i)
+
+		if ok, msg := ws.IsValid(fsys, baseDir); !ok {
+			v.invalid("ws", path, ws.Name, msg)
+		}
+
+		if _, ex := config.WsMap[ws.Name]; ex {
+			v.add(path, ws.Name, "duplicate ws name: "+ws.Name, "rename one of the ws transports")
+
+			continue
+		}
+
+		_, exRest := config.RestMap[ws.Name]
+		_, exGrpc := config.GrpcMap[ws.Name]
+
+		if exRest || exGrpc {
+			v.add(path, ws.Name, "ws name conflicts with another transport: "+ws.Name,
+				"transport names are shared by rest, grpc and ws, rename the ws transport")
+		}
+
+		config.WsMap[ws.Name] = ws
+	}
+
+	for i, driver := range config.DriverList {
+		path := fmt.Sprintf("driver[%d]", i)
+
+		if ok, msg := driver.IsValid(); !ok {
+			v.invalid("driver", path, driver.Name, msg)
+		}
+
+		if _, ex := config.DriverMap[driver.Name]; ex {
+			v.add(path, driver.Name, "duplicate driver name: "+driver.Name, "rename one of the drivers")
+
+			continue
+		}
+
+		config.DriverMap[driver.Name] = driver
+	}
+
+	for i, worker := range config.WorkerList {
+		path := fmt.Sprintf("worker[%d]", i)
+
+		if ok, msg := worker.IsValid(baseDir); !ok {
+			v.invalid("worker", path, worker.Name, msg)
+		}
+
+		if _, ex := config.WorkerMap[worker.Name]; ex {
+			v.add(path, worker.Name, "duplicate worker name: "+worker.Name, "rename one of the workers")
+
+			continue
+		}
+
+		config.WorkerMap[worker.Name] = worker
+	}
+
+	if ok, msg := config.Scheduler.IsValid(); !ok {
+		v.invalid("scheduler", "scheduler", "", msg)
+	}
+
+	// The scheduler is run by applications like any other worker
+	if config.Scheduler.Enabled {
+		if _, ex := config.WorkerMap[SchedulerWorkerName]; ex {
+			v.add("scheduler", "", "worker name '"+SchedulerWorkerName+"' is reserved when scheduler is enabled",
+				"rename the worker '"+SchedulerWorkerName+"'")
+		}
+
+		config.WorkerMap[SchedulerWorkerName] = Worker{
+			Name:              SchedulerWorkerName,
+			GeneratorType:     "template",
+			GeneratorTemplate: GeneratorTemplateScheduler,
+		}
+	}
+
+	for i, cli := range config.CLIList {
+		path := fmt.Sprintf("cli[%d]", i)
+
+		if ok, msg := cli.IsValid(); !ok {
+			v.invalid("cli", path, cli.Name, msg)
+		}
+
+		if _, ex := config.CLIMap[cli.Name]; ex {
+			v.add(path, cli.Name, "duplicate cli name: "+cli.Name, "rename one of the cli transports")
+
+			continue
+		}
+
+		config.CLIMap[cli.Name] = cli
+	}
+
+	for i, js := range config.JSONSchemaList {
+		path := fmt.Sprintf("jsonschema[%d]", i)
+
+		if ok, msg := js.IsValid(fsys, baseDir); !ok {
+			v.invalid("jsonschema", path, js.Name, msg)
+		}
+
+		if _, ex := config.JSONSchemaMap[js.Name]; ex {
+			v.add(path, js.Name, "duplicate jsonschema name: "+js.Name, "rename one of the jsonschema sets")
+
+			continue
+		}
+
+		config.JSONSchemaMap[js.Name] = js
+	}
+
+	for i, repo := range config.RepositoryList {
+		path := fmt.Sprintf("repository[%d]", i)
+
+		if ok, msg := repo.IsValid(); !ok {
+			v.invalid("repository", path, repo.Name, msg)
+		}
+
+		if _, ex := config.RepositoryMap[repo.Name]; ex {
+			v.add(path, repo.Name, "duplicate repository name: "+repo.Name, "rename one of the repositories")
+
+			continue
+		}
+
+		config.RepositoryMap[repo.Name] = repo
+	}
+
+	for i, kafka := range config.KafkaList {
+		path := fmt.Sprintf("kafka[%d]", i)
+
+		if ok, msg := kafka.IsValid(config.JSONSchemaMap); !ok {
+			v.invalid("kafka", path, kafka.Name, msg)
+		}
+
+		if _, ex := config.KafkaMap[kafka.Name]; ex {
+			v.add(path, kafka.Name, "duplicate kafka name: "+kafka.Name, "rename one of the kafka sections")
+
+			continue
+		}
+
+		config.KafkaMap[kafka.Name] = kafka
+	}
+
+	pluginNames := make(map[string]struct{}, len(config.PluginList))
+
+	for i, plugin := range config.PluginList {
+		path := fmt.Sprintf("plugins[%d]", i)
+
+		if ok, msg := plugin.IsValid(); !ok {
+			v.invalid("plugins", path, plugin.Name, msg)
+		}
+
+		if _, ex := pluginNames[plugin.Name]; ex {
+			v.add(path, plugin.Name, "duplicate plugin name: "+plugin.Name, "rename one of the plugins")
+
+			continue
+		}
+
+		pluginNames[plugin.Name] = struct{}{}
+	}
+
+	// Validate Grafana configuration
+	if ok, msg := config.Grafana.IsValid(); !ok {
+		v.invalid("grafana", "grafana", "", msg)
+	}
+
+	for _, ds := range config.Grafana.Datasources {
+		config.GrafanaDatasourceMap[ds.Name] = ds
+	}
+
+	// Validate documentation configuration
+	if ok, msg := config.Documentation.IsValid(); !ok {
+		v.invalid("documentation", "documentation", "", msg)
+	}
+
+	normalized := true
+
+	for i := range config.Applications {
+		validateApplication(config, i, v, &normalized)
+	}
+
+	// Validate dev_stand requires git_install in post_generate
+	if config.Main.DevStand && !slices.Contains(config.PostGenerate, "git_install") {
+		v.add("post_generate", "", "dev_stand requires 'git_install' in post_generate section",
+			"add git_install to post_generate")
+	}
+
+	// Validate that all defined entities are used in at least one application.
+	// Entities of applications not normalized are unknown, they would be reported as not used.
+	if normalized {
+		validateEntityUsage(config, v)
+	}
+
+	return v.problems
+}
+
+// validateApplication validates the application i and its references to the entities of the config,
+// normalized is reset if its transport or kafka lists can't be read
+func validateApplication(config *Config, i int, v *validator, normalized *bool) {
+	path := fmt.Sprintf("applications[%d]", i)
+	appName := config.Applications[i].Name
+
+	// Normalize transport list (supports both old string[] and new object[] format)
+	if err := config.Applications[i].NormalizeTransports(); err != nil {
+		v.add(path+".transport", appName, fmt.Sprintf("application[%d] '%s': %s", i, appName, err.Error()),
+			"use object format: `- name: transport_name`")
+
+		*normalized = false
+	}
+
+	// Normalize kafka list (supports both old string[] and new object[] format)
+	if err := config.Applications[i].NormalizeKafka(); err != nil {
+		v.add(path+".kafka", appName, fmt.Sprintf("application[%d] '%s': %s", i, appName, err.Error()),
+			"use object format: `- name: kafka_name`")
+
+		*normalized = false
+	}
+
+	app := config.Applications[i]
+
+	if ok, msg := app.IsValid(); !ok {
+		v.invalid("application", path, app.Name, msg)
+	}
+
+	// Валидация use_active_record: может быть только false (для отключения AR)
+	if app.UseActiveRecord != nil && *app.UseActiveRecord {
+		v.add(path+".use_active_record", app.Name,
+			"application '"+app.Name+"': use_active_record can only be set to false (to disable AR for specific app)",
+			"remove use_active_record, applications use ActiveRecord when main.use_active_record is set")
+	}
+
+	// Валидация use_envs: может быть только true или nil, false запрещен
+	if app.UseEnvs != nil && !*app.UseEnvs {
+		v.add(path+".use_envs", app.Name,
+			fmt.Sprintf("application[%d] '%s': use_envs can only be true or omitted, false is not allowed", i, app.Name),
+			"remove use_envs")
+	}
+
+	transports := append(append(sortedKeys(config.RestMap), sortedKeys(config.GrpcMap)...), sortedKeys(config.WsMap)...)
+
+	for j, transport := range app.TransportList {
+		transportPath := fmt.Sprintf("%s.transport[%d]", path, j)
+
+		_, exRest := config.RestMap[transport.Name]
+		_, exGrpc := config.GrpcMap[transport.Name]
+		_, exWs := config.WsMap[transport.Name]
+
+		if !exRest && !exGrpc && !exWs {
+			v.add(transportPath, app.Name, "unknown transport: "+transport.Name+" in application: "+app.Name,
+				unknownRefFix("a rest, grpc or ws transport", transport.Name, transports))
+
+			continue
+		}
+
+		// Validate instantiation is only allowed for ogen_client
+		if transport.Config.Instantiation != "" {
+			rest, isRest := config.RestMap[transport.Name]
+			if !isRest || rest.GeneratorType != GeneratorTypeOgenClient {
+				v.add(transportPath+".config.instantiation", app.Name,
+					fmt.Sprintf("transport '%s' in application '%s': %s",
+						transport.Name, app.Name, errInstantiationOnlyOgenClient),
+					"remove instantiation")
+			}
+		}
+	}
+
+	for j, driver := range app.DriverList {
+		if _, ex := config.DriverMap[driver.Name]; !ex {
+			v.add(fmt.Sprintf("%s.driver[%d]", path, j), app.Name,
+				"unknown driver: "+driver.Name+" in application: "+app.Name,
+				unknownRefFix("a driver", driver.Name, sortedKeys(config.DriverMap)))
+		}
+	}
+
+	for j, worker := range app.WorkerList {
+		if _, ex := config.WorkerMap[worker]; !ex {
+			v.add(fmt.Sprintf("%s.worker[%d]", path, j), app.Name,
+				"unknown worker: "+worker+" in application: "+app.Name,
+				unknownRefFix("a worker", worker, sortedKeys(config.WorkerMap)))
+		}
+	}
+
+	for j, repo := range app.RepositoryList {
+		if _, ex := config.RepositoryMap[repo]; !ex {
+			v.add(fmt.Sprintf("%s.repository[%d]", path, j), app.Name,
+				"unknown repository: "+repo+" in application: "+app.Name,
+				unknownRefFix("a repository", repo, sortedKeys(config.RepositoryMap)))
+		}
+	}
+
+	// Validate CLI reference
+	if app.CLI != "" {
+		if _, ex := config.CLIMap[app.CLI]; !ex {
+			v.add(path+".cli", app.Name, "unknown cli: "+app.CLI+" in application: "+app.Name,
+				unknownRefFix("a cli", app.CLI, sortedKeys(config.CLIMap)))
+		}
+	}
+
+	// Validate Kafka references
+	for j, appKafka := range app.KafkaList {
+		if _, ex := config.KafkaMap[appKafka.Name]; !ex {
+			v.add(fmt.Sprintf("%s.kafka[%d]", path, j), app.Name,
+				"unknown kafka: "+appKafka.Name+" in application: "+app.Name,
+				unknownRefFix("a kafka", appKafka.Name, sortedKeys(config.KafkaMap)))
+		}
+	}
+
+	// Validate Grafana datasource references
+	for j, dsName := range app.Grafana.Datasources {
+		if _, ex := config.GrafanaDatasourceMap[dsName]; !ex {
+			v.add(fmt.Sprintf("%s.grafana.datasources[%d]", path, j), app.Name,
+				"unknown grafana datasource: "+dsName+" in application: "+app.Name,
+				unknownRefFix("a grafana datasource", dsName, sortedKeys(config.GrafanaDatasourceMap)))
+		}
+	}
+}
+
+// validateEntityUsage checks that all defined entities (rest, grpc, ws, kafka, drivers, workers, repositories)
+// are referenced in at least one application
+func validateEntityUsage(config *Config, v *validator) {
+	usedTransports := make(map[string]bool)
+	usedKafka := make(map[string]bool)
+	usedDrivers := make(map[string]bool)
+	usedWorkers := make(map[string]bool)
+	usedCLI := make(map[string]bool)
+	usedRepositories := make(map[string]bool)
+
+	for _, app := range config.Applications {
+		for _, t := range app.TransportList {
+			usedTransports[t.Name] = true
+		}
+
+		for _, k := range app.KafkaList {
+			usedKafka[k.Name] = true
+		}
+
+		for _, d := range app.DriverList {
+			usedDrivers[d.Name] = true
+		}
+
+		for _, w := range app.WorkerList {
+			usedWorkers[w] = true
+		}
+
+		for _, r := range app.RepositoryList {
+			usedRepositories[r] = true
+		}
+
+		if app.CLI != "" {
+			usedCLI[app.CLI] = true
+		}
+	}
+
+	// unused reports the entities of a section not used by applications, a duplicate name is reported once
+	unused := func(section, key string, names []string, used map[string]bool) {
+		for i, name := range names {
+			if used[name] || slices.Contains(names[:i], name) {
+				continue
+			}
+
+			v.add(key+"["+strconv.Itoa(i)+"]", name, fmt.Sprintf("%s '%s' is not used in any application", section, name),
+				fmt.Sprintf("add it to the %s list of an application or remove it", key))
+		}
+	}
+
+	unused("rest", "rest", entityNames(config.RestList, func(r Rest) string { return r.Name }), usedTransports)
+	unused("grpc", "grpc", entityNames(config.GrpcList, func(g Grpc) string { return g.Name }), usedTransports)
+	unused("ws", "ws", entityNames(config.WsList, func(w Ws) string { return w.Name }), usedTransports)
+	unused("kafka", "kafka", entityNames(config.KafkaList, func(k Kafka) string { return k.Name }), usedKafka)
+	unused("driver", "driver", entityNames(config.DriverList, func(d Driver) string { return d.Name }), usedDrivers)
+	unused("repository", "repository",
+		entityNames(config.RepositoryList, func(r Repository) string { return r.Name }), usedRepositories)
+	unused("worker", "worker", entityNames(config.WorkerList, func(w Worker) string { return w.Name }), usedWorkers)
+	unused("cli", "cli", entityNames(config.CLIList, func(c CLI) string { return c.Name }), usedCLI)
+
+	if config.Scheduler.Enabled && !usedWorkers[SchedulerWorkerName] {
+		v.add("scheduler", "", fmt.Sprintf("worker '%s' is not used in any application", SchedulerWorkerName),
+			fmt.Sprintf("add %s to the worker list of an application or disable the scheduler", SchedulerWorkerName))
+	}
+}
+
+func entityNames[T any](items []T, name func(T) string) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, name(item))
+	}
+
+	return names
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// closest returns the candidate nearest to name if it is a likely typo of it, empty string otherwise
+func closest(name string, candidates []string) string {
+	best, bestDistance := "", max(2, len(name)/3)+1
+
+	for _, candidate := range candidates {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+// editDistance is the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+// Validate loads the config like GetConfigEnv and returns all its problems: values of unknown keys or
+// of wrong types and everything GetConfigEnv rejects. Problems carry the file and position of the value.
+// The error is returned if the config can't be read at all, e.g. a file is not valid YAML.
+func Validate(baseDir, configPath, env string) ([]Problem, error) {
+	return validate(nil, baseDir, ConfigFile(baseDir, configPath), env)
+}
+
+// ValidateFS is Validate for a config read from fsys, see GetConfigFS
+func ValidateFS(fsys fs.FS, configPath, env string) ([]Problem, error) {
+	return validate(fsys, filepath.Dir(configPath), configPath, env)
+}
+
+func validate(fsys fs.FS, baseDir, configPath, env string) ([]Problem, error) {
+	composed, err := composeConfig(fsys, configPath, env)
+	if err != nil {
+		return nil, err
+	}
+
+	problems, decodable := composed.checkStructure()
+	if !decodable {
+		return problems, nil
+	}
+
+	config, err := decodeConfig(composed)
+	if err != nil {
+		return nil, err
+	}
+
+	problems = append(problems, validateConfig(fsys, baseDir, &config)...)
+
+	for i := range problems {
+		if problems[i].File == "" {
+			composed.locate(&problems[i])
+		}
+	}
+
+	return problems, nil
+}
+
+// locate sets the file and position of a problem from its path, the nearest existing parent
+// is used for values missing in the config
+func (c composedConfig) locate(p *Problem) {
+	node := c.root
+
+	for _, segment := range strings.Split(p.Path, ".") {
+		key, index := segment, -1
+
+		if open := strings.IndexByte(segment, '['); open >= 0 && strings.HasSuffix(segment, "]") {
+			key = segment[:open]
+
+			if n, err := strconv.Atoi(segment[open+1 : len(segment)-1]); err == nil {
+				index = n
+			}
+		}
+
+		next := tools.MappingValue(node, key)
+		if next == nil {
+			break
+		}
+
+		node = next
+
+		if index < 0 {
+			continue
+		}
+
+		if node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+			break
+		}
+
+		node = node.Content[index]
+	}
+
+	p.File = c.files[node]
+	p.Line, p.Column = node.Line, node.Column
+}
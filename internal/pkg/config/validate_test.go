@@ -0,0 +1,115 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(dir, "api.yaml"), "openapi: 3.0.0\n")
+	writeConfigFile(t, filepath.Join(dir, "project.yaml"), `main:
+  name: shop
+  registry_type: github
+  logger: zerolog
+include: transports.yaml
+applications:
+  - name: api
+    transport:
+      - name: pubic
+    use_envs: maybe
+`)
+	writeConfigFile(t, filepath.Join(dir, "transports.yaml"), `rest:
+  - name: public
+    path: [./api.yaml]
+    generator_type: ogen
+    prot: 8080
+  - name: admin
+    generator_type: ogen
+`)
+
+	problems, err := Validate(dir, "project.yaml", "")
+	require.NoError(t, err)
+
+	main, included := filepath.Join(dir, "project.yaml"), filepath.Join(dir, "transports.yaml")
+
+	assert.Equal(t, []Problem{
+		{
+			File: main, Line: 10, Column: 15, Path: "applications[0].use_envs", Entity: "api",
+			Message: `expected a boolean, got "maybe"`,
+		},
+		{
+			File: included, Line: 5, Column: 5, Path: "rest[0].prot", Entity: "public",
+			Message: "unknown key 'prot'", Fix: "did you mean 'port'?",
+		},
+		{
+			File: included, Line: 6, Column: 5, Path: "rest[1]", Entity: "admin",
+			Message: "invalid config rest section: Empty path",
+			Fix:     "set path to the spec file, relative to the config directory",
+		},
+		{
+			File: main, Line: 9, Column: 9, Path: "applications[0].transport[0]", Entity: "api",
+			Message: "unknown transport: pubic in application: api", Fix: "did you mean 'public'?",
+		},
+		{
+			File: included, Line: 2, Column: 5, Path: "rest[0]", Entity: "public",
+			Message: "rest 'public' is not used in any application",
+			Fix:     "add it to the rest list of an application or remove it",
+		},
+		{
+			File: included, Line: 6, Column: 5, Path: "rest[1]", Entity: "admin",
+			Message: "rest 'admin' is not used in any application",
+			Fix:     "add it to the rest list of an application or remove it",
+		},
+	}, problems)
+
+	// GetConfig fails with the first problem found on the decoded config
+	writeConfigFile(t, filepath.Join(dir, "project.yaml"), `main:
+  name: shop
+  registry_type: github
+  logger: zerolog
+include: transports.yaml
+`)
+
+	_, err = GetConfig(dir, "project.yaml")
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.EqualError(t, err, "invalid config rest section: Empty path: invalid config")
+}
+
+func TestValidate_Valid(t *testing.T) {
+	dir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(dir, "project.yaml"), `main:
+  name: shop
+  registry_type: github
+  logger: zerolog
+cli:
+  - name: admin
+    generator_type: template
+    generator_template: cli
+applications:
+  - name: admin
+    cli: admin
+`)
+
+	problems, err := Validate(dir, "project.yaml", "")
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	writeConfigFile(t, filepath.Join(dir, "broken.yaml"), "main: [\n")
+
+	_, err = Validate(dir, "broken.yaml", "")
+	assert.Error(t, err)
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"public", "admin", "generator_params"}
+
+	assert.Equal(t, "public", closest("pubic", candidates))
+	assert.Equal(t, "generator_params", closest("genarator_params", candidates))
+	assert.Equal(t, "", closest("billing", candidates))
+}
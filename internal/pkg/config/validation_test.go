@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -238,6 +240,16 @@ func TestWorker_IsValid(t *testing.T) {
 			wantOK:  false,
 			wantMsg: "Generator params not supported",
 		},
+		{
+			name: "scheduler template is reserved",
+			worker: Worker{
+				Name:              "jobs",
+				GeneratorType:     "template",
+				GeneratorTemplate: "scheduler",
+			},
+			wantOK:  false,
+			wantMsg: "Scheduler worker is generated from the scheduler section",
+		},
 	}
 
 	for _, tt := range tests {
@@ -344,6 +356,150 @@ func TestDriver_IsValid(t *testing.T) {
 	}
 }
 
+func TestGrpc_IsValid(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "users.proto"), []byte("syntax = \"proto3\";"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		grpc    Grpc
+		wantOK  bool
+		wantMsg string
+	}{
+		{
+			name:   "valid buf_client",
+			grpc:   Grpc{Name: "users", Path: "users.proto", Port: 9000, GeneratorType: "buf_client"},
+			wantOK: true,
+		},
+		{
+			name:   "valid buf_client dynamic",
+			grpc:   Grpc{Name: "users", Path: "users.proto", GeneratorType: "buf_client", Instantiation: "dynamic"},
+			wantOK: true,
+		},
+		{
+			name:   "valid buf_server",
+			grpc:   Grpc{Name: "users", Path: "users.proto", Port: 9000, GeneratorType: "buf_server"},
+			wantOK: true,
+		},
+		{
+			name:    "buf_server without port",
+			grpc:    Grpc{Name: "users", Path: "users.proto", GeneratorType: "buf_server"},
+			wantOK:  false,
+			wantMsg: "port is required for buf_server",
+		},
+		{
+			name:    "buf_server with instantiation",
+			grpc:    Grpc{Name: "users", Path: "users.proto", Port: 9000, GeneratorType: "buf_server", Instantiation: "static"},
+			wantOK:  false,
+			wantMsg: "instantiation is only supported for buf_client",
+		},
+		{
+			name:    "invalid instantiation",
+			grpc:    Grpc{Name: "users", Path: "users.proto", GeneratorType: "buf_client", Instantiation: "lazy"},
+			wantOK:  false,
+			wantMsg: "instantiation must be 'static' or 'dynamic'",
+		},
+		{
+			name:    "empty name",
+			grpc:    Grpc{Path: "users.proto", GeneratorType: "buf_server", Port: 9000},
+			wantOK:  false,
+			wantMsg: "Empty name",
+		},
+		{
+			name:    "missing proto file",
+			grpc:    Grpc{Name: "users", Path: "missing.proto", GeneratorType: "buf_server", Port: 9000},
+			wantOK:  false,
+			wantMsg: "Invalid path: missing.proto",
+		},
+		{
+			name:    "empty generator type",
+			grpc:    Grpc{Name: "users", Path: "users.proto", Port: 9000},
+			wantOK:  false,
+			wantMsg: "generator_type is required for gRPC",
+		},
+		{
+			name:    "unknown generator type",
+			grpc:    Grpc{Name: "users", Path: "users.proto", Port: 9000, GeneratorType: "grpc_gateway"},
+			wantOK:  false,
+			wantMsg: "invalid generator_type: grpc_gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOK, gotMsg := tt.grpc.IsValid(nil, baseDir)
+
+			if gotOK != tt.wantOK {
+				t.Errorf("Grpc.IsValid() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+
+			if !tt.wantOK && gotMsg != tt.wantMsg {
+				t.Errorf("Grpc.IsValid() msg = %q, want %q", gotMsg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWs_IsValid(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "chat.yaml"), []byte("messages: []"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ws      Ws
+		wantOK  bool
+		wantMsg string
+	}{
+		{
+			name:   "valid",
+			ws:     Ws{Name: "chat", Path: "chat.yaml", Port: 8095},
+			wantOK: true,
+		},
+		{
+			name:    "empty name",
+			ws:      Ws{Path: "chat.yaml", Port: 8095},
+			wantOK:  false,
+			wantMsg: "Empty name",
+		},
+		{
+			name:    "empty path",
+			ws:      Ws{Name: "chat", Port: 8095},
+			wantOK:  false,
+			wantMsg: "Empty path",
+		},
+		{
+			name:    "missing spec file",
+			ws:      Ws{Name: "chat", Path: "missing.yaml", Port: 8095},
+			wantOK:  false,
+			wantMsg: "Invalid path: missing.yaml",
+		},
+		{
+			name:    "without port",
+			ws:      Ws{Name: "chat", Path: "chat.yaml"},
+			wantOK:  false,
+			wantMsg: "port is required for ws",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOK, gotMsg := tt.ws.IsValid(nil, baseDir)
+
+			if gotOK != tt.wantOK {
+				t.Errorf("Ws.IsValid() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+
+			if !tt.wantOK && gotMsg != tt.wantMsg {
+				t.Errorf("Ws.IsValid() msg = %q, want %q", gotMsg, tt.wantMsg)
+			}
+		})
+	}
+}
+
 func TestCLI_IsValid(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -485,6 +641,16 @@ func TestApplication_IsValid(t *testing.T) {
 			wantOK:  false,
 			wantMsg: "CLI application cannot have workers",
 		},
+		{
+			name: "CLI app with repositories",
+			app: Application{
+				Name:           "cli-app",
+				CLI:            "admin",
+				RepositoryList: []string{"users"},
+			},
+			wantOK:  false,
+			wantMsg: "CLI application cannot have repositories",
+		},
 		{
 			name: "transport with empty name",
 			app: Application{
@@ -1271,6 +1437,36 @@ func TestRepository_IsValid(t *testing.T) {
 			wantOK:  false,
 			wantMsg: "Empty type or driver",
 		},
+		{
+			name: "name is not a package name",
+			repo: Repository{
+				Name:     "user_events",
+				TypeDB:   "postgres",
+				DriverDB: "pgx",
+			},
+			wantOK:  false,
+			wantMsg: "Invalid name: user_events (lowercase letters and digits only)",
+		},
+		{
+			name: "unsupported type",
+			repo: Repository{
+				Name:     "users",
+				TypeDB:   "mysql",
+				DriverDB: "pgx",
+			},
+			wantOK:  false,
+			wantMsg: "Unsupported type_db: mysql (supported: postgres)",
+		},
+		{
+			name: "unsupported driver",
+			repo: Repository{
+				Name:     "users",
+				TypeDB:   "postgres",
+				DriverDB: "pq",
+			},
+			wantOK:  false,
+			wantMsg: "Unsupported driver_db: pq (supported: pgx)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1288,6 +1484,186 @@ func TestRepository_IsValid(t *testing.T) {
 	}
 }
 
+func TestScheduler_IsValid(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduler Scheduler
+		wantOK    bool
+		wantMsg   string
+	}{
+		{
+			name:      "disabled without jobs",
+			scheduler: Scheduler{},
+			wantOK:    true,
+		},
+		{
+			name: "valid cron and interval jobs",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs: []SchedulerJob{
+					{Name: "cleanup_sessions", Cron: "*/5 * * * *", Timeout: "1m", Singleton: true},
+					{Name: "refresh_rates", Interval: "30s"},
+					{Name: "daily_report", Cron: "@daily"},
+				},
+			},
+			wantOK: true,
+		},
+		{
+			name:      "enabled without jobs",
+			scheduler: Scheduler{Enabled: true},
+			wantOK:    false,
+			wantMsg:   "Enabled scheduler requires at least one job",
+		},
+		{
+			name: "duplicate job name",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs: []SchedulerJob{
+					{Name: "sync", Interval: "1m"},
+					{Name: "sync", Cron: "@hourly"},
+				},
+			},
+			wantOK:  false,
+			wantMsg: "Duplicate job name: sync",
+		},
+		{
+			name: "invalid job name",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs:    []SchedulerJob{{Name: "Sync-Users", Interval: "1m"}},
+			},
+			wantOK:  false,
+			wantMsg: "job Sync-Users: Invalid name: Sync-Users (snake_case expected)",
+		},
+		{
+			name: "double underscore in job name",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs:    []SchedulerJob{{Name: "sync__users", Interval: "1m"}},
+			},
+			wantOK:  false,
+			wantMsg: "job sync__users: Invalid name: sync__users (snake_case expected)",
+		},
+		{
+			name: "no schedule",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs:    []SchedulerJob{{Name: "sync"}},
+			},
+			wantOK:  false,
+			wantMsg: "job sync: Either cron or interval is required",
+		},
+		{
+			name: "both cron and interval",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs:    []SchedulerJob{{Name: "sync", Cron: "@hourly", Interval: "1h"}},
+			},
+			wantOK:  false,
+			wantMsg: "job sync: Only one of cron or interval can be set",
+		},
+		{
+			name: "invalid cron",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs:    []SchedulerJob{{Name: "sync", Cron: "* * *"}},
+			},
+			wantOK:  false,
+			wantMsg: "job sync: Invalid cron: expected exactly 5 fields, found 3: [* * *]",
+		},
+		{
+			name: "invalid interval",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs:    []SchedulerJob{{Name: "sync", Interval: "often"}},
+			},
+			wantOK:  false,
+			wantMsg: `job sync: Invalid interval: time: invalid duration "often"`,
+		},
+		{
+			name: "negative timeout",
+			scheduler: Scheduler{
+				Enabled: true,
+				Jobs:    []SchedulerJob{{Name: "sync", Interval: "1m", Timeout: "-1s"}},
+			},
+			wantOK:  false,
+			wantMsg: "job sync: Invalid timeout: -1s must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOK, gotMsg := tt.scheduler.IsValid()
+
+			if gotOK != tt.wantOK {
+				t.Errorf("Scheduler.IsValid() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+
+			if !tt.wantOK && gotMsg != tt.wantMsg {
+				t.Errorf("Scheduler.IsValid() msg = %q, want %q", gotMsg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestPlugin_IsValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugin  Plugin
+		wantOK  bool
+		wantMsg string
+	}{
+		{
+			name:   "valid plugin",
+			plugin: Plugin{Name: "internal-rpc", Command: "./plugins/rpc-gen", Timeout: "30s"},
+			wantOK: true,
+		},
+		{
+			name:   "command from PATH without timeout",
+			plugin: Plugin{Name: "rpc_gen", Command: "rpc-gen"},
+			wantOK: true,
+		},
+		{
+			name:    "empty name",
+			plugin:  Plugin{Command: "rpc-gen"},
+			wantOK:  false,
+			wantMsg: "Empty name",
+		},
+		{
+			name:    "invalid name",
+			plugin:  Plugin{Name: "Internal RPC", Command: "rpc-gen"},
+			wantOK:  false,
+			wantMsg: "Invalid name: Internal RPC (lowercase letters, digits, '-' and '_' expected)",
+		},
+		{
+			name:    "empty command",
+			plugin:  Plugin{Name: "rpc"},
+			wantOK:  false,
+			wantMsg: "Empty command for plugin rpc",
+		},
+		{
+			name:    "invalid timeout",
+			plugin:  Plugin{Name: "rpc", Command: "rpc-gen", Timeout: "0s"},
+			wantOK:  false,
+			wantMsg: "Invalid timeout: 0s must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOK, gotMsg := tt.plugin.IsValid()
+
+			if gotOK != tt.wantOK {
+				t.Errorf("Plugin.IsValid() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+
+			if !tt.wantOK && gotMsg != tt.wantMsg {
+				t.Errorf("Plugin.IsValid() msg = %q, want %q", gotMsg, tt.wantMsg)
+			}
+		})
+	}
+}
+
 func TestConsumer_IsValid(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	WsDirectionIn  = "in"
+	WsDirectionOut = "out"
+
+	// wsErrorMessage is the envelope type the generated server uses to report failures
+	wsErrorMessage = "error"
+)
+
+// WsSpecField represents a single field of a WebSocket message payload
+type WsSpecField struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+// WsSpecMessage represents a single WebSocket message type
+type WsSpecMessage struct {
+	Name string `yaml:"name"`
+	// Direction is "in" (client to server, default) or "out" (server to client)
+	Direction string        `yaml:"direction"`
+	Fields    []WsSpecField `yaml:"fields"`
+}
+
+// WsSpec represents the full WebSocket messages specification
+type WsSpec struct {
+	Messages []WsSpecMessage `yaml:"messages"`
+}
+
+// wsNameRe matches message and field names that convert to Go identifiers
+var wsNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// validWsFieldTypes contains allowed payload field types
+var validWsFieldTypes = map[string]bool{
+	"string":   true,
+	"int":      true,
+	"int64":    true,
+	"float64":  true,
+	"bool":     true,
+	"[]string": true,
+	"[]int":    true,
+	"[]int64":  true,
+}
+
+// ParseWsSpec reads and parses a WebSocket messages spec YAML file, from fsys or the OS file system if fsys is nil
+func ParseWsSpec(fsys fs.FS, path string) (*WsSpec, error) {
+	data, err := tools.ReadFile(fsys, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read ws spec file: %s", path)
+	}
+
+	var spec WsSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse ws spec file: %s", path)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid ws spec: %s", path)
+	}
+
+	return &spec, nil
+}
+
+// Validate checks the ws spec for errors and fills default directions
+func (s *WsSpec) Validate() error {
+	if len(s.Messages) == 0 {
+		return errors.New("no messages defined")
+	}
+
+	// keyed by Go name, since "user_joined" and "userJoined" would generate the same type
+	seenMessages := make(map[string]string)
+	hasIncoming := false
+
+	for i, msg := range s.Messages {
+		if msg.Name == "" {
+			return errors.New("message name is empty")
+		}
+
+		if !wsNameRe.MatchString(msg.Name) {
+			return fmt.Errorf("invalid message name: %s", msg.Name)
+		}
+
+		goName := wsGoName(msg.Name)
+
+		if goName == wsGoName(wsErrorMessage) {
+			return fmt.Errorf("message name '%s' is reserved", wsErrorMessage)
+		}
+
+		if prev, exists := seenMessages[goName]; exists {
+			if prev == msg.Name {
+				return fmt.Errorf("duplicate message name: %s", msg.Name)
+			}
+
+			return fmt.Errorf("message names '%s' and '%s' map to the same Go name %s", prev, msg.Name, goName)
+		}
+		seenMessages[goName] = msg.Name
+
+		switch msg.Direction {
+		case "":
+			s.Messages[i].Direction = WsDirectionIn
+			hasIncoming = true
+		case WsDirectionIn:
+			hasIncoming = true
+		case WsDirectionOut:
+		default:
+			return fmt.Errorf("message '%s': invalid direction '%s' (valid: in, out)", msg.Name, msg.Direction)
+		}
+
+		seenFields := make(map[string]string)
+		for _, f := range msg.Fields {
+			if f.Name == "" {
+				return fmt.Errorf("message '%s': field name is empty", msg.Name)
+			}
+
+			if !wsNameRe.MatchString(f.Name) {
+				return fmt.Errorf("message '%s': invalid field name: %s", msg.Name, f.Name)
+			}
+
+			fieldGoName := wsGoName(f.Name)
+			if prev, exists := seenFields[fieldGoName]; exists {
+				if prev == f.Name {
+					return fmt.Errorf("message '%s': duplicate field name: %s", msg.Name, f.Name)
+				}
+
+				return fmt.Errorf("message '%s': field names '%s' and '%s' map to the same Go name %s", msg.Name, prev, f.Name, fieldGoName)
+			}
+			seenFields[fieldGoName] = f.Name
+
+			if !validWsFieldTypes[f.Type] {
+				return fmt.Errorf("message '%s': field '%s': invalid type '%s'", msg.Name, f.Name, f.Type)
+			}
+		}
+	}
+
+	if !hasIncoming {
+		return errors.New("no incoming messages defined")
+	}
+
+	return nil
+}
+
+// wsGoName mirrors the generator's conversion of spec names to exported Go identifiers
+func wsGoName(name string) string {
+	var b strings.Builder
+
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+
+	return b.String()
+}
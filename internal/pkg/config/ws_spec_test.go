@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeWsSpec(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "messages.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	return path
+}
+
+func TestParseWsSpec_Valid(t *testing.T) {
+	path := writeWsSpec(t, `
+messages:
+  - name: chat_send
+    fields:
+      - name: room
+        type: string
+      - name: text
+        type: string
+  - name: ping
+  - name: chat_message
+    direction: out
+    fields:
+      - name: user_ids
+        type: "[]int64"
+`)
+
+	spec, err := ParseWsSpec(nil, path)
+	require.NoError(t, err)
+	require.Len(t, spec.Messages, 3)
+	assert.Equal(t, WsDirectionIn, spec.Messages[0].Direction)
+	assert.Len(t, spec.Messages[0].Fields, 2)
+	assert.Equal(t, WsDirectionIn, spec.Messages[1].Direction)
+	assert.Empty(t, spec.Messages[1].Fields)
+	assert.Equal(t, WsDirectionOut, spec.Messages[2].Direction)
+	assert.Equal(t, "[]int64", spec.Messages[2].Fields[0].Type)
+}
+
+func TestParseWsSpec_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "no messages",
+			content: `messages: []`,
+			wantErr: "no messages defined",
+		},
+		{
+			name: "empty name",
+			content: `
+messages:
+  - direction: in`,
+			wantErr: "message name is empty",
+		},
+		{
+			name: "duplicate message",
+			content: `
+messages:
+  - name: a
+  - name: a`,
+			wantErr: "duplicate message name: a",
+		},
+		{
+			name: "go name collision",
+			content: `
+messages:
+  - name: user_joined
+  - name: userJoined`,
+			wantErr: "message names 'user_joined' and 'userJoined' map to the same Go name UserJoined",
+		},
+		{
+			name: "invalid name",
+			content: `
+messages:
+  - name: chat.send`,
+			wantErr: "invalid message name: chat.send",
+		},
+		{
+			name: "reserved name",
+			content: `
+messages:
+  - name: error`,
+			wantErr: "message name 'error' is reserved",
+		},
+		{
+			name: "invalid direction",
+			content: `
+messages:
+  - name: a
+    direction: both`,
+			wantErr: "invalid direction 'both'",
+		},
+		{
+			name: "duplicate field",
+			content: `
+messages:
+  - name: a
+    fields:
+      - name: x
+        type: string
+      - name: x
+        type: int`,
+			wantErr: "duplicate field name: x",
+		},
+		{
+			name: "invalid field type",
+			content: `
+messages:
+  - name: a
+    fields:
+      - name: x
+        type: uuid`,
+			wantErr: "invalid type 'uuid'",
+		},
+		{
+			name: "only outgoing",
+			content: `
+messages:
+  - name: a
+    direction: out`,
+			wantErr: "no incoming messages defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseWsSpec(nil, writeWsSpec(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseWsSpec_FileNotFound(t *testing.T) {
+	_, err := ParseWsSpec(nil, "/nonexistent/messages.yaml")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read ws spec file")
+}
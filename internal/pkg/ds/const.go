@@ -60,6 +60,7 @@ type DocsConfig struct {
 	Type     DocsDeployType
 	SiteName string
 	Headers  []string // Custom HTTP headers for mc (minio type only)
+	Topology string   // Mermaid diagram of the applications for docs/topology.md, empty if disabled
 }
 
 // Artifact type constants
@@ -220,6 +221,7 @@ const (
 	KafkaTypeConsumer    = "consumer"
 	KafkaDriverCustom    = "custom"
 	KafkaObjNameProducer = "Producer"
+	KafkaObjNameConsumer = "Consumer"
 )
 
 // KafkaConfig represents Kafka producer/consumer configuration
@@ -272,12 +274,83 @@ func (k KafkaConfig) GetObjName() string {
 		return k.DriverObj
 	}
 
+	if k.Type == KafkaTypeConsumer {
+		return KafkaObjNameConsumer
+	}
+
 	return KafkaObjNameProducer
 }
 
+// IsConsumer returns true if config describes a kafka consumer
+func (k KafkaConfig) IsConsumer() bool {
+	return k.Type == KafkaTypeConsumer
+}
+
+// HasProducers returns true if any config describes a kafka producer.
+// Consumers are workers and are not registered as service drivers.
+func (k KafkaConfigs) HasProducers() bool {
+	for _, kafka := range k {
+		if kafka.Type == KafkaTypeProducer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Repository database constants
+const (
+	RepositoryTypePostgres  = "postgres"
+	RepositoryDriverPgx     = "pgx"
+	RepositoryObjNamePgx    = "Postgres"
+	repositoryPackageSuffix = "repo"
+)
+
+// Repository represents a generated data-access layer for one database schema
+//
+//nolint:decorder // follows existing pattern - types after consts
+type Repository struct {
+	Name     string // Unique name, used as package name and OC path component
+	TypeDB   string // postgres
+	DriverDB string // pgx
+}
+
+// Repositories is a map of Repository by name
+//
+//nolint:decorder // follows existing pattern
+type Repositories map[string]Repository
+
+// GetImport returns import path of the generated repository package
+func (r Repository) GetImport(modulePath string) string {
+	return modulePath + "/internal/pkg/repository/" + r.Name
+}
+
+// GetAlias returns import alias for the repository package.
+// The bare name is likely to clash with service-level identifiers (users, orders).
+func (r Repository) GetAlias() string {
+	return r.Name + repositoryPackageSuffix
+}
+
+// GetObjName returns struct name of the repository driver
+func (r Repository) GetObjName() string {
+	return RepositoryObjNamePgx
+}
+
+// HasPostgres returns true if any repository is backed by PostgreSQL
+func (r Repositories) HasPostgres() bool {
+	for _, repo := range r {
+		if repo.TypeDB == RepositoryTypePostgres {
+			return true
+		}
+	}
+
+	return false
+}
+
 const (
 	RestTransportType  TransportType = "rest"
 	GrpcTransportType  TransportType = "grpc"
+	WsTransportType    TransportType = "ws"
 	KafkaTransportType TransportType = "kafka"
 	CLITransportType   TransportType = "cli"
 
@@ -304,6 +377,15 @@ type Files struct {
 	OldDestName string
 	ParamsTmpl  any
 	Code        *bytes.Buffer
+	// Plugin is the name of the plugin that produced Content, such files are not rendered from a template
+	Plugin       string
+	Content      []byte
+	CommentStyle string // disclaimer comment style for Content, see templater.CommentAuto
+	// Pristine is the generated part as rendered when Code is merged with hand edits
+	Pristine []byte
+	// App and Transport are the application and transport the file is generated for, empty for shared files
+	App       string
+	Transport string
 }
 
 type DeployParams struct {
@@ -327,6 +409,7 @@ type App struct {
 	Drivers               Drivers
 	Workers               Workers
 	Kafka                 KafkaConfigs // Kafka producers/consumers for this app
+	Repositories          Repositories // Generated data-access layers used by this app
 	CLI                   *CLIApp      // CLI app config (exclusive with Transports/Workers)
 	Deploy                DeployParams
 	UseActiveRecord       bool
@@ -491,6 +574,17 @@ func (w Workers) Add(name string, worker Worker) error {
 	return nil
 }
 
+// HasScheduler returns true if the scheduler worker is generated
+func (w Workers) HasScheduler() bool {
+	for _, work := range w {
+		if work.SchedulerConfig != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (w Workers) GetUniqueTypes() map[string][]Worker {
 	uniqueTypes := make(map[string][]Worker)
 
@@ -562,6 +656,65 @@ type Transport struct {
 	BufLocalPlugins bool // Use local buf instead of docker for proto generation
 	Instantiation        string // "static" (default) or "dynamic" - only for ogen_client
 	Optional             bool   // true = optional dependency for this app
+	GrpcServices         []GrpcService // Services parsed from the proto file - only for buf_server
+	WsMessages           []WsMessage   // Messages parsed from the spec file - only for ws
+}
+
+// GrpcMethod represents a single rpc of a gRPC service
+type GrpcMethod struct {
+	Name            string
+	Request         string // Proto message name as written in the spec
+	Response        string // Proto message name as written in the spec
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+// GrpcService represents a gRPC service declared in a proto file
+type GrpcService struct {
+	Name    string
+	Methods []GrpcMethod
+}
+
+// WsField represents a single field of a WebSocket message payload
+type WsField struct {
+	Name   string // Original field name (snake_case), used as JSON key
+	GoName string // PascalCase field name
+	Type   string // Go type
+}
+
+// WsMessage represents a WebSocket message type from the spec file
+type WsMessage struct {
+	Name     string // Original message name, used as envelope type
+	GoName   string // PascalCase message name
+	Incoming bool   // true = client to server, false = server to client
+	Fields   []WsField
+}
+
+// GetWsIncoming returns messages sent by clients to the ws server
+func (t Transport) GetWsIncoming() []WsMessage {
+	return t.filterWsMessages(true)
+}
+
+// GetWsOutgoing returns messages sent by the ws server to clients
+func (t Transport) GetWsOutgoing() []WsMessage {
+	return t.filterWsMessages(false)
+}
+
+func (t Transport) filterWsMessages(incoming bool) []WsMessage {
+	messages := make([]WsMessage, 0, len(t.WsMessages))
+
+	for _, msg := range t.WsMessages {
+		if msg.Incoming == incoming {
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages
+}
+
+// IsGrpcServer returns true if transport is a generated gRPC server
+func (t Transport) IsGrpcServer() bool {
+	return t.Type == GrpcTransportType && t.GeneratorType == "buf_server"
 }
 
 // IsDynamic returns true if client should be created at runtime (not at startup)
@@ -604,13 +757,39 @@ func (q *QueueConfig) QueueIDs() []int {
 	return ids
 }
 
+// SchedulerJob represents a single periodic job of the scheduler worker
+type SchedulerJob struct {
+	Name      string // Original job name (snake_case)
+	GoName    string // PascalCase job name
+	Cron      string // Cron expression, empty for interval jobs
+	Interval  string // Fixed delay between runs (Go duration), empty for cron jobs
+	Timeout   string // Run timeout (Go duration), empty means no timeout
+	Singleton bool   // Skip a tick while the previous run is in progress
+}
+
+// SchedulerConfig holds the scheduler job list
+type SchedulerConfig struct {
+	Jobs []SchedulerJob
+}
+
+// JobNames returns the job names in declaration order
+func (s *SchedulerConfig) JobNames() []string {
+	names := make([]string, len(s.Jobs))
+	for i, job := range s.Jobs {
+		names[i] = job.Name
+	}
+
+	return names
+}
+
 type Worker struct {
 	Import            []string // Imports for main.go (worker initialization)
 	Name              string
 	GeneratorType     string
 	GeneratorTemplate string
 	GeneratorParams   map[string]string
-	QueueConfig       *QueueConfig // Non-nil for queue workers
+	QueueConfig       *QueueConfig     // Non-nil for queue workers
+	SchedulerConfig   *SchedulerConfig // Non-nil for the scheduler worker
 }
 
 type Apps []App
@@ -626,6 +805,17 @@ func (a Apps) HasActiveRecord() bool {
 	return false
 }
 
+// HasRepositories returns true if any application uses a generated repository
+func (a Apps) HasRepositories() bool {
+	for _, app := range a {
+		if len(app.Repositories) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsTransportOptional returns true if the transport is marked optional in at least one application
 func (a Apps) IsTransportOptional(name string) bool {
 	for _, app := range a {
@@ -787,6 +977,112 @@ func (a App) HasKafkaProducers() bool {
 	return false
 }
 
+// KafkaConsumerImports returns import paths for kafka consumers
+func (a App) KafkaConsumerImports(modulePath string) []string {
+	imports := make([]string, 0)
+
+	for _, kafka := range a.Kafka {
+		if kafka.Type == KafkaTypeConsumer {
+			imports = append(imports, kafka.GetImport(modulePath))
+		}
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		return strings.Compare(imports[i], imports[j]) < 0
+	})
+
+	return imports
+}
+
+// GetKafkaConsumers returns all kafka consumers for this app
+func (a App) GetKafkaConsumers() []KafkaConfig {
+	consumers := make([]KafkaConfig, 0)
+
+	for _, kafka := range a.Kafka {
+		if kafka.Type == KafkaTypeConsumer {
+			consumers = append(consumers, kafka)
+		}
+	}
+
+	sort.Slice(consumers, func(i, j int) bool {
+		return strings.Compare(consumers[i].Name, consumers[j].Name) < 0
+	})
+
+	return consumers
+}
+
+// HasKafkaConsumers returns true if app has any kafka consumers
+func (a App) HasKafkaConsumers() bool {
+	for _, kafka := range a.Kafka {
+		if kafka.Type == KafkaTypeConsumer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KafkaClient groups producers and consumers sharing one OnlineConf client node
+type KafkaClient struct {
+	Name      string
+	Events    []KafkaEvent  // Events of all producers and consumers, unique by name
+	Consumers []KafkaConfig // Consumers using this client
+}
+
+// GetKafkaClients returns kafka clients used by applications, sorted by name
+func (a Apps) GetKafkaClients() []KafkaClient {
+	clients := make(map[string]*KafkaClient)
+	seenKafka := make(map[string]struct{})
+	seenEvents := make(map[string]struct{})
+
+	for _, app := range a {
+		for _, kafka := range app.Kafka {
+			if _, ok := seenKafka[kafka.Name]; ok {
+				continue
+			}
+
+			seenKafka[kafka.Name] = struct{}{}
+
+			client, ok := clients[kafka.ClientName]
+			if !ok {
+				client = &KafkaClient{Name: kafka.ClientName}
+				clients[kafka.ClientName] = client
+			}
+
+			for _, event := range kafka.Events {
+				key := kafka.ClientName + "/" + event.Name
+				if _, ok := seenEvents[key]; !ok {
+					seenEvents[key] = struct{}{}
+					client.Events = append(client.Events, event)
+				}
+			}
+
+			if kafka.IsConsumer() {
+				client.Consumers = append(client.Consumers, kafka)
+			}
+		}
+	}
+
+	result := make([]KafkaClient, 0, len(clients))
+
+	for _, client := range clients {
+		sort.Slice(client.Events, func(i, j int) bool {
+			return strings.Compare(client.Events[i].Name, client.Events[j].Name) < 0
+		})
+		sort.Slice(client.Consumers, func(i, j int) bool {
+			return strings.Compare(client.Consumers[i].Name, client.Consumers[j].Name) < 0
+		})
+
+		result = append(result, *client)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Compare(result[i].Name, result[j].Name) < 0
+	})
+
+	return result
+}
+
 // GetTransportInfos returns transport info for Grafana dashboard generation.
 func (a App) GetTransportInfos() []grafana.TransportInfo {
 	infos := make([]grafana.TransportInfo, 0, len(a.Transports))
@@ -805,6 +1101,17 @@ func (a App) GetTransportInfos() []grafana.TransportInfo {
 	return infos
 }
 
+// GetSchedulerJobNames returns scheduler job names for Grafana dashboard generation.
+func (a App) GetSchedulerJobNames() []string {
+	for _, w := range a.Workers {
+		if w.SchedulerConfig != nil {
+			return w.SchedulerConfig.JobNames()
+		}
+	}
+
+	return nil
+}
+
 func (app App) getTransport(t TransportType) []Transport {
 	retTransports := []Transport{}
 
@@ -851,6 +1158,10 @@ func (a App) GetGrpcTransport() []Transport {
 	return a.getTransport(GrpcTransportType)
 }
 
+func (a App) GetWsTransport() []Transport {
+	return a.getTransport(WsTransportType)
+}
+
 func (a Apps) GetRestTransport() []Transport {
 	return a.getTransport(RestTransportType)
 }
@@ -863,6 +1174,23 @@ func (a Apps) GetGrpcTransport() []Transport {
 	return a.getTransport(GrpcTransportType)
 }
 
+func (a Apps) GetWsTransport() []Transport {
+	return a.getTransport(WsTransportType)
+}
+
+// GetGrpcServers returns buf_server transports of all applications
+func (a Apps) GetGrpcServers() []Transport {
+	servers := make([]Transport, 0)
+
+	for _, t := range a.GetGrpcTransport() {
+		if t.IsGrpcServer() {
+			servers = append(servers, t)
+		}
+	}
+
+	return servers
+}
+
 // func (a Apps) HasGrpcHandlers() bool {
 // 	return len(a.getHandlers("grpc")) > 0
 // }
@@ -930,8 +1258,11 @@ func (t Transport) GetOgenConfigPath(targetDir string) string {
 }
 
 func (t Transport) GetTargetSpecDir(targetDir string) string {
-	if t.Type == GrpcTransportType {
+	switch t.Type {
+	case GrpcTransportType:
 		return filepath.Join(targetDir, "api", "grpc", t.Name)
+	case WsTransportType:
+		return filepath.Join(targetDir, "api", "ws", t.Name)
 	}
 
 	return filepath.Join(targetDir, "api", "rest", t.Name, t.ApiVersion)
@@ -944,6 +1275,10 @@ func (t Transport) GetTargetSpecFile(num int) string {
 }
 
 func (t Transport) GetTargetGeneratePath(targetDir string) string {
+	if t.Type == GrpcTransportType {
+		return filepath.Join(targetDir, "pkg", "grpc", t.Name)
+	}
+
 	return filepath.Join(targetDir, "pkg", "rest", t.Name, t.ApiVersion)
 }
 
@@ -955,7 +1290,9 @@ type FilesDiff struct {
 	NewDirectory   map[string]struct{}
 	OtherDirectory map[string]struct{}
 	UserContent    map[string][]byte
+	UserRegions    map[string]map[string]string // Bodies of named user code regions (psg:begin/psg:end) by file and region id
 	RenameFiles    map[string]string
+	Orphans        map[string][]byte // Content that could not be kept in place, saved to a .orphan sidecar (adopt mode)
 }
 
 // GetTargetSpecDir returns the directory where schema files should be placed
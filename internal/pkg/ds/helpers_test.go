@@ -239,6 +239,142 @@ func TestApp_GetGrpcTransport(t *testing.T) {
 	}
 }
 
+func TestTransport_IsGrpcServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport Transport
+		want      bool
+	}{
+		{
+			name:      "buf_server",
+			transport: Transport{Type: GrpcTransportType, GeneratorType: "buf_server"},
+			want:      true,
+		},
+		{
+			name:      "buf_client",
+			transport: Transport{Type: GrpcTransportType, GeneratorType: "buf_client"},
+			want:      false,
+		},
+		{
+			name:      "rest ogen",
+			transport: Transport{Type: RestTransportType, GeneratorType: "ogen"},
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transport.IsGrpcServer(); got != tt.want {
+				t.Errorf("Transport.IsGrpcServer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApps_GetGrpcServers(t *testing.T) {
+	apps := Apps{
+		{
+			Transports: Transports{
+				"api":    Transport{Name: "api", Type: RestTransportType, GeneratorType: "ogen"},
+				"users":  Transport{Name: "users", Type: GrpcTransportType, GeneratorType: "buf_server"},
+				"orders": Transport{Name: "orders", Type: GrpcTransportType, GeneratorType: "buf_client"},
+			},
+		},
+		{
+			Transports: Transports{
+				"users": Transport{Name: "users", Type: GrpcTransportType, GeneratorType: "buf_server"},
+			},
+		},
+	}
+
+	got := apps.GetGrpcServers()
+
+	if len(got) != 1 || got[0].Name != "users" {
+		t.Errorf("Apps.GetGrpcServers() = %v, want only users", got)
+	}
+}
+
+func TestTransport_GetTargetGeneratePath(t *testing.T) {
+	rest := Transport{Name: "api", Type: RestTransportType, ApiVersion: "v1"}
+	if got := rest.GetTargetGeneratePath("/proj"); got != "/proj/pkg/rest/api/v1" {
+		t.Errorf("rest GetTargetGeneratePath() = %q", got)
+	}
+
+	grpc := Transport{Name: "users", Type: GrpcTransportType}
+	if got := grpc.GetTargetGeneratePath("mod"); got != "mod/pkg/grpc/users" {
+		t.Errorf("grpc GetTargetGeneratePath() = %q", got)
+	}
+}
+
+func TestTransport_GetTargetSpecDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport Transport
+		want      string
+	}{
+		{
+			name:      "rest",
+			transport: Transport{Name: "api", Type: RestTransportType, ApiVersion: "v1"},
+			want:      "/proj/api/rest/api/v1",
+		},
+		{
+			name:      "grpc",
+			transport: Transport{Name: "users", Type: GrpcTransportType},
+			want:      "/proj/api/grpc/users",
+		},
+		{
+			name:      "ws",
+			transport: Transport{Name: "chat", Type: WsTransportType},
+			want:      "/proj/api/ws/chat",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transport.GetTargetSpecDir("/proj"); got != tt.want {
+				t.Errorf("Transport.GetTargetSpecDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransport_GetWsMessages(t *testing.T) {
+	transport := Transport{
+		Name: "chat",
+		Type: WsTransportType,
+		WsMessages: []WsMessage{
+			{Name: "join_room", GoName: "JoinRoom", Incoming: true},
+			{Name: "message_posted", GoName: "MessagePosted"},
+			{Name: "send_message", GoName: "SendMessage", Incoming: true},
+		},
+	}
+
+	incoming := transport.GetWsIncoming()
+	if len(incoming) != 2 || incoming[0].Name != "join_room" || incoming[1].Name != "send_message" {
+		t.Errorf("Transport.GetWsIncoming() = %v, want join_room and send_message", incoming)
+	}
+
+	outgoing := transport.GetWsOutgoing()
+	if len(outgoing) != 1 || outgoing[0].Name != "message_posted" {
+		t.Errorf("Transport.GetWsOutgoing() = %v, want only message_posted", outgoing)
+	}
+}
+
+func TestApp_GetWsTransport(t *testing.T) {
+	app := App{
+		Transports: Transports{
+			"api":  Transport{Name: "api", Type: RestTransportType},
+			"chat": Transport{Name: "chat", Type: WsTransportType},
+		},
+	}
+
+	got := app.GetWsTransport()
+
+	if len(got) != 1 || got[0].Name != "chat" {
+		t.Errorf("App.GetWsTransport() = %v, want only chat", got)
+	}
+}
+
 func TestApp_HasOgenClients(t *testing.T) {
 	tests := []struct {
 		name string
@@ -143,6 +143,15 @@ func TestKafkaConfig_GetObjName(t *testing.T) {
 			},
 			want: "MyProducer",
 		},
+		{
+			name: "segmentio consumer",
+			kafka: KafkaConfig{
+				Name:   "events_consumer",
+				Type:   KafkaTypeConsumer,
+				Driver: "segmentio",
+			},
+			want: KafkaObjNameConsumer,
+		},
 	}
 
 	for _, tt := range tests {
@@ -263,6 +272,140 @@ func TestApp_KafkaImports(t *testing.T) {
 	}
 }
 
+func TestKafkaConfigs_HasProducers(t *testing.T) {
+	tests := []struct {
+		name  string
+		kafka KafkaConfigs
+		want  bool
+	}{
+		{
+			name:  "has producer",
+			kafka: KafkaConfigs{"producer1": {Type: KafkaTypeProducer}},
+			want:  true,
+		},
+		{
+			name:  "only consumer",
+			kafka: KafkaConfigs{"consumer1": {Type: KafkaTypeConsumer}},
+			want:  false,
+		},
+		{
+			name:  "empty",
+			kafka: nil,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.kafka.HasProducers(); got != tt.want {
+				t.Errorf("HasProducers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_GetKafkaConsumers(t *testing.T) {
+	app := App{
+		Kafka: KafkaConfigs{
+			"consumer2": {Name: "consumer2", Type: KafkaTypeConsumer},
+			"producer1": {Name: "producer1", Type: KafkaTypeProducer},
+			"consumer1": {Name: "consumer1", Type: KafkaTypeConsumer},
+		},
+	}
+
+	consumers := app.GetKafkaConsumers()
+
+	if len(consumers) != 2 {
+		t.Fatalf("GetKafkaConsumers() returned %d items, want 2", len(consumers))
+	}
+
+	if consumers[0].Name != "consumer1" || consumers[1].Name != "consumer2" {
+		t.Errorf("GetKafkaConsumers() = %v, %v, want consumer1, consumer2", consumers[0].Name, consumers[1].Name)
+	}
+
+	if !app.HasKafkaConsumers() {
+		t.Errorf("HasKafkaConsumers() = false, want true")
+	}
+
+	if (App{}).HasKafkaConsumers() {
+		t.Errorf("HasKafkaConsumers() on empty app = true, want false")
+	}
+}
+
+func TestApp_KafkaConsumerImports(t *testing.T) {
+	modulePath := "github.com/myorg/myservice"
+
+	app := App{
+		Kafka: KafkaConfigs{
+			"producer1": {Name: "producer1", Type: KafkaTypeProducer},
+			"consumer1": {Name: "consumer1", Type: KafkaTypeConsumer},
+		},
+	}
+
+	imports := app.KafkaConsumerImports(modulePath)
+
+	// Should only include consumer imports
+	if len(imports) != 1 {
+		t.Fatalf("KafkaConsumerImports() returned %d items, want 1", len(imports))
+	}
+
+	expected := modulePath + "/pkg/drivers/kafka/consumer1"
+	if imports[0] != expected {
+		t.Errorf("KafkaConsumerImports() = %v, want %v", imports[0], expected)
+	}
+}
+
+func TestApps_GetKafkaClients(t *testing.T) {
+	producer := KafkaConfig{
+		Name:       "events_producer",
+		Type:       KafkaTypeProducer,
+		ClientName: "main",
+		Events:     []KafkaEvent{{Name: "users"}},
+	}
+	consumer := KafkaConfig{
+		Name:       "events_consumer",
+		Type:       KafkaTypeConsumer,
+		ClientName: "main",
+		Group:      "group",
+		Events:     []KafkaEvent{{Name: "users"}, {Name: "orders"}},
+	}
+	audit := KafkaConfig{
+		Name:       "audit_producer",
+		Type:       KafkaTypeProducer,
+		ClientName: "audit",
+		Events:     []KafkaEvent{{Name: "audit"}},
+	}
+
+	apps := Apps{
+		{Name: "api", Kafka: KafkaConfigs{producer.Name: producer, audit.Name: audit}},
+		{Name: "worker", Kafka: KafkaConfigs{producer.Name: producer, consumer.Name: consumer}},
+	}
+
+	clients := apps.GetKafkaClients()
+
+	if len(clients) != 2 {
+		t.Fatalf("GetKafkaClients() returned %d clients, want 2", len(clients))
+	}
+
+	if clients[0].Name != "audit" || clients[1].Name != "main" {
+		t.Errorf("GetKafkaClients() names = %v, %v, want audit, main", clients[0].Name, clients[1].Name)
+	}
+
+	mainClient := clients[1]
+
+	if len(mainClient.Events) != 2 || mainClient.Events[0].Name != "orders" || mainClient.Events[1].Name != "users" {
+		t.Errorf("GetKafkaClients() main events = %v, want [orders users]", mainClient.Events)
+	}
+
+	if len(mainClient.Consumers) != 1 || mainClient.Consumers[0].Name != "events_consumer" {
+		t.Errorf("GetKafkaClients() main consumers = %v, want [events_consumer]", mainClient.Consumers)
+	}
+
+	if len(clients[0].Consumers) != 0 {
+		t.Errorf("GetKafkaClients() audit consumers = %v, want none", clients[0].Consumers)
+	}
+}
+
 func TestKafkaConstants(t *testing.T) {
 	// Verify constants are set correctly
 	if KafkaTypeProducer != "producer" {
@@ -280,4 +423,8 @@ func TestKafkaConstants(t *testing.T) {
 	if KafkaObjNameProducer != "Producer" {
 		t.Errorf("KafkaObjNameProducer = %q, want %q", KafkaObjNameProducer, "Producer")
 	}
+
+	if KafkaObjNameConsumer != "Consumer" {
+		t.Errorf("KafkaObjNameConsumer = %q, want %q", KafkaObjNameConsumer, "Consumer")
+	}
 }
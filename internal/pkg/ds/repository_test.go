@@ -0,0 +1,86 @@
+package ds
+
+import (
+	"testing"
+)
+
+func TestRepository_GetImport(t *testing.T) {
+	repo := Repository{Name: "users", TypeDB: RepositoryTypePostgres, DriverDB: RepositoryDriverPgx}
+
+	want := "github.com/myorg/myservice/internal/pkg/repository/users"
+	if got := repo.GetImport("github.com/myorg/myservice"); got != want {
+		t.Errorf("GetImport() = %q, want %q", got, want)
+	}
+}
+
+func TestRepository_GetAlias(t *testing.T) {
+	repo := Repository{Name: "users"}
+
+	if got := repo.GetAlias(); got != "usersrepo" {
+		t.Errorf("GetAlias() = %q, want %q", got, "usersrepo")
+	}
+}
+
+func TestRepositories_HasPostgres(t *testing.T) {
+	tests := []struct {
+		name  string
+		repos Repositories
+		want  bool
+	}{
+		{
+			name:  "no repositories",
+			repos: Repositories{},
+			want:  false,
+		},
+		{
+			name: "postgres repository",
+			repos: Repositories{
+				"users": {Name: "users", TypeDB: RepositoryTypePostgres, DriverDB: RepositoryDriverPgx},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.repos.HasPostgres(); got != tt.want {
+				t.Errorf("HasPostgres() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApps_HasRepositories(t *testing.T) {
+	tests := []struct {
+		name string
+		apps Apps
+		want bool
+	}{
+		{
+			name: "no apps",
+			apps: Apps{},
+			want: false,
+		},
+		{
+			name: "apps without repositories",
+			apps: Apps{{Name: "api"}, {Name: "worker", Repositories: Repositories{}}},
+			want: false,
+		},
+		{
+			name: "one app with repository",
+			apps: Apps{
+				{Name: "api"},
+				{Name: "admin", Repositories: Repositories{"users": {Name: "users"}}},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.apps.HasRepositories(); got != tt.want {
+				t.Errorf("HasRepositories() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package ds
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWorkers_HasScheduler(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers Workers
+		want    bool
+	}{
+		{
+			name:    "no workers",
+			workers: Workers{},
+			want:    false,
+		},
+		{
+			name: "daemon worker only",
+			workers: Workers{
+				"bg": {Name: "bg", GeneratorType: "template", GeneratorTemplate: "daemon"},
+			},
+			want: false,
+		},
+		{
+			name: "scheduler worker",
+			workers: Workers{
+				"scheduler": {
+					Name:            "scheduler",
+					SchedulerConfig: &SchedulerConfig{Jobs: []SchedulerJob{{Name: "sync"}}},
+				},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.workers.HasScheduler(); got != tt.want {
+				t.Errorf("HasScheduler() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_GetSchedulerJobNames(t *testing.T) {
+	app := App{
+		Workers: Workers{
+			"bg": {Name: "bg"},
+			"scheduler": {
+				Name: "scheduler",
+				SchedulerConfig: &SchedulerConfig{Jobs: []SchedulerJob{
+					{Name: "refresh_rates", GoName: "RefreshRates"},
+					{Name: "cleanup_sessions", GoName: "CleanupSessions"},
+				}},
+			},
+		},
+	}
+
+	// Declaration order is kept so dashboard rows follow the config
+	want := []string{"refresh_rates", "cleanup_sessions"}
+	if got := app.GetSchedulerJobNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSchedulerJobNames() = %v, want %v", got, want)
+	}
+
+	if got := (App{}).GetSchedulerJobNames(); got != nil {
+		t.Errorf("GetSchedulerJobNames() without scheduler = %v, want nil", got)
+	}
+}
@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"github.com/pkg/errors"
+)
+
+// Backup modes for --backup
+const (
+	BackupArchive = "archive"
+	BackupBranch  = "branch"
+)
+
+const (
+	backupBranchPrefix = "psg-backup/"
+	dirtyListLimit     = 10
+)
+
+var errDirtyTarget = errors.New("target has uncommitted changes")
+
+func (g *Generator) checkBackupMode() error {
+	switch g.Backup {
+	case "", BackupArchive, BackupBranch:
+		return nil
+	default:
+		return errors.Errorf("unknown backup mode %q (expected %s or %s)", g.Backup, BackupArchive, BackupBranch)
+	}
+}
+
+// checkGitClean refuses to regenerate over uncommitted changes: after generation they could not
+// be told apart from generated ones or restored with git. Changes in .project-config are allowed,
+// editing the config is how a regeneration starts; stage directories of a killed run are removed
+// by the regeneration.
+func (g *Generator) checkGitClean(targetPath string) error {
+	if g.AllowDirty {
+		return nil
+	}
+
+	changes, err := tools.GitUncommittedChanges(targetPath)
+	if err != nil {
+		return err
+	}
+
+	dirty := make([]string, 0, len(changes))
+
+	for _, path := range changes {
+		if !strings.HasPrefix(path, ".project-config/") && !strings.HasPrefix(path, stageDirPattern) {
+			dirty = append(dirty, path)
+		}
+	}
+
+	if len(dirty) == 0 {
+		return nil
+	}
+
+	list := dirty
+	if len(list) > dirtyListLimit {
+		list = append(list[:dirtyListLimit:dirtyListLimit], fmt.Sprintf("and %d more", len(dirty)-dirtyListLimit))
+	}
+
+	return fmt.Errorf("%w: %s; commit or stash them, or rerun with --allow-dirty", errDirtyTarget, strings.Join(list, ", "))
+}
+
+// backupTarget saves the current state of the target before anything is written
+func (g *Generator) backupTarget(targetPath string) error {
+	now := time.Now()
+
+	switch g.Backup {
+	case BackupArchive:
+		path, err := archiveTarget(targetPath, now)
+		if err != nil {
+			return errors.Wrap(err, "create backup archive")
+		}
+
+		g.log().Info("backup", "archive", path)
+	case BackupBranch:
+		branch, err := backupBranch(targetPath, now)
+		if err != nil {
+			return errors.Wrap(err, "create backup branch")
+		}
+
+		g.log().Info("backup", "branch", branch)
+	}
+
+	return nil
+}
+
+// archiveTarget writes the target tree without .git and previous backups to
+// .project-config/backup/<time>.tar.gz and returns the archive path
+func archiveTarget(targetPath string, now time.Time) (string, error) {
+	dir := filepath.Join(targetPath, projectBackupDir)
+	if err := os.MkdirAll(dir, tools.DefaultDirPerm); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, now.Format(backupTimeLayout)+".tar.gz")
+
+	tmp, err := os.CreateTemp(dir, ".archive-")
+	if err != nil {
+		return "", err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := writeTarGz(tmp, targetPath); err != nil {
+		tmp.Close()
+
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func writeTarGz(w io.Writer, root string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if rel == ".git" || rel == projectBackupDir || strings.HasPrefix(d.Name(), stageDirPattern) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = rel
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// backupBranch points a new branch at the current state of the work tree.
+// Uncommitted changes to tracked files are captured with git stash create, the work tree is not touched.
+func backupBranch(targetPath string, now time.Time) (string, error) {
+	rev, err := gitOutput(targetPath, "stash", "create", "psg backup")
+	if err != nil {
+		return "", err
+	}
+
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	branch := backupBranchPrefix + now.Format(backupTimeLayout)
+
+	if _, err := gitOutput(targetPath, "branch", branch, rev); err != nil {
+		return "", err
+	}
+
+	return branch, nil
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w (output: %s)", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func initTestRepo(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	rep, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrt, err := rep.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(root, name), content)
+
+		if _, err := wrt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = wrt.Commit("init", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerator_CheckGitClean(t *testing.T) {
+	repo := t.TempDir()
+	target := filepath.Join(repo, "service")
+
+	initTestRepo(t, repo, map[string]string{
+		"other/main.go":                        "package main\n",
+		"service/main.go":                      "package main\n",
+		"service/.project-config/project.yaml": "main:\n",
+	})
+
+	g := Generator{}
+
+	if err := g.checkGitClean(target); err != nil {
+		t.Fatalf("clean tree: checkGitClean() error = %v", err)
+	}
+
+	// Config edits, changes outside of the target and stage directories of a killed run are allowed
+	writeTestFile(t, filepath.Join(target, ".project-config", "project.yaml"), "main:\n  name: x\n")
+	writeTestFile(t, filepath.Join(repo, "other", "main.go"), "package other\n")
+	writeTestFile(t, filepath.Join(target, stageDirPattern+"999", "main.go"), "package main\n")
+
+	if err := g.checkGitClean(target); err != nil {
+		t.Fatalf("config edit: checkGitClean() error = %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(target, "main.go"), "package main\n\nfunc main() {}\n")
+	writeTestFile(t, filepath.Join(target, "untracked.go"), "package main\n")
+
+	err := g.checkGitClean(target)
+	if !errors.Is(err, errDirtyTarget) {
+		t.Fatalf("dirty tree: checkGitClean() error = %v, want %v", err, errDirtyTarget)
+	}
+
+	if want := "target has uncommitted changes: main.go, untracked.go; commit or stash them, or rerun with --allow-dirty"; err.Error() != want {
+		t.Errorf("checkGitClean() error = %q, want %q", err, want)
+	}
+
+	g.AllowDirty = true
+	if err := g.checkGitClean(target); err != nil {
+		t.Errorf("AllowDirty: checkGitClean() error = %v", err)
+	}
+
+	if err := (&Generator{}).checkGitClean(t.TempDir()); err != nil {
+		t.Errorf("not a git repository: checkGitClean() error = %v", err)
+	}
+}
+
+func TestGenerator_CheckBackupMode(t *testing.T) {
+	for _, mode := range []string{"", BackupArchive, BackupBranch} {
+		if err := (&Generator{Backup: mode}).checkBackupMode(); err != nil {
+			t.Errorf("checkBackupMode(%q) error = %v", mode, err)
+		}
+	}
+
+	if err := (&Generator{Backup: "zip"}).checkBackupMode(); err == nil {
+		t.Error("checkBackupMode(\"zip\") error = nil")
+	}
+}
+
+func TestArchiveTarget(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestFile(t, filepath.Join(root, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(root, "internal", "app.go"), "package internal\n")
+	writeTestFile(t, filepath.Join(root, ".project-config", "project.yaml"), "main:\n")
+	writeTestFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeTestFile(t, filepath.Join(root, ".project-config", "backup", "old.tar.gz"), "old")
+
+	path, err := archiveTarget(root, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("archiveTarget() error = %v", err)
+	}
+
+	if want := filepath.Join(root, ".project-config", "backup", "20250102-030405.tar.gz"); path != want {
+		t.Errorf("archiveTarget() path = %s, want %s", path, want)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(gz)
+	names := []string{}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names = append(names, hdr.Name)
+	}
+
+	sort.Strings(names)
+
+	want := []string{".project-config/", ".project-config/project.yaml", "internal/", "internal/app.go", "main.go"}
+	if len(names) != len(want) {
+		t.Fatalf("archive entries = %v, want %v", names, want)
+	}
+
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("archive entries = %v, want %v", names, want)
+
+			break
+		}
+	}
+}
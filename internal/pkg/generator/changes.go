@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Changes lists the files a generation changed, relative to the target
+type Changes struct {
+	Created []string     `json:"created"`
+	Updated []string     `json:"updated"`
+	Renamed []FileRename `json:"renamed"`
+	Removed []string     `json:"removed"`
+}
+
+// FileRename is a file moved to a new name, its content may change too
+type FileRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (r FileRename) String() string {
+	return r.From + " -> " + r.To
+}
+
+func newChanges(fileChanges []fileChange) Changes {
+	c := Changes{}
+
+	for _, fc := range fileChanges {
+		switch {
+		case fc.from == "":
+			c.Created = append(c.Created, fc.to)
+		case fc.to == "":
+			c.Removed = append(c.Removed, fc.from)
+		case fc.from != fc.to:
+			c.Renamed = append(c.Renamed, FileRename{From: fc.from, To: fc.to})
+		default:
+			c.Updated = append(c.Updated, fc.to)
+		}
+	}
+
+	return c
+}
+
+// Empty reports whether the generation left the target as it was
+func (c Changes) Empty() bool {
+	return len(c.Created)+len(c.Updated)+len(c.Renamed)+len(c.Removed) == 0
+}
+
+// String is a one-line summary: "1 created, 2 updated, 1 removed"
+func (c Changes) String() string {
+	if c.Empty() {
+		return "no changes"
+	}
+
+	parts := []string{}
+
+	for _, group := range []struct {
+		count int
+		verb  string
+	}{
+		{len(c.Created), "created"},
+		{len(c.Updated), "updated"},
+		{len(c.Renamed), "renamed"},
+		{len(c.Removed), "removed"},
+	} {
+		if group.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", group.count, group.verb))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"testing"
+)
+
+func TestNewChanges(t *testing.T) {
+	changes := newChanges([]fileChange{
+		{to: "new.go"},
+		{from: "edited.go", to: "edited.go"},
+		{from: "old_name.go", to: "new_name.go"},
+		{from: "stale.go"},
+	})
+
+	if len(changes.Created) != 1 || changes.Created[0] != "new.go" {
+		t.Errorf("Created = %v", changes.Created)
+	}
+
+	if len(changes.Updated) != 1 || changes.Updated[0] != "edited.go" {
+		t.Errorf("Updated = %v", changes.Updated)
+	}
+
+	if len(changes.Renamed) != 1 || changes.Renamed[0].String() != "old_name.go -> new_name.go" {
+		t.Errorf("Renamed = %v", changes.Renamed)
+	}
+
+	if len(changes.Removed) != 1 || changes.Removed[0] != "stale.go" {
+		t.Errorf("Removed = %v", changes.Removed)
+	}
+}
+
+func TestChanges_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes Changes
+		want    string
+	}{
+		{name: "empty", changes: Changes{}, want: "no changes"},
+		{name: "some", changes: Changes{Created: []string{"a"}, Removed: []string{"b", "c"}}, want: "1 created, 2 removed"},
+		{
+			name:    "all",
+			changes: Changes{Created: []string{"a"}, Updated: []string{"b"}, Renamed: []FileRename{{From: "c", To: "d"}}, Removed: []string{"e"}},
+			want:    "1 created, 1 updated, 1 renamed, 1 removed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.changes.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+	"github.com/pkg/errors"
+)
+
+const (
+	projectBackupDir = ".project-config/backup"
+	backupTimeLayout = "20060102-150405"
+)
+
+var errHandEdited = errors.New("generated part of files was edited by hand")
+
+// findHandEdited returns files whose generated part no longer matches the checksum recorded
+// in meta.yaml by the previous generation. Files without a recorded checksum are not checked.
+func (g *Generator) findHandEdited(targetPath string, files []ds.Files, filesDiff ds.FilesDiff) ([]string, error) {
+	if len(g.Meta.Checksums) == 0 {
+		return nil, nil
+	}
+
+	paths := make([]string, 0, len(files)+len(filesDiff.ObsoleteFiles))
+
+	for _, file := range files {
+		if _, ex := filesDiff.IgnoreFiles[file.DestName]; ex {
+			continue
+		}
+
+		// The file is still under its old name if it is going to be renamed
+		path := file.DestName
+		if _, ex := filesDiff.RenameFiles[file.OldDestName]; ex {
+			if _, err := os.Stat(file.OldDestName); err == nil {
+				path = file.OldDestName
+			}
+		}
+
+		// Adopted files are kept whole in their .orphan sidecar
+		if _, ex := filesDiff.Orphans[path]; ex {
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	// Obsolete files are deleted, edits in them would be lost too
+	for obsoleteFile := range filesDiff.ObsoleteFiles {
+		paths = append(paths, obsoleteFile)
+	}
+
+	edited := []string{}
+
+	for _, path := range paths {
+		checksum, ok := g.Meta.Checksums[filepath.ToSlash(relPath(targetPath, path))]
+		if !ok {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %s", path)
+		}
+
+		if templater.GeneratedChecksum(path, content) != checksum {
+			edited = append(edited, path)
+		}
+	}
+
+	sort.Strings(edited)
+
+	return edited, nil
+}
+
+// handEditedError lists hand-edited files relative to the target
+func handEditedError(targetPath string, edited []string) error {
+	return fmt.Errorf("%w: %s; move the changes below the disclaimer or rerun with --force", errHandEdited, strings.Join(relPaths(targetPath, edited), ", "))
+}
+
+// backupHandEdited copies hand-edited files to .project-config/backup/<time>/ before they are overwritten
+func backupHandEdited(tx *fsTransaction, targetPath string, edited []string) error {
+	if len(edited) == 0 {
+		return nil
+	}
+
+	backupDir := filepath.Join(targetPath, projectBackupDir, time.Now().Format(backupTimeLayout))
+
+	for _, path := range edited {
+		dst := filepath.Join(backupDir, relPath(targetPath, path))
+
+		if err := tx.CopyFile(path, dst); err != nil {
+			return errors.Wrapf(err, "backup %s", path)
+		}
+
+		tx.log.Info("backup hand-edited file", "file", path, "backup", dst)
+	}
+
+	return nil
+}
+
+// generatedChecksums returns checksums of the generated part of every written file as rendered, keyed for meta.yaml.
+// A merged file keeps the checksum of the rendering, so its hand edits are merged again next time.
+func generatedChecksums(targetPath string, files []ds.Files, filesDiff ds.FilesDiff) map[string]string {
+	checksums := make(map[string]string, len(files))
+
+	for _, file := range files {
+		if _, ex := filesDiff.IgnoreFiles[file.DestName]; ex {
+			continue
+		}
+
+		checksums[filepath.ToSlash(relPath(targetPath, file.DestName))] = templater.GeneratedChecksum(file.DestName, pristinePart(file))
+	}
+
+	return checksums
+}
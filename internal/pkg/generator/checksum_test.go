@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/meta"
+)
+
+const testDisclaimer = "// If you need you can add your code after this message\n"
+
+func TestGenerator_FindHandEdited(t *testing.T) {
+	root := t.TempDir()
+
+	clean := filepath.Join(root, "psg_clean_gen.go")
+	userCode := filepath.Join(root, "psg_user_gen.go")
+	edited := filepath.Join(root, "internal", "psg_edited_gen.go")
+	unknown := filepath.Join(root, "psg_unknown_gen.go")
+	obsolete := filepath.Join(root, "psg_old_gen.go")
+
+	generated := "package main\n" + testDisclaimer
+
+	files := []ds.Files{
+		{DestName: clean, OldDestName: clean, Code: bytes.NewBufferString(generated)},
+		{DestName: userCode, OldDestName: userCode, Code: bytes.NewBufferString(generated)},
+		{DestName: edited, OldDestName: edited, Code: bytes.NewBufferString(generated)},
+		{DestName: unknown, OldDestName: unknown, Code: bytes.NewBufferString(generated)},
+	}
+
+	filesDiff := emptyFilesDiff()
+	filesDiff.ObsoleteFiles[obsolete] = struct{}{}
+
+	// Checksums as the previous generation recorded them
+	g := Generator{Meta: meta.Meta{
+		Checksums: generatedChecksums(root, append(files, ds.Files{DestName: obsolete, Code: bytes.NewBufferString(generated)}), filesDiff),
+	}}
+	delete(g.Meta.Checksums, "psg_unknown_gen.go")
+
+	if got := g.Meta.Checksums["internal/psg_edited_gen.go"]; got == "" {
+		t.Fatalf("generatedChecksums() has no entry for internal/psg_edited_gen.go: %v", g.Meta.Checksums)
+	}
+
+	writeTestFile(t, clean, generated)
+	writeTestFile(t, userCode, generated+"\nfunc userFunc() {}\n")
+	writeTestFile(t, edited, "package main\n\nvar hack = 1\n"+testDisclaimer)
+	writeTestFile(t, unknown, "package main\n\nvar hack = 1\n"+testDisclaimer)
+	writeTestFile(t, obsolete, "package main\n// edited\n"+testDisclaimer)
+
+	got, err := g.findHandEdited(root, files, filesDiff)
+	if err != nil {
+		t.Fatalf("findHandEdited() error = %v", err)
+	}
+
+	want := []string{edited, obsolete}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("findHandEdited() = %v, want %v", got, want)
+	}
+
+	err = handEditedError(root, got)
+	if !errors.Is(err, errHandEdited) || !strings.Contains(err.Error(), "internal/psg_edited_gen.go, psg_old_gen.go") {
+		t.Errorf("handEditedError() = %v", err)
+	}
+}
+
+func TestBackupHandEdited(t *testing.T) {
+	root := t.TempDir()
+	edited := filepath.Join(root, "internal", "psg_edited_gen.go")
+
+	writeTestFile(t, edited, "package internal\n")
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if err := backupHandEdited(tx, root, []string{edited}); err != nil {
+		t.Fatalf("backupHandEdited() error = %v", err)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(root, projectBackupDir, "*", "internal", "psg_edited_gen.go"))
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("backup not found: %v %v", backups, err)
+	}
+
+	if got := readTestFile(t, backups[0]); got != "package internal\n" {
+		t.Errorf("backup content = %q", got)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, ".project-config")); !os.IsNotExist(err) {
+		t.Errorf("backup directory kept after Rollback")
+	}
+}
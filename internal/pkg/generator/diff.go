@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	diffContextLines = 3
+	diffNullFile     = "/dev/null"
+)
+
+// fileChange is a single file regeneration would change.
+// An empty from means the file is created, an empty to means it is removed.
+type fileChange struct {
+	from, to string
+	old, new []byte
+}
+
+// path is used to order changes in the output
+func (c fileChange) path() string {
+	if c.to != "" {
+		return c.to
+	}
+
+	return c.from
+}
+
+// writeFilesDiff writes a unified diff between the target tree and the rendered files.
+// Paths in the output are relative to targetPath with a/ and b/ prefixes, as git prints them.
+func writeFilesDiff(w io.Writer, targetPath string, files []ds.Files, filesDiff ds.FilesDiff) (bool, error) {
+	changes, err := collectFileChanges(targetPath, files, filesDiff)
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range changes {
+		fromFile, toFile := diffNullFile, diffNullFile
+
+		if c.from != "" {
+			fromFile = "a/" + filepath.ToSlash(c.from)
+		}
+
+		if c.to != "" {
+			toFile = "b/" + filepath.ToSlash(c.to)
+		}
+
+		// A rename without content changes has no hunks, so it is reported as git does
+		if c.from != "" && c.to != "" && c.from != c.to {
+			if _, err := fmt.Fprintf(w, "rename from %s\nrename to %s\n", filepath.ToSlash(c.from), filepath.ToSlash(c.to)); err != nil {
+				return false, errors.Wrapf(err, "write diff for %s", c.path())
+			}
+		}
+
+		err := difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+			A:        splitDiffLines(c.old),
+			B:        splitDiffLines(c.new),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  diffContextLines,
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "write diff for %s", c.path())
+		}
+	}
+
+	return len(changes) > 0, nil
+}
+
+func collectFileChanges(targetPath string, files []ds.Files, filesDiff ds.FilesDiff) ([]fileChange, error) {
+	renamedFrom := make(map[string]string, len(filesDiff.RenameFiles))
+	for oldFile, newFile := range filesDiff.RenameFiles {
+		renamedFrom[newFile] = oldFile
+	}
+
+	changes := []fileChange{}
+
+	for _, file := range files {
+		if _, ex := filesDiff.IgnoreFiles[file.DestName]; ex {
+			continue
+		}
+
+		src := file.DestName
+		if oldFile, ok := renamedFrom[file.DestName]; ok {
+			if _, err := os.Stat(oldFile); err == nil {
+				src = oldFile
+			}
+		}
+
+		old, err := os.ReadFile(src)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "read %s", src)
+		}
+
+		from := ""
+		if err == nil {
+			if src == file.DestName && bytes.Equal(old, file.Code.Bytes()) {
+				continue
+			}
+
+			from = relPath(targetPath, src)
+		}
+
+		changes = append(changes, fileChange{from: from, to: relPath(targetPath, file.DestName), old: old, new: file.Code.Bytes()})
+	}
+
+	for obsoleteFile := range filesDiff.ObsoleteFiles {
+		old, err := os.ReadFile(obsoleteFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %s", obsoleteFile)
+		}
+
+		changes = append(changes, fileChange{from: relPath(targetPath, obsoleteFile), old: old})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].path() < changes[j].path()
+	})
+
+	return changes, nil
+}
+
+// relPath returns path relative to the target directory.
+// Paths found by walking the target may be relative to the working directory.
+func relPath(targetPath, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	if rel, err := filepath.Rel(targetPath, abs); err == nil {
+		return rel
+	}
+
+	return path
+}
+
+// splitDiffLines splits content into lines keeping line endings.
+// Unlike difflib.SplitLines it does not add an empty line after the final newline.
+func splitDiffLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+
+	return lines
+}
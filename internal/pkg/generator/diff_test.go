@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+)
+
+func emptyFilesDiff() ds.FilesDiff {
+	return ds.FilesDiff{
+		IgnoreFiles:   make(map[string]struct{}),
+		ObsoleteFiles: make(map[string]struct{}),
+		RenameFiles:   make(map[string]string),
+	}
+}
+
+func TestWriteFilesDiff(t *testing.T) {
+	root := t.TempDir()
+
+	unchanged := filepath.Join(root, "unchanged.go")
+	modified := filepath.Join(root, "internal", "modified.go")
+	created := filepath.Join(root, "created.go")
+	ignored := filepath.Join(root, "go.mod")
+	obsolete := filepath.Join(root, "psg_old_gen.go")
+	renamedFrom := filepath.Join(root, "old_name.go")
+	renamedTo := filepath.Join(root, "new_name.go")
+
+	writeTestFile(t, unchanged, "package main\n")
+	writeTestFile(t, modified, "package internal\n\nvar a = 1\n")
+	writeTestFile(t, ignored, "module example\n")
+	writeTestFile(t, obsolete, "package main\n")
+	writeTestFile(t, renamedFrom, "package main\n// renamed\n")
+
+	files := []ds.Files{
+		{DestName: unchanged, Code: bytes.NewBufferString("package main\n")},
+		{DestName: modified, Code: bytes.NewBufferString("package internal\n\nvar a = 2\n")},
+		{DestName: created, Code: bytes.NewBufferString("package main\n")},
+		{DestName: ignored, Code: bytes.NewBufferString("module other\n")},
+		{DestName: renamedTo, Code: bytes.NewBufferString("package main\n// renamed\n")},
+	}
+
+	filesDiff := emptyFilesDiff()
+	filesDiff.IgnoreFiles[ignored] = struct{}{}
+	filesDiff.ObsoleteFiles[obsolete] = struct{}{}
+	filesDiff.RenameFiles[renamedFrom] = renamedTo
+
+	var out bytes.Buffer
+
+	changed, err := writeFilesDiff(&out, root, files, filesDiff)
+	if err != nil {
+		t.Fatalf("writeFilesDiff() error = %v", err)
+	}
+
+	if !changed {
+		t.Error("writeFilesDiff() changed = false, want true")
+	}
+
+	got := out.String()
+
+	for _, want := range []string{
+		"--- /dev/null\n+++ b/created.go\n@@ -0,0 +1 @@\n+package main\n",
+		"--- a/internal/modified.go\n+++ b/internal/modified.go\n",
+		"-var a = 1\n+var a = 2\n",
+		"rename from old_name.go\nrename to new_name.go\n",
+		"--- a/psg_old_gen.go\n+++ /dev/null\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diff does not contain %q:\n%s", want, got)
+		}
+	}
+
+	for _, notWant := range []string{"unchanged.go", "go.mod"} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("diff mentions %s:\n%s", notWant, got)
+		}
+	}
+
+	// Files are ordered by path
+	if strings.Index(got, "created.go") > strings.Index(got, "internal/modified.go") {
+		t.Errorf("diff is not sorted by path:\n%s", got)
+	}
+}
+
+func TestWriteFilesDiff_NoChanges(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+
+	writeTestFile(t, path, "package main\n")
+
+	var out bytes.Buffer
+
+	changed, err := writeFilesDiff(&out, root, []ds.Files{
+		{DestName: path, Code: bytes.NewBufferString("package main\n")},
+	}, emptyFilesDiff())
+	if err != nil {
+		t.Fatalf("writeFilesDiff() error = %v", err)
+	}
+
+	if changed || out.Len() != 0 {
+		t.Errorf("writeFilesDiff() = %v, %q; want no changes", changed, out.String())
+	}
+}
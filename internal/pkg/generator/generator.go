@@ -2,8 +2,9 @@ package generator
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"github.com/Educentr/go-project-starter/internal/pkg/meta"
 	"github.com/Educentr/go-project-starter/internal/pkg/templater"
 	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"github.com/Educentr/go-project-starter/internal/pkg/topology"
 	"github.com/pkg/errors"
 	"golang.org/x/mod/semver"
 )
@@ -24,6 +26,10 @@ import (
 type Generator struct {
 	AppInfo             string
 	DryRun              bool
+	Force               bool   // overwrite hand-edited generated files after backing them up
+	AllowDirty          bool   // regenerate over uncommitted changes in the target
+	Adopt               bool   // move content that blocks regeneration to .orphan files instead of failing
+	Backup              string // back up the target before writing: BackupArchive or BackupBranch
 	Meta                meta.Meta
 	Logger              ds.Logger
 	ProjectName         string
@@ -48,15 +54,28 @@ type Generator struct {
 	GoatVersion         string
 	GoatServicesVersion string
 	TargetDir           string
-	ConfigPath          string // Source config file path for copying to target
+	ConfigPath          string               // Source config file path for copying to target
+	ConfigIncludes      []string             // Files included by the config, copied to target with it
+	ConfigDeprecations  []cfg.Deprecations   // Deprecated features used by the config, reported as warnings
+	TemplatesDir        string               // Templates overlay directory, empty for embedded templates only
+	templates           *templater.Templates // set by collectFiles from TemplatesDir
+	FS                  fs.FS                // file system of the config, specs and TemplatesDir, nil for the OS one
+	SourcePaths         []string             // config and spec files the project is generated from
+	Only                []ScopeFilter        // regenerate only the files selected by any of the filters, see selectScope
+	scope               *scope               // set by render when Only is not empty
+	Report              Report               // what the last Generate did or, in dry run, would do
+	Output              io.Writer            // dry run listing, os.Stdout if nil
+	Log                 *slog.Logger         // messages of the generation, slog.Default() if nil
 	DockerImagePrefix   string
 	SkipInitService     bool
 	PostGenerate        []ExecCmd
+	Plugins             []Plugin
 	Transports          ds.Transports
 	Workers             ds.Workers
 	Drivers             ds.Drivers
 	JSONSchemas         ds.JSONSchemas
 	Kafka               ds.KafkaConfigs
+	Repositories        ds.Repositories
 	Applications        ds.Apps
 	Grafana             grafana.Config
 	Artifacts           ds.ArtifactsConfig
@@ -64,9 +83,10 @@ type Generator struct {
 }
 
 type ExecCmd struct {
-	Cmd string
-	Arg []string
-	Msg string
+	Cmd  string
+	Arg  []string
+	Msg  string
+	Step string // post_generate entry of the config the command runs for
 }
 
 var errUnknownTransport = errors.New("unknown transport")
@@ -80,6 +100,7 @@ func New(AppInfo string, config cfg.Config, genMeta meta.Meta, dryrun bool) (*Ge
 		Drivers:      make(ds.Drivers),
 		JSONSchemas:  make(ds.JSONSchemas),
 		Kafka:        make(ds.KafkaConfigs),
+		Repositories: make(ds.Repositories),
 		DryRun:       dryrun,
 		Meta:         genMeta,
 	}
@@ -91,6 +112,49 @@ func New(AppInfo string, config cfg.Config, genMeta meta.Meta, dryrun bool) (*Ge
 	return &g, nil
 }
 
+// log returns the logger of the generation
+func (g *Generator) log() *slog.Logger {
+	if g.Log == nil {
+		return slog.Default()
+	}
+
+	return g.Log
+}
+
+// Topology returns the graph of the applications: transports, clients, kafka topics, drivers and workers
+func (g *Generator) Topology() *topology.Graph {
+	return topology.Build(g.Applications, g.Transports)
+}
+
+// SelectPostGenerate keeps only the post_generate steps named in steps, in the config order.
+// A step the config does not have is an error.
+func (g *Generator) SelectPostGenerate(steps []string) error {
+	selected := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		selected[step] = false
+	}
+
+	cmds := make([]ExecCmd, 0, len(g.PostGenerate))
+
+	for _, cmd := range g.PostGenerate {
+		if _, ok := selected[cmd.Step]; ok {
+			selected[cmd.Step] = true
+
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	for _, step := range steps {
+		if !selected[step] {
+			return errors.Errorf("post_generate step %q is not configured", step)
+		}
+	}
+
+	g.PostGenerate = cmds
+
+	return nil
+}
+
 func (g *Generator) processConfig(config cfg.Config) error {
 	g.Logger = config.Main.LoggerObj
 	g.ProjectName = config.Main.Name
@@ -129,7 +193,32 @@ func (g *Generator) processConfig(config cfg.Config) error {
 	}
 
 	g.TargetDir = "./"
+	g.FS = config.FS
 	g.ConfigPath = config.ConfigFilePath
+	g.ConfigIncludes = config.IncludePaths
+	g.ConfigDeprecations = config.Deprecations
+	g.SourcePaths = config.SourcePaths()
+
+	if config.Main.TemplatesDir != "" {
+		g.TemplatesDir = config.Main.TemplatesDir
+		if !filepath.IsAbs(g.TemplatesDir) {
+			g.TemplatesDir = filepath.Join(config.BasePath, g.TemplatesDir)
+		}
+	}
+
+	for _, plugin := range config.PluginList {
+		// A plugin is a command run in the config directory
+		if config.FS != nil {
+			return errors.Errorf("plugin %s: plugins need the config on disk", plugin.Name)
+		}
+
+		p, err := newPlugin(plugin, config.BasePath)
+		if err != nil {
+			return err
+		}
+
+		g.Plugins = append(g.Plugins, p)
+	}
 
 	if config.Deploy.LogCollector.Type != "" {
 		g.Deploy.LogCollector.Type = config.Deploy.LogCollector.Type
@@ -216,7 +305,7 @@ func (g *Generator) processConfig(config cfg.Config) error {
 		if w.GeneratorTemplate == "queue" && len(w.Path) > 0 && w.Path[0] != "" {
 			specPath := filepath.Join(config.BasePath, w.Path[0])
 
-			spec, err := cfg.ParseQueueSpec(specPath)
+			spec, err := cfg.ParseQueueSpec(config.FS, specPath)
 			if err != nil {
 				return errors.Wrapf(err, "failed to parse queue spec for worker '%s'", w.Name)
 			}
@@ -229,6 +318,21 @@ func (g *Generator) processConfig(config cfg.Config) error {
 		}
 	}
 
+	if config.Scheduler.Enabled {
+		worker := ds.Worker{
+			Import: []string{fmt.Sprintf(`%sWorker "%s/internal/app/worker/%s"`,
+				cfg.SchedulerWorkerName, g.ProjectPath, cfg.SchedulerWorkerName)},
+			Name:              cfg.SchedulerWorkerName,
+			GeneratorType:     "template",
+			GeneratorTemplate: cfg.GeneratorTemplateScheduler,
+			SchedulerConfig:   convertSchedulerJobs(config.Scheduler.Jobs),
+		}
+
+		if err := g.Workers.Add(cfg.SchedulerWorkerName, worker); err != nil {
+			return err
+		}
+	}
+
 	for _, driver := range config.DriverList {
 		if _, ex := g.Drivers[driver.Name]; ex {
 			return fmt.Errorf("duplicate driver name: %s", driver.Name)
@@ -271,11 +375,56 @@ func (g *Generator) processConfig(config cfg.Config) error {
 			}
 		}
 
+		if grpc.GeneratorType == "buf_server" {
+			spec, err := cfg.ParseProtoSpec(config.FS, paths[0])
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse proto spec for grpc '%s'", grpc.Name)
+			}
+
+			transport.Import = []string{
+				fmt.Sprintf(`%s "%s/internal/app/transport/grpc/%s"`, grpc.Name, g.ProjectPath, grpc.Name),
+			}
+			transport.Init = fmt.Sprintf(`%s.NewServer()`, grpc.Name)
+			transport.GrpcServices = convertProtoSpec(spec)
+		}
+
 		if err := g.Transports.Add(grpc.Name, transport); err != nil {
 			return err
 		}
 	}
 
+	for _, ws := range config.WsList {
+		if ws.Name == "" {
+			return errors.New("ws name is empty")
+		}
+
+		paths := []string{filepath.Join(config.BasePath, ws.Path)}
+
+		spec, err := cfg.ParseWsSpec(config.FS, paths[0])
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse ws spec for '%s'", ws.Name)
+		}
+
+		transport := ds.Transport{
+			Name:              ws.Name,
+			PkgName:           ws.Name,
+			Type:              ds.WsTransportType,
+			GeneratorType:     "template",
+			GeneratorTemplate: "ws",
+			Port:              strconv.FormatUint(uint64(ws.Port), 10),
+			SpecPath:          paths,
+			Import: []string{
+				fmt.Sprintf(`%s "%s/internal/app/transport/ws/%s"`, ws.Name, g.ProjectPath, ws.Name),
+			},
+			Init:       fmt.Sprintf(`%s.NewServer()`, ws.Name),
+			WsMessages: convertWsSpec(spec),
+		}
+
+		if err := g.Transports.Add(ws.Name, transport); err != nil {
+			return err
+		}
+	}
+
 	// Process Grafana datasources
 	for _, cfgDs := range config.Grafana.Datasources {
 		g.Grafana.Datasources = append(g.Grafana.Datasources, grafana.Datasource{
@@ -384,6 +533,14 @@ func (g *Generator) processConfig(config cfg.Config) error {
 		g.Kafka[kafka.Name] = kafkaConfig
 	}
 
+	for _, repo := range config.RepositoryList {
+		g.Repositories[repo.Name] = ds.Repository{
+			Name:     repo.Name,
+			TypeDB:   string(repo.TypeDB),
+			DriverDB: string(repo.DriverDB),
+		}
+	}
+
 	// Process artifacts configuration BEFORE applications loop
 	// Default to docker only if not specified at root level
 	if len(config.Artifacts) == 0 {
@@ -485,6 +642,7 @@ func (g *Generator) processConfig(config cfg.Config) error {
 			Workers:               make(ds.Workers),
 			Drivers:               make(ds.Drivers),
 			Kafka:                 make(ds.KafkaConfigs),
+			Repositories:          make(ds.Repositories),
 			UseActiveRecord:       useActiveRecord,
 			DependsOnDockerImages: app.DependsOnDockerImages,
 			UseEnvs:               useEnvs,
@@ -578,7 +736,7 @@ func (g *Generator) processConfig(config cfg.Config) error {
 			if len(cli.Path) > 0 && cli.Path[0] != "" {
 				specPath := filepath.Join(config.BasePath, cli.Path[0])
 
-				spec, err := cfg.ParseCLISpec(specPath)
+				spec, err := cfg.ParseCLISpec(config.FS, specPath)
 				if err != nil {
 					return errors.Wrapf(err, "failed to parse CLI spec for '%s'", cli.Name)
 				}
@@ -600,6 +758,15 @@ func (g *Generator) processConfig(config cfg.Config) error {
 			application.Kafka[appKafka.Name] = kafka
 		}
 
+		for _, repoName := range app.RepositoryList {
+			repo, ex := g.Repositories[repoName]
+			if !ex {
+				return errors.Errorf("unknown repository: %s in application: %s", repoName, app.Name)
+			}
+
+			application.Repositories[repoName] = repo
+		}
+
 		g.Applications = append(g.Applications, application)
 	}
 
@@ -619,7 +786,14 @@ func (g *Generator) processConfig(config cfg.Config) error {
 		g.Artifacts.Types = append(g.Artifacts.Types, a)
 	}
 
+	// The topology page of the documentation site is drawn from the resolved applications
+	if config.Documentation.Topology && g.Documentation.IsEnabled() {
+		g.Documentation.Topology = g.Topology().Mermaid()
+	}
+
 	for _, postGenerate := range config.PostGenerate {
+		first := len(g.PostGenerate)
+
 		switch postGenerate {
 		case "git_install":
 			g.PostGenerate = append(g.PostGenerate, ExecCmd{Cmd: "make", Arg: []string{"git-init"}, Msg: "initialize git"})
@@ -644,6 +818,10 @@ func (g *Generator) processConfig(config cfg.Config) error {
 			cmd := strings.Split(postGenerate, " ")
 			g.PostGenerate = append(g.PostGenerate, ExecCmd{Cmd: cmd[0], Arg: cmd[1:], Msg: "custom command"})
 		}
+
+		for i := first; i < len(g.PostGenerate); i++ {
+			g.PostGenerate[i].Step = postGenerate
+		}
 	}
 
 	// for _, e := range config.Applications {
@@ -668,16 +846,6 @@ func (g *Generator) processConfig(config cfg.Config) error {
 	// 	}
 	// }
 
-	// for i, e := range g.config.WsList {
-	// 	if !e.IsValid() {
-	// 		log.Fatalln("invalid ws config with", i, "index")
-	// 	}
-
-	// 	if err := g.AddWSApi(e.Path, e.Name); err != nil {
-	// 		return err
-	// 	}
-	// }
-
 	// for i, e := range g.config.ConsumerList {
 	// 	if !e.IsValid() {
 	// 		log.Fatalln("invalid consumer config with", i, "index")
@@ -688,16 +856,6 @@ func (g *Generator) processConfig(config cfg.Config) error {
 	// 	}
 	// }
 
-	// for i, e := range g.config.RepositoryList {
-	// 	if !e.IsValid() {
-	// 		log.Fatalln("invalid repository config with", i, "index")
-	// 	}
-
-	// 	if err := g.AddRepository(e.Name, e.TypeDB, e.DriverDB); err != nil {
-	// 		return err
-	// 	}
-	// }
-
 	return nil
 }
 
@@ -732,6 +890,7 @@ func (g *Generator) GetTmplParams() templater.GeneratorParams {
 		Workers:             g.Workers,
 		JSONSchemas:         g.JSONSchemas,
 		Kafka:               g.Kafka,
+		Repositories:        g.Repositories,
 		Grafana:             g.Grafana,
 		Artifacts:           g.Artifacts,
 		Documentation:       g.Documentation,
@@ -762,11 +921,12 @@ func (g *Generator) GetTmplRunnerParams(worker ds.Worker) templater.GeneratorRun
 	}
 }
 
-func (g *Generator) CopySpecs() error {
+// CopySpecs copies transport specs into the target tree with copyFile
+func (g *Generator) CopySpecs(copyFile func(src, dst string) error) error {
 	for _, app := range g.Applications {
 		for _, transport := range app.Transports {
 			for specNum, spec := range transport.SpecPath {
-				if _, err := os.Stat(spec); err != nil {
+				if !errors.Is(tools.FileExistsFS(g.FS, spec), tools.ErrExist) {
 					return fmt.Errorf("spec file not found: %s", spec)
 				}
 
@@ -777,9 +937,9 @@ func (g *Generator) CopySpecs() error {
 					transport.GetTargetSpecFile(specNum),
 				)
 
-				log.Printf("copy spec: `%s` to `%s`\n", source, dest)
+				g.log().Debug("copy spec", "from", source, "to", dest)
 
-				if err := tools.CopyFile(source, dest); err != nil {
+				if err := copyFile(source, dest); err != nil {
 					return err
 				}
 			}
@@ -789,7 +949,8 @@ func (g *Generator) CopySpecs() error {
 	return nil
 }
 
-func (g *Generator) CopySchemas() error {
+// CopySchemas copies JSON schemas into the target tree with copyFile
+func (g *Generator) CopySchemas(copyFile func(src, dst string) error) error {
 	for _, schema := range g.JSONSchemas {
 		targetDir := schema.GetTargetSpecDir(g.TargetDir)
 
@@ -809,16 +970,16 @@ func (g *Generator) CopySchemas() error {
 		}
 
 		for _, schemaPath := range paths {
-			if _, err := os.Stat(schemaPath); err != nil {
+			if err := tools.FileExistsFS(g.FS, schemaPath); !errors.Is(err, tools.ErrExist) {
 				return errors.Wrapf(err, "schema file not found: %s", schemaPath)
 			}
 
 			_, fileName := filepath.Split(schemaPath)
 			dest := filepath.Join(targetDir, fileName)
 
-			log.Printf("copy schema: `%s` to `%s`\n", schemaPath, dest)
+			g.log().Debug("copy schema", "from", schemaPath, "to", dest)
 
-			if err := tools.CopyFile(schemaPath, dest); err != nil {
+			if err := copyFile(schemaPath, dest); err != nil {
 				return err
 			}
 		}
@@ -827,6 +988,121 @@ func (g *Generator) CopySchemas() error {
 	return nil
 }
 
+// render collects the file set, narrowed to the --only scope, and renders every template with
+// the user code found in the target tree
+func (g *Generator) render(targetPath string) ([]ds.Files, []ds.Files, ds.FilesDiff, error) {
+	if err := g.startLayout(targetPath); err != nil {
+		return nil, nil, ds.FilesDiff{}, err
+	}
+
+	if err := g.checkLayout(); err != nil {
+		return nil, nil, ds.FilesDiff{}, err
+	}
+
+	dirs, files, err := g.collectFiles(targetPath)
+	if err != nil {
+		return nil, nil, ds.FilesDiff{}, errors.Wrap(err, "Error collect files")
+	}
+
+	if dirs, files, err = g.selectScope(targetPath, dirs, files); err != nil {
+		return nil, nil, ds.FilesDiff{}, err
+	}
+
+	filesDiff, err := templater.GetUserCodeFromScope(targetPath, files, g.Adopt, g.scope.contains)
+	if err != nil {
+		return nil, nil, ds.FilesDiff{}, errors.Wrap(err, "Error get user code")
+	}
+
+	g.migrateUserCode(files, filesDiff)
+
+	start := time.Now()
+
+	if err := renderFiles(g.templates, files, filesDiff); err != nil {
+		return nil, nil, ds.FilesDiff{}, err
+	}
+
+	for _, file := range files {
+		source := file.SourceName
+		if file.Plugin != "" {
+			source = "plugin " + file.Plugin
+		}
+
+		g.log().Debug("render file", "file", file.DestName, "source", source)
+	}
+
+	g.log().Debug("rendered", "files", len(files), "dirs", len(dirs), "elapsed", time.Since(start))
+
+	return dirs, files, filesDiff, nil
+}
+
+// Diff renders the project and writes a unified diff against the target tree to w.
+// Nothing is written to the target. Reports whether regeneration would change anything.
+func (g *Generator) Diff(w io.Writer) (bool, error) {
+	targetPath, err := filepath.Abs(g.TargetDir)
+	if err != nil {
+		return false, errors.Wrap(err, "Error target path")
+	}
+
+	files, filesDiff, err := g.renderMerged(targetPath)
+	if err != nil {
+		return false, err
+	}
+
+	return writeFilesDiff(w, targetPath, files, filesDiff)
+}
+
+// Render renders the project without writing anything. It returns the code of the files Generate
+// would write, keyed by slash-separated path relative to the target, and the diff against the target
+// tree. Hand edits are merged as Generate merges them; specs and the config are not copied.
+func (g *Generator) Render() (map[string][]byte, ds.FilesDiff, error) {
+	targetPath, err := filepath.Abs(g.TargetDir)
+	if err != nil {
+		return nil, ds.FilesDiff{}, errors.Wrap(err, "Error target path")
+	}
+
+	files, filesDiff, err := g.renderMerged(targetPath)
+	if err != nil {
+		return nil, ds.FilesDiff{}, err
+	}
+
+	rendered := make(map[string][]byte, len(files))
+
+	for _, file := range files {
+		if _, ex := filesDiff.IgnoreFiles[file.DestName]; ex {
+			continue
+		}
+
+		rendered[filepath.ToSlash(relPath(targetPath, file.DestName))] = file.Code.Bytes()
+	}
+
+	return rendered, filesDiff, nil
+}
+
+// renderMerged renders the project with the hand edits of the target merged, the returned files
+// include the .orphan files of adopted content
+func (g *Generator) renderMerged(targetPath string) ([]ds.Files, ds.FilesDiff, error) {
+	_, files, filesDiff, err := g.render(targetPath)
+	if err != nil {
+		return nil, ds.FilesDiff{}, err
+	}
+
+	handEdited, err := g.findHandEdited(targetPath, files, filesDiff)
+	if err != nil {
+		return nil, ds.FilesDiff{}, errors.Wrap(err, "Error check generated files")
+	}
+
+	if _, err = g.mergeHandEdited(targetPath, files, filesDiff, handEdited); err != nil {
+		return nil, ds.FilesDiff{}, errors.Wrap(err, "Error merge hand-edited files")
+	}
+
+	orphans, err := orphanFiles(filesDiff)
+	if err != nil {
+		return nil, ds.FilesDiff{}, err
+	}
+
+	return append(files, orphans...), filesDiff, nil
+}
+
 // ToDo Generate generates the content of a file and writes it to the specified destination path.
 // It also applies custom code patches and saves a snapshot of the generated content.
 // Добавить проверку, что хватает менста на диске
@@ -836,167 +1112,201 @@ func (g *Generator) Generate() error {
 		return errors.Wrap(err, "Error target path")
 	}
 
-	dirs, files, err := g.collectFiles(targetPath)
+	g.Report = Report{Target: targetPath, DryRun: g.DryRun}
+
+	if err = g.checkBackupMode(); err != nil {
+		return err
+	}
+
+	dirs, files, filesDiff, err := g.render(targetPath)
 	if err != nil {
-		return errors.Wrap(err, "Error collect files")
+		return err
 	}
 
-	filesDiff, err := templater.GetUserCodeFromFiles(g.TargetDir, files)
+	handEdited, err := g.findHandEdited(targetPath, files, filesDiff)
 	if err != nil {
-		return errors.Wrap(err, "Error get user code")
+		return errors.Wrap(err, "Error check generated files")
 	}
 
-	for i := range files {
-		tmpl, err := templater.GetTemplate(files[i].SourceName)
-		if err != nil {
-			return fmt.Errorf("failed to get template %s: %w", files[i].SourceName, err)
-		}
+	merge, err := g.mergeHandEdited(targetPath, files, filesDiff, handEdited)
+	if err != nil {
+		return errors.Wrap(err, "Error merge hand-edited files")
+	}
 
-		files[i].Code, err = templater.GenerateByTmpl(tmpl, files[i].ParamsTmpl, filesDiff.UserContent[files[i].DestName], files[i].DestName)
-		if err != nil {
-			return errors.Wrap(err, "Error generate")
-		}
+	handEdited = merge.Unmerged
+
+	orphans, err := orphanFiles(filesDiff)
+	if err != nil {
+		return err
+	}
+
+	fileChanges, err := collectFileChanges(targetPath, append(files, orphans...), filesDiff)
+	if err != nil {
+		return errors.Wrap(err, "Error collect changes")
 	}
 
+	g.Report = g.newReport(targetPath, files, filesDiff, orphans, merge, newChanges(fileChanges))
+
 	if g.DryRun {
+		out := g.Output
+		if out == nil {
+			out = os.Stdout
+		}
+
+		g.writeMigrationsPreview(out, targetPath, files, filesDiff)
+
+		for _, file := range handEdited {
+			fmt.Fprintf(out, "Hand-edited generated file: %s\n", file)
+		}
+
+		for _, file := range merge.Merged {
+			fmt.Fprintf(out, "Merge hand-edited generated file: %s\n", file)
+		}
+
+		for _, file := range merge.Conflicts {
+			fmt.Fprintf(out, "Merge conflict in generated file: %s\n", file)
+		}
+
+		for _, orphan := range orphans {
+			fmt.Fprintf(out, "Adopt content: %s -> %s\n", orphan.OldDestName, orphan.DestName)
+		}
+
 		for file := range filesDiff.IgnoreFiles {
-			fmt.Printf("Ignore file: %s\n", file)
+			fmt.Fprintf(out, "Ignore file: %s\n", file)
 		}
 
 		for file := range filesDiff.NewDirectory {
-			fmt.Printf("Created new directory: %s\n", file)
+			fmt.Fprintf(out, "Created new directory: %s\n", file)
 		}
 
 		for oldFile, newFile := range filesDiff.RenameFiles {
-			fmt.Printf("Rename file: %s -> %s\n", oldFile, newFile)
+			fmt.Fprintf(out, "Rename file: %s -> %s\n", oldFile, newFile)
 		}
 
 		for file := range filesDiff.NewFiles {
-			fmt.Printf("Created new file: %s\n", file)
+			fmt.Fprintf(out, "Created new file: %s\n", file)
 		}
 
 		for file := range filesDiff.OtherDirectory {
-			fmt.Printf("User dir: %s\n", file)
+			fmt.Fprintf(out, "User dir: %s\n", file)
 		}
 
 		for file := range filesDiff.OtherFiles {
-			fmt.Printf("User file: %s\n", file)
+			fmt.Fprintf(out, "User file: %s\n", file)
 		}
 
 		for file, content := range filesDiff.UserContent {
-			fmt.Printf("Store user content in file: %s (len: %d)\n", file, len(content))
+			fmt.Fprintf(out, "Store user content in file: %s (len: %d)\n", file, len(content))
+		}
+
+		for file, regions := range filesDiff.UserRegions {
+			fmt.Fprintf(out, "Store user code regions in file: %s (regions: %d)\n", file, len(regions))
 		}
 
 		for file := range filesDiff.ObsoleteFiles {
-			fmt.Printf("Remove obsolete file: %s\n", file)
+			fmt.Fprintf(out, "Remove obsolete file: %s\n", file)
 		}
 
 		return nil
 	}
 
-	if err = tools.MakeDirs(dirs); err != nil {
-		return errors.Wrap(err, "Error make dir")
+	if len(handEdited) > 0 && !g.Force {
+		return handEditedError(targetPath, handEdited)
 	}
 
-	for oldFile, newFile := range filesDiff.RenameFiles {
-		st, err := os.Stat(oldFile)
-		if err != nil {
-			if _, ok := err.(*fs.PathError); ok {
-				continue
-			}
-
-			return fmt.Errorf("error stat file %s: %w", oldFile, err)
-		}
+	if err = g.checkGitClean(targetPath); err != nil {
+		return err
+	}
 
-		log.Printf("stat: %+v -> %T\n", st, err)
-		if st, err := os.Stat(newFile); err == nil && st.Name() == filepath.Base(newFile) {
-			return errors.New("Want to rename but new file exists: " + newFile)
-		}
+	if err = g.backupTarget(targetPath); err != nil {
+		return err
+	}
 
-		if err = os.Rename(oldFile, newFile); err != nil {
-			return errors.Wrap(err, "Error rename old file")
-		}
+	tx, err := newFSTransaction(targetPath)
+	if err != nil {
+		return errors.Wrap(err, "Error start transaction")
 	}
+	defer tx.Close()
+
+	tx.log = g.log()
 
 	for _, file := range files {
 		if _, ex := filesDiff.IgnoreFiles[file.DestName]; ex {
 			continue
 		}
 
-		dstFile, err := os.Create(file.DestName)
-		if err != nil {
-			return errors.Wrap(err, "Error create file")
+		if err = tx.Stage(file.DestName, file.Code); err != nil {
+			return errors.Wrap(err, "Error stage file")
 		}
-		defer dstFile.Close()
+	}
 
-		file.Code.WriteTo(dstFile)
+	// Files with conflict markers do not parse until the conflicts are resolved
+	for _, file := range merge.Conflicts {
+		tx.SkipValidation(file)
 	}
 
-	// Remove obsolete generated files (stale psg_*_gen.go without user code)
-	for obsoleteFile := range filesDiff.ObsoleteFiles {
-		log.Printf("removing obsolete generated file: %s", obsoleteFile)
+	for _, orphan := range orphans {
+		g.log().Info("adopt content", "from", orphan.OldDestName, "to", orphan.DestName)
 
-		if err := os.Remove(obsoleteFile); err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("error removing obsolete file %s: %w", obsoleteFile, err)
+		if err = tx.Stage(orphan.DestName, orphan.Code); err != nil {
+			return errors.Wrap(err, "Error stage orphan file")
 		}
 	}
 
-	if err = g.CopySpecs(); err != nil {
-		return errors.Wrap(err, "Error copy spec")
+	if err = tx.Validate(); err != nil {
+		return errors.Wrap(err, "Error validate generated files")
 	}
 
-	if err = g.CopySchemas(); err != nil {
-		return errors.Wrap(err, "Error copy schemas")
+	if err = g.apply(tx, targetPath, dirs, files, filesDiff, handEdited); err != nil {
+		return tx.rollbackWith(err)
 	}
 
-	// Create .project-config directory in target for meta.yaml and config
-	projectConfigDir := filepath.Join(targetPath, ".project-config")
-	if err = os.MkdirAll(projectConfigDir, tools.DefaultDirPerm); err != nil {
-		return fmt.Errorf("error creating .project-config directory: %w", err)
+	for _, file := range merge.Merged {
+		g.log().Info("hand edits merged with the new generation", "file", file)
 	}
 
-	// Copy config file to target's .project-config for regeneration support
-	if g.ConfigPath != "" {
-		targetConfigPath := filepath.Join(projectConfigDir, "project.yaml")
-
-		// Resolve both paths to absolute for proper comparison
-		absSourcePath, err := filepath.Abs(g.ConfigPath)
-		if err != nil {
-			return fmt.Errorf("error resolving source config path: %w", err)
-		}
-
-		absTargetPath, err := filepath.Abs(targetConfigPath)
-		if err != nil {
-			return fmt.Errorf("error resolving target config path: %w", err)
-		}
-
-		// Only copy if source is different from target
-		if absSourcePath != absTargetPath {
-			if err = tools.CopyFile(g.ConfigPath, targetConfigPath); err != nil {
-				return fmt.Errorf("error copying config to target: %w", err)
-			}
+	// Post generate steps would fail on unresolved conflicts, the merged files are kept for resolving
+	if len(merge.Conflicts) > 0 {
+		return mergeConflictsError(targetPath, merge.Conflicts)
+	}
 
-			log.Printf("copy config: `%s` to `%s`", g.ConfigPath, targetConfigPath)
-		}
+	// go.sum is not generated but post_generate steps (go mod tidy) rewrite it
+	if err = tx.Snapshot(filepath.Join(targetPath, "go.sum")); err != nil {
+		return tx.rollbackWith(err)
 	}
 
-	if err = g.Meta.Save(); err != nil {
-		return fmt.Errorf("error save meta: %w", err)
+	// Files the steps create (.git of git_install, make generate output) are removed on failure
+	if err = tx.TrackNew(); err != nil {
+		return tx.rollbackWith(err)
 	}
 
 	for _, procData := range g.PostGenerate {
 		cmd := exec.Command(procData.Cmd, procData.Arg...)
 		cmd.Dir = targetPath
 
-		log.Printf("run: %s\n", procData.Msg)
+		g.log().Info("run post generate step", "step", procData.Step, "msg", procData.Msg)
 
+		start := time.Now()
 		out, err := cmd.CombinedOutput()
+
+		step := StepResult{
+			Step:       procData.Step,
+			Command:    strings.Join(append([]string{procData.Cmd}, procData.Arg...), " "),
+			DurationMs: time.Since(start).Milliseconds(),
+			Output:     string(out),
+		}
+
 		if err != nil {
-			return fmt.Errorf("error run %s %s: %w (with output: %s)", procData.Cmd, strings.Join(procData.Arg, ", "), err, out)
+			step.Error = err.Error()
+			g.Report.PostGenerate = append(g.Report.PostGenerate, step)
+
+			return tx.rollbackWith(fmt.Errorf("error run %s %s: %w (with output: %s)", procData.Cmd, strings.Join(procData.Arg, ", "), err, out))
 		}
 
+		g.Report.PostGenerate = append(g.Report.PostGenerate, step)
+
 		if len(out) > 0 {
-			log.Printf("result: %s\n", out)
+			g.log().Debug("post generate step output", "step", procData.Step, "output", string(out))
 		}
 	}
 
@@ -1010,17 +1320,17 @@ func (g *Generator) Generate() error {
 				"https://github.com/onlineconf/onlineconf", "etc/repo-oc")
 			cmd.Dir = targetPath
 
-			log.Println("run: add onlineconf submodule")
+			g.log().Info("add onlineconf submodule")
 
 			out, err := cmd.CombinedOutput()
 			if err != nil {
-				return fmt.Errorf("error adding submodule: %w (output: %s)", err, out)
+				return tx.rollbackWith(fmt.Errorf("error adding submodule: %w (output: %s)", err, out))
 			}
 
 			// Note: Using default branch (main) which contains the node:18 fix
 			// Tag v3.5.0 has a bug with FROM node (uses latest which is v25, incompatible with postcss)
 		} else {
-			log.Println("skip: onlineconf submodule already exists")
+			g.log().Debug("onlineconf submodule already exists, skipped")
 		}
 
 		// Create initial commit so that git HEAD works for docker builds
@@ -1033,11 +1343,11 @@ func (g *Generator) Generate() error {
 			cmd := exec.Command("git", "add", ".")
 			cmd.Dir = targetPath
 
-			log.Println("run: git add .")
+			g.log().Info("run git add .")
 
 			out, err := cmd.CombinedOutput()
 			if err != nil {
-				return fmt.Errorf("error git add: %w (output: %s)", err, out)
+				return tx.rollbackWith(fmt.Errorf("error git add: %w (output: %s)", err, out))
 			}
 
 			// Use -c to set author/committer for this commit only (works without global git config)
@@ -1047,21 +1357,195 @@ func (g *Generator) Generate() error {
 				"commit", "-m", "Initial commit (auto-generated by go-project-starter)")
 			cmd.Dir = targetPath
 
-			log.Println("run: git commit -m 'Initial commit'")
+			g.log().Info("create initial commit")
 
 			out, err = cmd.CombinedOutput()
 			if err != nil {
-				return fmt.Errorf("error git commit: %w (output: %s)", err, out)
+				return tx.rollbackWith(fmt.Errorf("error git commit: %w (output: %s)", err, out))
 			}
 		} else {
-			log.Println("skip: git repository already has commits")
+			g.log().Debug("git repository already has commits, initial commit skipped")
+		}
+	}
+
+	g.log().Info("generated", "target", targetPath, "changes", g.Report.Changes.String())
+
+	return nil
+}
+
+// apply writes staged files, renames, obsolete file removals, specs, config and meta into the target tree.
+// Every change goes through tx so a failure can be rolled back.
+func (g *Generator) apply(tx *fsTransaction, targetPath string, dirs, files []ds.Files, filesDiff ds.FilesDiff, handEdited []string) error {
+	if err := backupHandEdited(tx, targetPath, handEdited); err != nil {
+		return errors.Wrap(err, "Error backup hand-edited files")
+	}
+
+	if err := tx.MakeDirs(dirs); err != nil {
+		return errors.Wrap(err, "Error make dir")
+	}
+
+	for oldFile, newFile := range filesDiff.RenameFiles {
+		st, err := os.Stat(oldFile)
+		if err != nil {
+			if _, ok := err.(*fs.PathError); ok {
+				continue
+			}
+
+			return fmt.Errorf("error stat file %s: %w", oldFile, err)
+		}
+
+		g.log().Debug("rename file", "from", oldFile, "to", newFile, "mode", st.Mode())
+
+		if st, err := os.Stat(newFile); err == nil && st.Name() == filepath.Base(newFile) {
+			return errors.New("Want to rename but new file exists: " + newFile)
+		}
+
+		if err = tx.Rename(oldFile, newFile); err != nil {
+			return errors.Wrap(err, "Error rename old file")
+		}
+	}
+
+	if err := tx.Apply(); err != nil {
+		return errors.Wrap(err, "Error write files")
+	}
+
+	// Remove obsolete generated files (stale psg_*_gen.go without user code)
+	for obsoleteFile := range filesDiff.ObsoleteFiles {
+		g.log().Info("remove obsolete generated file", "file", obsoleteFile)
+
+		if err := tx.Remove(obsoleteFile); err != nil {
+			return fmt.Errorf("error removing obsolete file %s: %w", obsoleteFile, err)
+		}
+	}
+
+	if err := g.CopySpecs(g.copySource(tx)); err != nil {
+		return errors.Wrap(err, "Error copy spec")
+	}
+
+	if err := g.CopySchemas(g.copySource(tx)); err != nil {
+		return errors.Wrap(err, "Error copy schemas")
+	}
+
+	// Create .project-config directory in target for meta.yaml and config
+	projectConfigDir := filepath.Join(targetPath, ".project-config")
+	if err := tx.mkdirAll(projectConfigDir, tools.DefaultDirPerm); err != nil {
+		return fmt.Errorf("error creating .project-config directory: %w", err)
+	}
+
+	// Copy config file to target's .project-config for regeneration support
+	copyConfig, err := g.copiesConfig(targetPath)
+	if err != nil {
+		return err
+	}
+
+	if copyConfig {
+		targetConfigPath := filepath.Join(projectConfigDir, "project.yaml")
+
+		if err = g.copySource(tx)(g.ConfigPath, targetConfigPath); err != nil {
+			return fmt.Errorf("error copying config to target: %w", err)
+		}
+
+		g.log().Debug("copy config", "from", g.ConfigPath, "to", targetConfigPath)
+
+		if err = g.copyConfigIncludes(tx, projectConfigDir); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Snapshot(g.Meta.Path); err != nil {
+		return err
+	}
+
+	if err := writeSnapshots(tx, targetPath, files, filesDiff, g.scope.contains); err != nil {
+		return err
+	}
+
+	g.recordMigrations()
+	g.Meta.Checksums = g.scope.keepChecksums(targetPath, g.Meta.Checksums, generatedChecksums(targetPath, files, filesDiff))
+
+	if err := g.Meta.Save(); err != nil {
+		return fmt.Errorf("error save meta: %w", err)
+	}
+
+	return nil
+}
+
+// copiesConfig reports whether the generation copies the config to the .project-config of the target
+func (g *Generator) copiesConfig(targetPath string) (bool, error) {
+	if g.ConfigPath == "" {
+		return false, nil
+	}
+
+	absSourcePath, err := filepath.Abs(g.ConfigPath)
+	if err != nil {
+		return false, fmt.Errorf("error resolving source config path: %w", err)
+	}
+
+	absTargetPath, err := filepath.Abs(filepath.Join(targetPath, ".project-config", "project.yaml"))
+	if err != nil {
+		return false, fmt.Errorf("error resolving target config path: %w", err)
+	}
+
+	return absSourcePath != absTargetPath, nil
+}
+
+// includeRelPath returns the path of an included config relative to the config directory,
+// false if the file is outside of it
+func includeRelPath(configDir, include string) (string, bool) {
+	rel, err := filepath.Rel(configDir, include)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return rel, true
+}
+
+// copyConfigIncludes copies the files included by the config next to its copy in the target, at the same
+// relative paths. Files outside the config directory are not copied, the copy then needs them at the old paths.
+func (g *Generator) copyConfigIncludes(tx *fsTransaction, projectConfigDir string) error {
+	configDir := filepath.Dir(g.ConfigPath)
+
+	for _, include := range g.ConfigIncludes {
+		rel, ok := includeRelPath(configDir, include)
+		if !ok {
+			g.log().Warn("included config is outside of the config directory, not copied", "file", include, "dir", configDir)
+
+			continue
+		}
+
+		if err := g.copySource(tx)(include, filepath.Join(projectConfigDir, rel)); err != nil {
+			return fmt.Errorf("error copying included config %s to target: %w", include, err)
 		}
 	}
 
 	return nil
 }
 
+// copySource returns the function copying a config or spec file into the target: the file is read
+// from the file system of the config
+func (g *Generator) copySource(tx *fsTransaction) func(src, dst string) error {
+	if g.FS == nil {
+		return tx.CopyFile
+	}
+
+	return func(src, dst string) error {
+		data, err := tools.ReadFile(g.FS, src)
+		if err != nil {
+			return err
+		}
+
+		return tx.WriteFile(dst, data)
+	}
+}
+
 func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, error) {
+	templates, err := templater.NewTemplates(g.FS, g.TemplatesDir, g.log())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g.templates = templates
+
 	// Determine output filename for AI agent documentation
 	if g.GenerateLlmsMd {
 		llmsPath := filepath.Join(targetPath, "LLMS.md")
@@ -1088,12 +1572,12 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 		}
 	}
 
-	dirs, files, err := templater.GetMainTemplates(g.GetTmplParams())
+	dirs, files, err := g.templates.GetMainTemplates(g.GetTmplParams())
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get main templates: %w", err)
 	}
 
-	dirsDocs, filesDocs, err := templater.GetDocsTemplates(g.GetTmplParams())
+	dirsDocs, filesDocs, err := g.templates.GetDocsTemplates(g.GetTmplParams())
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get docs templates: %w", err)
 	}
@@ -1104,7 +1588,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 	types := g.Transports.GetUniqueTypes()
 
 	for transportType, templateType := range types {
-		dirsTr, filesTr, err := templater.GetTransportTemplates(transportType, g.GetTmplParams())
+		dirsTr, filesTr, err := g.templates.GetTransportTemplates(transportType, g.GetTmplParams())
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get transport templates: %w", err)
 		}
@@ -1114,15 +1598,15 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 
 		for tmplType, tr := range templateType {
 			for _, transport := range tr {
-				dirsTrT, filesTrT, err := templater.GetTransportGeneratorTemplates(transportType, tmplType, g.GetTmplHandlerParams(transport))
+				dirsTrT, filesTrT, err := g.templates.GetTransportGeneratorTemplates(transportType, tmplType, g.GetTmplHandlerParams(transport))
 				if err != nil {
 					return nil, nil, fmt.Errorf("failed to get transport generator templates: %w", err)
 				}
 
 				dirs = append(dirs, dirsTrT...)
-				files = append(files, filesTrT...)
+				files = append(files, forTransport(filesTrT, transport.Name)...)
 
-				dirsH, filesH, err := templater.GetTransportHandlerTemplates(
+				dirsH, filesH, err := g.templates.GetTransportHandlerTemplates(
 					transport.Type,
 					filepath.Join(transport.GeneratorType, transport.GeneratorTemplate),
 					g.GetTmplHandlerParams(transport),
@@ -1132,7 +1616,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 				}
 
 				dirs = append(dirs, dirsH...)
-				files = append(files, filesH...)
+				files = append(files, forTransport(filesH, transport.Name)...)
 			}
 		}
 	}
@@ -1140,7 +1624,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 	workerTypes := g.Workers.GetUniqueTypes()
 
 	for tmplType, w := range workerTypes {
-		dirsTr, filesTr, err := templater.GetWorkerTemplates(g.GetTmplParams())
+		dirsTr, filesTr, err := g.templates.GetWorkerTemplates(g.GetTmplParams())
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get worker templates: %w", err)
 		}
@@ -1148,7 +1632,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 		dirs = append(dirs, dirsTr...)
 		files = append(files, filesTr...)
 
-		dirsTrT, filesTrT, err := templater.GetWorkerGeneratorTemplates(tmplType, g.GetTmplParams())
+		dirsTrT, filesTrT, err := g.templates.GetWorkerGeneratorTemplates(tmplType, g.GetTmplParams())
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get worker generator templates: %w", err)
 		}
@@ -1160,7 +1644,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 
 		for _, work := range w {
 			if !generatedShared[work.GeneratorTemplate] {
-				dirsS, filesS, err := templater.GetWorkerRunnerSharedTemplates(
+				dirsS, filesS, err := g.templates.GetWorkerRunnerSharedTemplates(
 					filepath.Join(work.GeneratorType, work.GeneratorTemplate),
 					g.GetTmplParams(),
 				)
@@ -1174,7 +1658,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 				generatedShared[work.GeneratorTemplate] = true
 			}
 
-			dirsH, filesH, err := templater.GetWorkerRunnerTemplates(
+			dirsH, filesH, err := g.templates.GetWorkerRunnerTemplates(
 				filepath.Join(work.GeneratorType, work.GeneratorTemplate),
 				g.GetTmplRunnerParams(work),
 			)
@@ -1187,7 +1671,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 		}
 	}
 
-	dirsL, filesL, err := templater.GetLoggerTemplates(g.Logger.FilesToGenerate(), g.Logger.DestDir(), g.GetTmplParams())
+	dirsL, filesL, err := g.templates.GetLoggerTemplates(g.Logger.FilesToGenerate(), g.Logger.DestDir(), g.GetTmplParams())
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get logger templates: %w", err)
 	}
@@ -1197,7 +1681,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 
 	// Generate Kafka driver templates for segmentio drivers
 	for _, kafka := range g.Kafka {
-		dirsK, filesK, err := templater.GetKafkaDriverTemplates(kafka, g.GetTmplParams())
+		dirsK, filesK, err := g.templates.GetKafkaDriverTemplates(kafka, g.GetTmplParams())
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get kafka driver templates for %s: %w", kafka.Name, err)
 		}
@@ -1206,62 +1690,72 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 		files = append(files, filesK...)
 	}
 
+	for _, repo := range g.Repositories {
+		dirsR, filesR, err := g.templates.GetRepositoryTemplates(repo, g.GetTmplParams())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get repository templates for %s: %w", repo.Name, err)
+		}
+
+		dirs = append(dirs, dirsR...)
+		files = append(files, filesR...)
+	}
+
 	for _, app := range g.Applications {
-		dirApp, filesApp, err := templater.GetAppTemplates(g.GetTmplAppParams(app))
+		dirApp, filesApp, err := g.templates.GetAppTemplates(g.GetTmplAppParams(app))
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get app templates: %w", err)
 		}
 
 		dirs = append(dirs, dirApp...)
-		files = append(files, filesApp...)
+		files = append(files, forApp(filesApp, app.Name)...)
 
 		// Generate CLI handler templates for CLI apps
 		if app.IsCLI() {
-			dirsCLI, filesCLI, err := templater.GetCLIHandlerTemplates(app.CLI, g.GetTmplParams())
+			dirsCLI, filesCLI, err := g.templates.GetCLIHandlerTemplates(app.CLI, g.GetTmplParams())
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to get CLI handler templates: %w", err)
 			}
 
 			dirs = append(dirs, dirsCLI...)
-			files = append(files, filesCLI...)
+			files = append(files, forApp(filesCLI, app.Name)...)
 		}
 
 		// Generate GOAT test templates for applications with goat_tests enabled
 		if app.GoatTests && !app.IsCLI() {
-			dirsTest, filesTest, err := templater.GetTestTemplates(g.GetTmplAppParams(app))
+			dirsTest, filesTest, err := g.templates.GetTestTemplates(g.GetTmplAppParams(app))
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to get test templates for %s: %w", app.Name, err)
 			}
 
 			dirs = append(dirs, dirsTest...)
-			files = append(files, filesTest...)
+			files = append(files, forApp(filesTest, app.Name)...)
 
 			// Generate mock templates for applications with ogen_clients
 			if app.HasOgenClients() {
-				dirsMock, filesMock, err := templater.GetMockTemplates(g.GetTmplAppParams(app))
+				dirsMock, filesMock, err := g.templates.GetMockTemplates(g.GetTmplAppParams(app))
 				if err != nil {
 					return nil, nil, fmt.Errorf("failed to get mock templates for %s: %w", app.Name, err)
 				}
 
 				dirs = append(dirs, dirsMock...)
-				files = append(files, filesMock...)
+				files = append(files, forApp(filesMock, app.Name)...)
 			}
 		}
 
 		// Generate packaging templates for applications when packaging artifacts are enabled
-		dirsPkg, filesPkg, err := templater.GetPackagingTemplates(g.GetTmplAppParams(app))
+		dirsPkg, filesPkg, err := g.templates.GetPackagingTemplates(g.GetTmplAppParams(app))
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get packaging templates for %s: %w", app.Name, err)
 		}
 
 		dirs = append(dirs, dirsPkg...)
-		files = append(files, filesPkg...)
+		files = append(files, forApp(filesPkg, app.Name)...)
 	}
 
 	// Generate Grafana templates if any datasources are configured
 	if g.Grafana.HasDatasources() {
 		// Generate global provisioning templates (datasources and dashboard provider config)
-		dirsGrafana, filesGrafana, err := templater.GetGrafanaProvisioningTemplates(g.GetTmplParams())
+		dirsGrafana, filesGrafana, err := g.templates.GetGrafanaProvisioningTemplates(g.GetTmplParams())
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get grafana provisioning templates: %w", err)
 		}
@@ -1272,19 +1766,19 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 		// Generate dashboard for each application that has Grafana datasources
 		for _, app := range g.Applications {
 			if app.Grafana.HasDatasources() {
-				dirsAppGrafana, filesAppGrafana, err := templater.GetGrafanaDashboardTemplates(g.GetTmplAppParams(app))
+				dirsAppGrafana, filesAppGrafana, err := g.templates.GetGrafanaDashboardTemplates(g.GetTmplAppParams(app))
 				if err != nil {
 					return nil, nil, fmt.Errorf("failed to get grafana dashboard templates for %s: %w", app.Name, err)
 				}
 
 				dirs = append(dirs, dirsAppGrafana...)
-				files = append(files, filesAppGrafana...)
+				files = append(files, forApp(filesAppGrafana, app.Name)...)
 			}
 		}
 
 		// Generate Prometheus config for dev environment if prometheus datasource exists
 		if g.Grafana.HasPrometheus() {
-			dirsProm, filesProm, err := templater.GetPrometheusTemplates(g.GetTmplParams())
+			dirsProm, filesProm, err := g.templates.GetPrometheusTemplates(g.GetTmplParams())
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to get prometheus templates: %w", err)
 			}
@@ -1295,7 +1789,7 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 
 		// Generate Loki config for dev environment if loki datasource exists
 		if g.Grafana.HasLoki() {
-			dirsLoki, filesLoki, err := templater.GetLokiTemplates(g.GetTmplParams())
+			dirsLoki, filesLoki, err := g.templates.GetLokiTemplates(g.GetTmplParams())
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to get loki templates: %w", err)
 			}
@@ -1305,10 +1799,6 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 		}
 	}
 
-	// ToDo check git status
-	// ToDo make backup of targetPath
-	// ToDo validate checksum previous generated files
-
 	for i := range dirs {
 		if err := templater.GenerateFilenameByTmpl(&dirs[i], targetPath, g.Meta.Version); err != nil {
 			return nil, nil, fmt.Errorf("failed to generate filename by template %s: %w", dirs[i].DestName, err)
@@ -1321,6 +1811,14 @@ func (g *Generator) collectFiles(targetPath string) ([]ds.Files, []ds.Files, err
 		}
 	}
 
+	// Plugin files are named by the plugins and collected last, so collisions with templates are reported
+	filesPlugins, err := g.pluginFiles(targetPath, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files = append(files, filesPlugins...)
+
 	return dirs, files, nil
 }
 
@@ -1435,6 +1933,31 @@ func toPascalCase(s string) string {
 	return result.String()
 }
 
+// convertWsSpec converts parsed ws spec messages to ds.WsMessage slice
+func convertWsSpec(spec *cfg.WsSpec) []ds.WsMessage {
+	messages := make([]ds.WsMessage, 0, len(spec.Messages))
+
+	for _, m := range spec.Messages {
+		fields := make([]ds.WsField, 0, len(m.Fields))
+		for _, f := range m.Fields {
+			fields = append(fields, ds.WsField{
+				Name:   f.Name,
+				GoName: toPascalCase(f.Name),
+				Type:   f.Type,
+			})
+		}
+
+		messages = append(messages, ds.WsMessage{
+			Name:     m.Name,
+			GoName:   toPascalCase(m.Name),
+			Incoming: m.Direction != cfg.WsDirectionOut,
+			Fields:   fields,
+		})
+	}
+
+	return messages
+}
+
 // convertQueueSpec converts parsed queue spec to ds.QueueConfig
 func convertQueueSpec(spec *cfg.QueueSpec) *ds.QueueConfig {
 	queues := make([]ds.QueueDef, 0, len(spec.Queues))
@@ -1460,6 +1983,47 @@ func convertQueueSpec(spec *cfg.QueueSpec) *ds.QueueConfig {
 	return &ds.QueueConfig{Queues: queues}
 }
 
+func convertSchedulerJobs(jobs []cfg.SchedulerJob) *ds.SchedulerConfig {
+	result := make([]ds.SchedulerJob, 0, len(jobs))
+
+	for _, j := range jobs {
+		result = append(result, ds.SchedulerJob{
+			Name:      j.Name,
+			GoName:    toPascalCase(j.Name),
+			Cron:      j.Cron,
+			Interval:  j.Interval,
+			Timeout:   j.Timeout,
+			Singleton: j.Singleton,
+		})
+	}
+
+	return &ds.SchedulerConfig{Jobs: result}
+}
+
+func convertProtoSpec(spec *cfg.ProtoSpec) []ds.GrpcService {
+	services := make([]ds.GrpcService, 0, len(spec.Services))
+
+	for _, svc := range spec.Services {
+		methods := make([]ds.GrpcMethod, 0, len(svc.Methods))
+		for _, m := range svc.Methods {
+			methods = append(methods, ds.GrpcMethod{
+				Name:            m.Name,
+				Request:         m.Request,
+				Response:        m.Response,
+				ClientStreaming: m.ClientStreaming,
+				ServerStreaming: m.ServerStreaming,
+			})
+		}
+
+		services = append(services, ds.GrpcService{
+			Name:    svc.Name,
+			Methods: methods,
+		})
+	}
+
+	return services
+}
+
 // filenameToTypeName converts a schema filename to a Go type name
 // Example: abonent.user.schema.json → AbonentUserSchemaJson
 func filenameToTypeName(path string) string {
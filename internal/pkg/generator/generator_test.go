@@ -298,6 +298,42 @@ func TestExecCmd(t *testing.T) {
 	}
 }
 
+func TestGenerator_SelectPostGenerate(t *testing.T) {
+	postGenerate := []ExecCmd{
+		{Cmd: "make", Arg: []string{"clean-import"}, Step: "clean_imports"},
+		{Cmd: "git", Arg: []string{"add", "."}, Step: "git_initial_commit"},
+		{Cmd: "git", Arg: []string{"commit", "-m", "Initial commit"}, Step: "git_initial_commit"},
+		{Cmd: "make", Arg: []string{"tidy"}, Step: "go_mod_tidy"},
+	}
+
+	g := &Generator{PostGenerate: postGenerate}
+
+	if err := g.SelectPostGenerate([]string{"go_mod_tidy", "git_initial_commit"}); err != nil {
+		t.Fatalf("SelectPostGenerate() error = %v", err)
+	}
+
+	want := []string{"git", "git", "make"}
+	if len(g.PostGenerate) != len(want) {
+		t.Fatalf("SelectPostGenerate() kept %d steps, want %d", len(g.PostGenerate), len(want))
+	}
+
+	for i, cmd := range g.PostGenerate {
+		if cmd.Cmd != want[i] {
+			t.Errorf("PostGenerate[%d].Cmd = %q, want %q", i, cmd.Cmd, want[i])
+		}
+	}
+
+	g = &Generator{PostGenerate: postGenerate}
+	if err := g.SelectPostGenerate(nil); err != nil || len(g.PostGenerate) != 0 {
+		t.Errorf("SelectPostGenerate(nil) = %v, kept %d steps, want none", err, len(g.PostGenerate))
+	}
+
+	g = &Generator{PostGenerate: postGenerate}
+	if err := g.SelectPostGenerate([]string{"tools_install"}); err == nil {
+		t.Errorf("SelectPostGenerate() with a step missing in the config error = nil")
+	}
+}
+
 func TestGeneratorStruct_Fields(t *testing.T) {
 	g := Generator{
 		AppInfo:             "test-app-info",
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/layout"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+)
+
+// startLayout moves a target without meta.yaml and without generated files to the current layout:
+// there is nothing to migrate. A project generated before meta.yaml existed stays at version 1
+// and gets all the migrations.
+func (g *Generator) startLayout(targetPath string) error {
+	if !g.Meta.NotFound() {
+		return nil
+	}
+
+	generated, err := templater.HasGeneratedFiles(targetPath)
+	if err != nil {
+		return fmt.Errorf("failed to look for generated files: %w", err)
+	}
+
+	if !generated {
+		g.Meta.Version = layout.Current()
+	}
+
+	return nil
+}
+
+// checkLayout refuses projects of a newer layout and partial regeneration of projects that need layout migrations:
+// a migration is recorded for the whole project, files outside the scope would keep the old layout.
+func (g *Generator) checkLayout() error {
+	if err := layout.Check(g.Meta.Version); err != nil {
+		return err
+	}
+
+	if len(g.Only) > 0 && len(layout.Pending(g.Meta.Version)) > 0 {
+		return fmt.Errorf("project layout version %d needs migrations to version %d, regenerate without --only first",
+			g.Meta.Version, layout.Current())
+	}
+
+	return nil
+}
+
+// migrated reports whether the file is found in the target under the name of the layout of a previous version
+func migrated(file ds.Files, filesDiff ds.FilesDiff) bool {
+	if _, ex := filesDiff.RenameFiles[file.OldDestName]; !ex {
+		return false
+	}
+
+	_, err := os.Stat(file.OldDestName)
+
+	return err == nil
+}
+
+// migrateUserCode applies the content rewrites of the pending layout migrations to the user code of migrated files
+func (g *Generator) migrateUserCode(files []ds.Files, filesDiff ds.FilesDiff) {
+	if len(layout.Pending(g.Meta.Version)) == 0 {
+		return
+	}
+
+	for _, file := range files {
+		if !migrated(file, filesDiff) {
+			continue
+		}
+
+		if code, ok := filesDiff.UserContent[file.DestName]; ok {
+			filesDiff.UserContent[file.DestName] = []byte(layout.RewriteCode(string(code), g.Meta.Version))
+		}
+
+		for id, body := range filesDiff.UserRegions[file.DestName] {
+			filesDiff.UserRegions[file.DestName][id] = layout.RewriteCode(body, g.Meta.Version)
+		}
+	}
+}
+
+// writeMigrationsPreview lists the pending layout migrations, each with the files it moves
+func (g *Generator) writeMigrationsPreview(w io.Writer, targetPath string, files []ds.Files, filesDiff ds.FilesDiff) {
+	pending := layout.Pending(g.Meta.Version)
+	if len(pending) == 0 {
+		return
+	}
+
+	moves := make(map[int][]layout.Move, len(pending))
+
+	for _, file := range files {
+		if !migrated(file, filesDiff) {
+			continue
+		}
+
+		for _, move := range layout.Moves(filepath.ToSlash(relPath(targetPath, file.DestName)), g.Meta.Version) {
+			moves[move.Version] = append(moves[move.Version], move)
+		}
+	}
+
+	for _, m := range pending {
+		fmt.Fprintf(w, "Layout migration to version %d: %s\n", m.Version, m.Description)
+
+		for _, move := range moves[m.Version] {
+			fmt.Fprintf(w, "  %s -> %s\n", move.From, move.To)
+		}
+	}
+}
+
+// recordMigrations records the pending layout migrations in meta one at a time, in order
+func (g *Generator) recordMigrations() {
+	for _, m := range layout.Pending(g.Meta.Version) {
+		g.Meta.RecordMigration(m.Version, m.Description, time.Now())
+
+		g.log().Info("layout migrated", "version", m.Version, "description", m.Description)
+	}
+}
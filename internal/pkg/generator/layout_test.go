@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/layout"
+	"github.com/Educentr/go-project-starter/internal/pkg/meta"
+)
+
+// layoutTestFiles returns pkg/ds/psg_types_gen.go of a project of layout version 2, found on disk under its old name
+func layoutTestFiles(t *testing.T, root string) ([]ds.Files, ds.FilesDiff) {
+	t.Helper()
+
+	dest := filepath.Join(root, "pkg", "ds", "psg_types_gen.go")
+	old := filepath.Join(root, "pkg", "app", "ds", "psg_types_gen.go")
+
+	writeTestFile(t, old, "package ds\n")
+
+	filesDiff := emptyFilesDiff()
+	filesDiff.RenameFiles[old] = dest
+	filesDiff.UserContent = map[string][]byte{dest: []byte("import _ \"example.com/svc/pkg/app/ds\"\n")}
+	filesDiff.UserRegions = map[string]map[string]string{dest: {"types": "var _ = \"/pkg/app/ds\"\n"}}
+
+	return []ds.Files{{DestName: dest, OldDestName: old}}, filesDiff
+}
+
+func TestGenerator_MigrateUserCode(t *testing.T) {
+	root := t.TempDir()
+	files, filesDiff := layoutTestFiles(t, root)
+
+	g := Generator{Meta: meta.Meta{Version: 2}}
+	g.migrateUserCode(files, filesDiff)
+
+	dest := files[0].DestName
+
+	if got := string(filesDiff.UserContent[dest]); got != "import _ \"example.com/svc/pkg/ds\"\n" {
+		t.Errorf("UserContent = %q", got)
+	}
+
+	if got := filesDiff.UserRegions[dest]["types"]; got != "var _ = \"/pkg/ds\"\n" {
+		t.Errorf("UserRegions[types] = %q", got)
+	}
+}
+
+func TestGenerator_WriteMigrationsPreview(t *testing.T) {
+	root := t.TempDir()
+	files, filesDiff := layoutTestFiles(t, root)
+
+	var buf bytes.Buffer
+
+	g := Generator{Meta: meta.Meta{Version: 2}}
+	g.writeMigrationsPreview(&buf, root, files, filesDiff)
+
+	want := "Layout migration to version 3: move pkg/app/ds to pkg/ds\n" +
+		"  pkg/app/ds/psg_types_gen.go -> pkg/ds/psg_types_gen.go\n" +
+		"Layout migration to version 4: move generated tests to tests/<app>\n"
+	if buf.String() != want {
+		t.Errorf("writeMigrationsPreview() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+
+	g.Meta.Version = layout.Current()
+	g.writeMigrationsPreview(&buf, root, files, filesDiff)
+
+	if buf.Len() != 0 {
+		t.Errorf("writeMigrationsPreview() of the current layout =\n%s", buf.String())
+	}
+}
+
+func TestGenerator_RecordMigrations(t *testing.T) {
+	g := Generator{Meta: meta.Meta{Version: 2}}
+	g.recordMigrations()
+
+	if g.Meta.Version != layout.Current() {
+		t.Errorf("Meta.Version = %d, want %d", g.Meta.Version, layout.Current())
+	}
+
+	if len(g.Meta.Migrations) != layout.Current()-2 || g.Meta.Migrations[0].Version != 3 {
+		t.Errorf("Meta.Migrations = %+v", g.Meta.Migrations)
+	}
+}
+
+func TestGenerator_CheckLayout(t *testing.T) {
+	g := Generator{Meta: meta.Meta{Version: layout.Current() + 1}}
+	if err := g.checkLayout(); err == nil {
+		t.Error("checkLayout() of a newer layout error = nil, want error")
+	}
+
+	g = Generator{Meta: meta.Meta{Version: 2}, Only: []ScopeFilter{{}}}
+	if err := g.checkLayout(); err == nil || !strings.Contains(err.Error(), "--only") {
+		t.Errorf("checkLayout() with --only and pending migrations error = %v", err)
+	}
+
+	g.Only = nil
+	if err := g.checkLayout(); err != nil {
+		t.Errorf("checkLayout() error = %v", err)
+	}
+}
+
+func TestGenerator_FreshTarget(t *testing.T) {
+	root := t.TempDir()
+
+	// A target without meta.yaml and without generated files is generated in the current layout
+	m, err := meta.GetMeta(filepath.Join(root, ".project-config"), "meta.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := Generator{Meta: m, Only: []ScopeFilter{{}}}
+	if err = g.startLayout(root); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = g.checkLayout(); err != nil {
+		t.Errorf("checkLayout() with --only error = %v", err)
+	}
+
+	g.recordMigrations()
+
+	if g.Meta.Version != layout.Current() || len(g.Meta.Migrations) != 0 {
+		t.Errorf("Meta = %+v, want version %d without migrations", g.Meta, layout.Current())
+	}
+}
+
+func TestGenerator_PreMetaTarget(t *testing.T) {
+	root := t.TempDir()
+
+	// A project generated before meta.yaml existed keeps version 1 and gets all the migrations
+	writeTestFile(t, filepath.Join(root, "pkg", "app", "ds", "psg_types_gen.go"),
+		"package ds\n\n// If you need you can add your code after this message\n")
+
+	m, err := meta.GetMeta(filepath.Join(root, ".project-config"), "meta.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := Generator{Meta: m}
+	if err = g.startLayout(root); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Meta.Version != 1 {
+		t.Fatalf("Meta.Version = %d, want 1", g.Meta.Version)
+	}
+
+	g.recordMigrations()
+
+	if g.Meta.Version != layout.Current() || len(g.Meta.Migrations) != layout.Current()-1 {
+		t.Errorf("Meta = %+v, want version %d with all the migrations", g.Meta, layout.Current())
+	}
+}
@@ -0,0 +1,298 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// projectSnapshotsDir keeps the generated part of every file as the last generation wrote it,
+// the base of the three-way merge with hand edits
+const projectSnapshotsDir = ".project-config/snapshots"
+
+// Conflict markers, as git writes them
+const (
+	conflictCurrent   = "<<<<<<< current"
+	conflictSeparator = "======="
+	conflictGenerated = ">>>>>>> generated"
+)
+
+var errMergeConflicts = errors.New("merge conflicts in generated files")
+
+// mergeResult sorts hand-edited files by what the merge did with them
+type mergeResult struct {
+	Merged    []string // hand edits and template changes merged cleanly, by new name
+	Conflicts []string // written with conflict markers, by new name
+	Unmerged  []string // no snapshot to merge with, or obsolete
+}
+
+// snapshotPath returns where the snapshot of a target file is kept
+func snapshotPath(targetPath, path string) string {
+	return filepath.Join(targetPath, projectSnapshotsDir, relPath(targetPath, path))
+}
+
+// mergeHandEdited merges hand edits of the generated part into the rendered files: the snapshot
+// of the previous generation is the base, the file on disk and the new rendering are the sides.
+// The rendered code of merged files is replaced, Pristine keeps the generated part as rendered.
+// With --force nothing is merged, hand-edited files are overwritten.
+func (g *Generator) mergeHandEdited(targetPath string, files []ds.Files, filesDiff ds.FilesDiff, handEdited []string) (mergeResult, error) {
+	res := mergeResult{}
+
+	if len(handEdited) == 0 || g.Force {
+		res.Unmerged = handEdited
+
+		return res, nil
+	}
+
+	// Hand-edited files are reported under the name they have on disk, which is the old one before a rename
+	byPath := make(map[string]int, len(files))
+	for i, file := range files {
+		byPath[file.DestName] = i
+		byPath[file.OldDestName] = i
+	}
+
+	for _, path := range handEdited {
+		i, ok := byPath[path]
+		if _, ex := filesDiff.ObsoleteFiles[path]; ex {
+			ok = false
+		}
+
+		if !ok {
+			res.Unmerged = append(res.Unmerged, path)
+
+			continue
+		}
+
+		base, err := os.ReadFile(snapshotPath(targetPath, path))
+		if os.IsNotExist(err) {
+			res.Unmerged = append(res.Unmerged, path)
+
+			continue
+		}
+
+		if err != nil {
+			return mergeResult{}, errors.Wrapf(err, "read snapshot of %s", path)
+		}
+
+		current, err := os.ReadFile(path)
+		if err != nil {
+			return mergeResult{}, errors.Wrapf(err, "read %s", path)
+		}
+
+		file := &files[i]
+
+		genCode, userCode := templater.SplitGenerated(file.DestName, file.Code.Bytes())
+		pristine := templater.GeneratedPart(file.DestName, file.Code.Bytes())
+
+		merged, conflict := merge3(base, templater.GeneratedPart(path, current), pristine)
+
+		// The merged part has empty regions, their bodies are taken from the rendered file
+		regions, err := templater.ExtractRegions(string(genCode))
+		if err != nil {
+			return mergeResult{}, errors.Wrapf(err, "merge %s", path)
+		}
+
+		code, err := templater.InjectRegions(append(merged, userCode...), regions)
+		if err != nil {
+			return mergeResult{}, errors.Wrapf(err, "merge %s", path)
+		}
+
+		file.Code = bytes.NewBuffer(code)
+		file.Pristine = pristine
+
+		if conflict {
+			res.Conflicts = append(res.Conflicts, file.DestName)
+		} else {
+			res.Merged = append(res.Merged, file.DestName)
+		}
+	}
+
+	return res, nil
+}
+
+// mergeConflictsError lists files with conflicts relative to the target
+func mergeConflictsError(targetPath string, conflicts []string) error {
+	return fmt.Errorf("%w: %s; resolve the conflicts and rerun to finish post_generate steps", errMergeConflicts, strings.Join(relPaths(targetPath, conflicts), ", "))
+}
+
+// pristinePart returns the generated part of a file as rendered, before a merge with hand edits
+func pristinePart(file ds.Files) []byte {
+	if file.Pristine != nil {
+		return file.Pristine
+	}
+
+	return templater.GeneratedPart(file.DestName, file.Code.Bytes())
+}
+
+// writeSnapshots saves the generated part of every written file for the next merge and
+// removes snapshots of files in scope that are not generated any more
+func writeSnapshots(tx *fsTransaction, targetPath string, files []ds.Files, filesDiff ds.FilesDiff, inScope func(string) bool) error {
+	written := make(map[string]struct{}, len(files))
+
+	for _, file := range files {
+		if _, ex := filesDiff.IgnoreFiles[file.DestName]; ex {
+			continue
+		}
+
+		path := snapshotPath(targetPath, file.DestName)
+		written[path] = struct{}{}
+
+		if err := tx.WriteFile(path, pristinePart(file)); err != nil {
+			return errors.Wrapf(err, "write snapshot of %s", file.DestName)
+		}
+	}
+
+	stale := []string{}
+	snapshotsDir := filepath.Join(targetPath, projectSnapshotsDir)
+
+	err := filepath.WalkDir(snapshotsDir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, ex := written[path]; ex || d.IsDir() {
+			return nil
+		}
+
+		if rel, err := filepath.Rel(snapshotsDir, path); err == nil && inScope(filepath.Join(targetPath, rel)) {
+			stale = append(stale, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "walk snapshots")
+	}
+
+	sort.Strings(stale)
+
+	for _, path := range stale {
+		if err := tx.Remove(path); err != nil {
+			return errors.Wrapf(err, "remove stale snapshot %s", path)
+		}
+	}
+
+	return nil
+}
+
+// merge3 merges the changes from base to current and from base to generated line by line.
+// Regions changed on both sides differently are written between conflict markers.
+func merge3(base, current, generated []byte) ([]byte, bool) {
+	baseLines := splitLines(base)
+	currentLines := splitLines(current)
+	generatedLines := splitLines(generated)
+
+	toCurrent := matchLines(baseLines, currentLines)
+	toGenerated := matchLines(baseLines, generatedLines)
+
+	var (
+		out      bytes.Buffer
+		conflict bool
+	)
+
+	i, j, k := 0, 0, 0
+
+	for {
+		// The next base line kept on both sides ends the chunk changed on either side
+		stable := i
+		for stable < len(baseLines) {
+			if _, ok := toCurrent[stable]; ok {
+				if _, ok := toGenerated[stable]; ok {
+					break
+				}
+			}
+
+			stable++
+		}
+
+		jEnd, kEnd := len(currentLines), len(generatedLines)
+		if stable < len(baseLines) {
+			jEnd, kEnd = toCurrent[stable], toGenerated[stable]
+		}
+
+		if mergeChunk(&out, baseLines[i:stable], currentLines[j:jEnd], generatedLines[k:kEnd]) {
+			conflict = true
+		}
+
+		if stable == len(baseLines) {
+			break
+		}
+
+		out.WriteString(baseLines[stable])
+		i, j, k = stable+1, jEnd+1, kEnd+1
+	}
+
+	return out.Bytes(), conflict
+}
+
+// mergeChunk writes a chunk between stable lines and reports a conflict
+func mergeChunk(out *bytes.Buffer, base, current, generated []string) bool {
+	switch {
+	case slices.Equal(current, base):
+		writeLines(out, generated, false)
+	case slices.Equal(generated, base), slices.Equal(current, generated):
+		writeLines(out, current, false)
+	default:
+		out.WriteString(conflictCurrent + "\n")
+		writeLines(out, current, true)
+		out.WriteString(conflictSeparator + "\n")
+		writeLines(out, generated, true)
+		out.WriteString(conflictGenerated + "\n")
+
+		return true
+	}
+
+	return false
+}
+
+// matchLines maps lines of a to the lines of b they are kept as
+func matchLines(a, b []string) map[int]int {
+	matches := make(map[int]int)
+
+	for _, m := range difflib.NewMatcherWithJunk(a, b, false, nil).GetMatchingBlocks() {
+		for n := range m.Size {
+			matches[m.A+n] = m.B + n
+		}
+	}
+
+	return matches
+}
+
+// splitLines splits content after line ends
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// writeLines writes lines, terminating the last one if a conflict marker follows
+func writeLines(out *bytes.Buffer, lines []string, terminate bool) {
+	for _, ln := range lines {
+		out.WriteString(ln)
+	}
+
+	if terminate && len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		out.WriteByte('\n')
+	}
+}
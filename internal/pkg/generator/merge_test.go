@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+
+	tests := []struct {
+		name         string
+		current      string
+		generated    string
+		want         string
+		wantConflict bool
+	}{
+		{
+			name:      "no hand edits",
+			current:   base,
+			generated: "a\nb\nC\nd\ne\n",
+			want:      "a\nb\nC\nd\ne\n",
+		},
+		{
+			name:      "no template changes",
+			current:   "a\nB\nc\nd\ne\n",
+			generated: base,
+			want:      "a\nB\nc\nd\ne\n",
+		},
+		{
+			name:      "changes in different lines",
+			current:   "a\nB\nc\nd\ne\n",
+			generated: "a\nb\nc\nD\ne\n",
+			want:      "a\nB\nc\nD\ne\n",
+		},
+		{
+			name:      "insertions on both sides",
+			current:   "x\na\nb\nc\nd\ne\n",
+			generated: "a\nb\nc\nd\ne\ny\n",
+			want:      "x\na\nb\nc\nd\ne\ny\n",
+		},
+		{
+			name:      "same change on both sides",
+			current:   "a\nb\nC\nd\ne\n",
+			generated: "a\nb\nC\nd\ne\n",
+			want:      "a\nb\nC\nd\ne\n",
+		},
+		{
+			name:         "conflict",
+			current:      "a\nb\nmine\nd\ne\n",
+			generated:    "a\nb\ntheirs\nd\nE\n",
+			want:         "a\nb\n<<<<<<< current\nmine\n=======\ntheirs\n>>>>>>> generated\nd\nE\n",
+			wantConflict: true,
+		},
+		{
+			name:         "conflict at the end without line end",
+			current:      "a\nb\nc\nd\nmine",
+			generated:    "a\nb\nc\nd\ntheirs\n",
+			want:         "a\nb\nc\nd\n<<<<<<< current\nmine\n=======\ntheirs\n>>>>>>> generated\n",
+			wantConflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := merge3([]byte(base), []byte(tt.current), []byte(tt.generated))
+			if string(got) != tt.want || conflict != tt.wantConflict {
+				t.Errorf("merge3() = %q, %v; want %q, %v", got, conflict, tt.want, tt.wantConflict)
+			}
+		})
+	}
+}
+
+func TestGenerator_MergeHandEdited(t *testing.T) {
+	root := t.TempDir()
+
+	merged := filepath.Join(root, "cmd", "psg_main_gen.go")
+	conflicted := filepath.Join(root, "psg_conflict_gen.go")
+	noSnapshot := filepath.Join(root, "psg_new_gen.go")
+
+	region := "// psg:begin:fields\n// psg:end:fields\n"
+	oldGenerated := "package main\n\nvar a = 1\n\n" + region + "\nvar b = 2\n" + testDisclaimer
+	newGenerated := "package main\n\nvar a = 1\n\n" + region + "\nvar b = 3\n" + testDisclaimer
+
+	writeTestFile(t, snapshotPath(root, merged), oldGenerated)
+	writeTestFile(t, snapshotPath(root, conflicted), oldGenerated)
+
+	// The region body and the code below the disclaimer are kept in the rendered file already
+	rendered := "package main\n\nvar a = 1\n\n// psg:begin:fields\nvar f int\n// psg:end:fields\n\nvar b = 3\n" + testDisclaimer + "\nfunc user() {}\n"
+
+	writeTestFile(t, merged, "package main\n\nvar a = 10\n\n// psg:begin:fields\nvar f int\n// psg:end:fields\n\nvar b = 2\n"+testDisclaimer+"\nfunc user() {}\n")
+	writeTestFile(t, conflicted, "package main\n\nvar a = 1\n\n"+region+"\nvar b = 4\n"+testDisclaimer)
+
+	files := []ds.Files{
+		{DestName: merged, OldDestName: merged, Code: bytes.NewBufferString(rendered)},
+		{DestName: conflicted, OldDestName: conflicted, Code: bytes.NewBufferString(newGenerated)},
+		{DestName: noSnapshot, OldDestName: noSnapshot, Code: bytes.NewBufferString(newGenerated)},
+	}
+
+	g := Generator{}
+
+	res, err := g.mergeHandEdited(root, files, emptyFilesDiff(), []string{merged, conflicted, noSnapshot})
+	if err != nil {
+		t.Fatalf("mergeHandEdited() error = %v", err)
+	}
+
+	if len(res.Merged) != 1 || res.Merged[0] != merged || len(res.Conflicts) != 1 || res.Conflicts[0] != conflicted ||
+		len(res.Unmerged) != 1 || res.Unmerged[0] != noSnapshot {
+		t.Fatalf("mergeHandEdited() = %+v", res)
+	}
+
+	want := "package main\n\nvar a = 10\n\n// psg:begin:fields\nvar f int\n// psg:end:fields\n\nvar b = 3\n" + testDisclaimer + "\nfunc user() {}\n"
+	if got := files[0].Code.String(); got != want {
+		t.Errorf("merged code = %q, want %q", got, want)
+	}
+
+	if got := string(files[0].Pristine); got != newGenerated {
+		t.Errorf("merged Pristine = %q, want the rendered generated part %q", got, newGenerated)
+	}
+
+	wantConflict := "package main\n\nvar a = 1\n\n" + region + "\n<<<<<<< current\nvar b = 4\n=======\nvar b = 3\n>>>>>>> generated\n" + testDisclaimer
+	if got := files[1].Code.String(); got != wantConflict {
+		t.Errorf("conflicted code = %q, want %q", got, wantConflict)
+	}
+
+	if files[2].Pristine != nil || files[2].Code.String() != newGenerated {
+		t.Errorf("file without snapshot was merged")
+	}
+
+	// --force overwrites instead of merging
+	g.Force = true
+
+	res, err = g.mergeHandEdited(root, files, emptyFilesDiff(), []string{merged})
+	if err != nil || len(res.Unmerged) != 1 || len(res.Merged) != 0 {
+		t.Errorf("mergeHandEdited() with force = %+v, %v", res, err)
+	}
+}
+
+func TestWriteSnapshots(t *testing.T) {
+	root := t.TempDir()
+
+	kept := filepath.Join(root, "psg_kept_gen.go")
+	stale := snapshotPath(root, filepath.Join(root, "internal", "psg_old_gen.go"))
+	writeTestFile(t, stale, "package old\n")
+
+	merged := ds.Files{DestName: filepath.Join(root, "psg_merged_gen.go"), Code: bytes.NewBufferString("package x // edited\n" + testDisclaimer), Pristine: []byte("package x\n" + testDisclaimer)}
+
+	files := []ds.Files{
+		{DestName: kept, Code: bytes.NewBufferString("package kept\n" + testDisclaimer + "\nfunc user() {}\n")},
+		merged,
+	}
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if err := writeSnapshots(tx, root, files, emptyFilesDiff(), func(string) bool { return true }); err != nil {
+		t.Fatalf("writeSnapshots() error = %v", err)
+	}
+
+	if got := readTestFile(t, snapshotPath(root, kept)); got != "package kept\n"+testDisclaimer {
+		t.Errorf("snapshot = %q, want the generated part", got)
+	}
+
+	if got := readTestFile(t, snapshotPath(root, merged.DestName)); got != string(merged.Pristine) {
+		t.Errorf("snapshot of merged file = %q, want its Pristine", got)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale snapshot was not removed")
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readTestFile(t, stale); got != "package old\n" {
+		t.Errorf("stale snapshot was not restored by rollback: %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+)
+
+const orphanExt = ".orphan"
+
+// orphanFiles turns content adopted from files that could not be regenerated in place
+// into sidecar files next to them. An existing sidecar is never overwritten.
+func orphanFiles(filesDiff ds.FilesDiff) ([]ds.Files, error) {
+	paths := make([]string, 0, len(filesDiff.Orphans))
+	for path := range filesDiff.Orphans {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	files := make([]ds.Files, 0, len(paths))
+
+	for _, path := range paths {
+		sidecar, err := orphanSidecarPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, ds.Files{
+			OldDestName: path,
+			DestName:    sidecar,
+			Code:        bytes.NewBuffer(filesDiff.Orphans[path]),
+		})
+	}
+
+	return files, nil
+}
+
+// orphanSidecarPath returns the first free name of path.orphan, path.orphan.2, ...
+func orphanSidecarPath(path string) (string, error) {
+	sidecar := path + orphanExt
+
+	for i := 2; ; i++ {
+		_, err := os.Lstat(sidecar)
+		if os.IsNotExist(err) {
+			return sidecar, nil
+		}
+
+		if err != nil {
+			return "", errors.Wrapf(err, "Error check orphan file %s", sidecar)
+		}
+
+		sidecar = fmt.Sprintf("%s%s.%d", path, orphanExt, i)
+	}
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOrphanFiles(t *testing.T) {
+	root := t.TempDir()
+
+	first := filepath.Join(root, "psg_a_gen.go")
+	second := filepath.Join(root, "psg_b_gen.go")
+
+	// A sidecar left by a previous run is kept
+	writeTestFile(t, second+orphanExt, "old orphan\n")
+
+	filesDiff := emptyFilesDiff()
+	filesDiff.Orphans = map[string][]byte{
+		second: []byte("func b() {}\n"),
+		first:  []byte("func a() {}\n"),
+	}
+
+	got, err := orphanFiles(filesDiff)
+	if err != nil {
+		t.Fatalf("orphanFiles() error = %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("orphanFiles() returned %d files, want 2", len(got))
+	}
+
+	want := []struct{ from, to, code string }{
+		{first, first + ".orphan", "func a() {}\n"},
+		{second, second + ".orphan.2", "func b() {}\n"},
+	}
+
+	for i, w := range want {
+		if got[i].OldDestName != w.from || got[i].DestName != w.to || got[i].Code.String() != w.code {
+			t.Errorf("orphanFiles()[%d] = %s -> %s (%q), want %s -> %s (%q)",
+				i, got[i].OldDestName, got[i].DestName, got[i].Code.String(), w.from, w.to, w.code)
+		}
+	}
+}
+
+func TestOrphanSidecarPath_StatError(t *testing.T) {
+	root := t.TempDir()
+
+	// A regular file in place of the directory makes Lstat fail with ENOTDIR
+	parent := filepath.Join(root, "internal")
+	writeTestFile(t, parent, "not a directory\n")
+
+	if _, err := orphanSidecarPath(filepath.Join(parent, "psg_a_gen.go")); err == nil {
+		t.Error("orphanSidecarPath() error = nil, want error")
+	}
+}
@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cfg "github.com/Educentr/go-project-starter/internal/pkg/config"
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+	"github.com/pkg/errors"
+)
+
+// PluginProtocolVersion is the version of the plugin request and response format
+const PluginProtocolVersion = 1
+
+const (
+	defaultPluginTimeout = time.Minute
+	originPlugin         = "plugin"
+)
+
+// Plugin is an external generator from the plugins config section
+type Plugin struct {
+	Name    string
+	Command string // absolute path, or a name looked up in PATH
+	Args    []string
+	Dir     string // config directory, the plugin runs in it
+	Timeout time.Duration
+	Config  map[string]any
+}
+
+// pluginRequest is written to the plugin stdin.
+// Params is the model templates are executed with, so plugins see the same field names.
+type pluginRequest struct {
+	ProtocolVersion int                       `json:"protocol_version"`
+	Plugin          pluginInfo                `json:"plugin"`
+	TargetDir       string                    `json:"target_dir"`
+	Params          templater.GeneratorParams `json:"params"`
+}
+
+type pluginInfo struct {
+	Name   string         `json:"name"`
+	Config map[string]any `json:"config"`
+}
+
+// pluginResponse is read from the plugin stdout
+type pluginResponse struct {
+	ProtocolVersion int          `json:"protocol_version"`
+	Files           []pluginFile `json:"files"`
+
+	stderr string // messages the plugin wrote to stderr
+}
+
+// pluginFile is a file to generate. Path is relative to the target, Disclaimer is a comment style
+// (templater.CommentAuto by default). User code below the disclaimer is kept as in template files.
+type pluginFile struct {
+	Path       string `json:"path"`
+	Content    string `json:"content"`
+	Disclaimer string `json:"disclaimer"`
+}
+
+func newPlugin(plugin cfg.Plugin, configDir string) (Plugin, error) {
+	dir, err := filepath.Abs(configDir)
+	if err != nil {
+		return Plugin{}, errors.Wrap(err, "config dir")
+	}
+
+	command := plugin.Command
+	if strings.ContainsRune(command, '/') && !filepath.IsAbs(command) {
+		command = filepath.Join(dir, command)
+	}
+
+	timeout := defaultPluginTimeout
+	if plugin.Timeout != "" {
+		if timeout, err = time.ParseDuration(plugin.Timeout); err != nil {
+			return Plugin{}, errors.Wrapf(err, "plugin %s timeout", plugin.Name)
+		}
+	}
+
+	return Plugin{
+		Name:    plugin.Name,
+		Command: command,
+		Args:    plugin.Args,
+		Dir:     dir,
+		Timeout: timeout,
+		Config:  plugin.Config,
+	}, nil
+}
+
+// pluginFiles runs the plugins and returns the files they generate.
+// A plugin may not write over a file generated from a template or by another plugin.
+func (g *Generator) pluginFiles(targetPath string, files []ds.Files) ([]ds.Files, error) {
+	if len(g.Plugins) == 0 {
+		return nil, nil
+	}
+
+	producers := make(map[string]string, len(files))
+	for _, file := range files {
+		producers[file.DestName] = file.SourceName
+	}
+
+	params := g.GetTmplParams()
+	result := []ds.Files{}
+
+	for _, plugin := range g.Plugins {
+		resp, err := plugin.run(targetPath, params)
+		if err != nil {
+			return nil, errors.Wrapf(err, "plugin %s", plugin.Name)
+		}
+
+		if resp.stderr != "" {
+			g.log().Info("plugin output", "plugin", plugin.Name, "output", resp.stderr)
+		}
+
+		g.log().Debug("run plugin", "plugin", plugin.Name, "files", len(resp.Files))
+
+		for _, file := range resp.Files {
+			dest, err := pluginFilePath(targetPath, file.Path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "plugin %s", plugin.Name)
+			}
+
+			if producer, ex := producers[dest]; ex {
+				return nil, errors.Errorf("plugin %s: %s is already generated by %s", plugin.Name, file.Path, producer)
+			}
+
+			producers[dest] = originPlugin + " " + plugin.Name
+
+			result = append(result, ds.Files{
+				SourceName:   originPlugin + ":" + plugin.Name,
+				DestName:     dest,
+				OldDestName:  dest,
+				ParamsTmpl:   params,
+				Plugin:       plugin.Name,
+				Content:      []byte(file.Content),
+				CommentStyle: file.Disclaimer,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// run executes the plugin with the request on stdin and decodes its stdout.
+// Stderr of a successful run is logged, of a failed one is added to the error.
+func (p Plugin) run(targetPath string, params templater.GeneratorParams) (pluginResponse, error) {
+	request, err := json.Marshal(pluginRequest{
+		ProtocolVersion: PluginProtocolVersion,
+		Plugin:          pluginInfo{Name: p.Name, Config: p.Config},
+		TargetDir:       targetPath,
+		Params:          params,
+	})
+	if err != nil {
+		return pluginResponse{}, errors.Wrap(err, "encode request")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Dir = p.Dir
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = errors.Errorf("timed out after %s", p.Timeout)
+		}
+
+		return pluginResponse{}, fmt.Errorf("run %s: %w (output: %s)", p.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var resp pluginResponse
+
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&resp); err != nil {
+		return pluginResponse{}, errors.Wrap(err, "decode response")
+	}
+
+	if resp.ProtocolVersion != 0 && resp.ProtocolVersion != PluginProtocolVersion {
+		return pluginResponse{}, errors.Errorf("unsupported protocol version %d (expected %d)", resp.ProtocolVersion, PluginProtocolVersion)
+	}
+
+	resp.stderr = strings.TrimSpace(stderr.String())
+
+	return resp, nil
+}
+
+// pluginFilePath resolves a plugin file path in the target. Paths outside of the target and
+// in directories the generator keeps its own state in are refused.
+func pluginFilePath(targetPath, path string) (string, error) {
+	rel := filepath.FromSlash(path)
+	if path == "" || !filepath.IsLocal(rel) {
+		return "", errors.Errorf("file path %q must be relative and inside the target", path)
+	}
+
+	switch top, _, _ := strings.Cut(filepath.ToSlash(filepath.Clean(rel)), "/"); top {
+	case ".git", ".project-config":
+		return "", errors.Errorf("file path %q is in reserved directory %s", path, top)
+	}
+
+	return filepath.Join(targetPath, rel), nil
+}
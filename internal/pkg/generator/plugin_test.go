@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cfg "github.com/Educentr/go-project-starter/internal/pkg/config"
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+)
+
+// writeTestPlugin writes a shell script plugin to dir and returns it configured to run there
+func writeTestPlugin(t *testing.T, dir, name, script string) Plugin {
+	t.Helper()
+
+	command := filepath.Join(dir, name)
+	writeTestFile(t, command, "#!/bin/sh\n"+script)
+
+	if err := os.Chmod(command, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return Plugin{Name: name, Command: command, Dir: dir, Timeout: 10 * time.Second}
+}
+
+func TestNewPlugin(t *testing.T) {
+	configDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		plugin      cfg.Plugin
+		wantCommand string
+		wantTimeout time.Duration
+	}{
+		{
+			name:        "relative command is resolved from the config directory",
+			plugin:      cfg.Plugin{Name: "rpc", Command: "./plugins/rpc-gen", Timeout: "30s"},
+			wantCommand: filepath.Join(configDir, "plugins", "rpc-gen"),
+			wantTimeout: 30 * time.Second,
+		},
+		{
+			name:        "command name is looked up in PATH",
+			plugin:      cfg.Plugin{Name: "rpc", Command: "rpc-gen"},
+			wantCommand: "rpc-gen",
+			wantTimeout: defaultPluginTimeout,
+		},
+		{
+			name:        "absolute command",
+			plugin:      cfg.Plugin{Name: "rpc", Command: "/usr/local/bin/rpc-gen"},
+			wantCommand: "/usr/local/bin/rpc-gen",
+			wantTimeout: defaultPluginTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newPlugin(tt.plugin, configDir)
+			if err != nil {
+				t.Fatalf("newPlugin() error = %v", err)
+			}
+
+			if got.Command != tt.wantCommand || got.Timeout != tt.wantTimeout || got.Dir != configDir {
+				t.Errorf("newPlugin() = %s, %s in %s; want %s, %s in %s",
+					got.Command, got.Timeout, got.Dir, tt.wantCommand, tt.wantTimeout, configDir)
+			}
+		})
+	}
+}
+
+func TestPluginRun(t *testing.T) {
+	dir := t.TempDir()
+
+	// The plugin runs in its directory: the request is saved next to it
+	plugin := writeTestPlugin(t, dir, "rpc", `cat > request.json
+echo "plugin log line" >&2
+cat <<'EOF'
+{"protocol_version": 1, "files": [
+  {"path": "internal/app/transport/rpc/psg_rpc_gen.go", "content": "package rpc\n"},
+  {"path": "api/rpc.schema", "content": "schema\n", "disclaimer": "hash"}
+]}
+EOF
+`)
+	plugin.Config = map[string]any{"apps": []any{"api"}}
+
+	resp, err := plugin.run("/target", templater.GeneratorParams{ProjectName: "sctest"})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	want := []pluginFile{
+		{Path: "internal/app/transport/rpc/psg_rpc_gen.go", Content: "package rpc\n"},
+		{Path: "api/rpc.schema", Content: "schema\n", Disclaimer: "hash"},
+	}
+
+	if len(resp.Files) != len(want) {
+		t.Fatalf("run() returned %d files, want %d", len(resp.Files), len(want))
+	}
+
+	for i := range want {
+		if resp.Files[i] != want[i] {
+			t.Errorf("run() file %d = %+v, want %+v", i, resp.Files[i], want[i])
+		}
+	}
+
+	var request struct {
+		ProtocolVersion int `json:"protocol_version"`
+		Plugin          struct {
+			Name   string         `json:"name"`
+			Config map[string]any `json:"config"`
+		} `json:"plugin"`
+		TargetDir string `json:"target_dir"`
+		Params    struct {
+			ProjectName string
+		} `json:"params"`
+	}
+
+	if err := json.Unmarshal([]byte(readTestFile(t, filepath.Join(dir, "request.json"))), &request); err != nil {
+		t.Fatalf("request is not valid JSON: %v", err)
+	}
+
+	if request.ProtocolVersion != PluginProtocolVersion || request.Plugin.Name != "rpc" ||
+		request.TargetDir != "/target" || request.Params.ProjectName != "sctest" {
+		t.Errorf("request = %+v", request)
+	}
+
+	if apps, ok := request.Plugin.Config["apps"].([]any); !ok || len(apps) != 1 || apps[0] != "api" {
+		t.Errorf("request plugin config = %v, want apps: [api]", request.Plugin.Config)
+	}
+}
+
+func TestPluginRun_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		wantErr string
+	}{
+		{
+			name:    "non-zero exit reports stderr",
+			script:  "echo 'unknown transport rpc' >&2\nexit 3\n",
+			wantErr: "unknown transport rpc",
+		},
+		{
+			name:    "invalid JSON",
+			script:  "echo 'files:'\n",
+			wantErr: "decode response",
+		},
+		{
+			name:    "unknown response field",
+			script:  `echo '{"file": []}'` + "\n",
+			wantErr: `unknown field "file"`,
+		},
+		{
+			name:    "unsupported protocol version",
+			script:  `echo '{"protocol_version": 2, "files": []}'` + "\n",
+			wantErr: "unsupported protocol version 2",
+		},
+		{
+			name:    "timeout",
+			script:  "exec sleep 5\n",
+			timeout: 100 * time.Millisecond,
+			wantErr: "timed out after 100ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := writeTestPlugin(t, t.TempDir(), "rpc", tt.script)
+			if tt.timeout != 0 {
+				plugin.Timeout = tt.timeout
+			}
+
+			_, err := plugin.run("/target", templater.GeneratorParams{ProjectName: "sctest"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("run() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPluginFilePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "internal/rpc/psg_rpc_gen.go", want: "/target/internal/rpc/psg_rpc_gen.go"},
+		{path: "./api/../api/rpc.yaml", want: "/target/api/rpc.yaml"},
+		{path: "", wantErr: true},
+		{path: "/etc/passwd", wantErr: true},
+		{path: "../outside.go", wantErr: true},
+		{path: "api/../../outside.go", wantErr: true},
+		{path: ".git/config", wantErr: true},
+		{path: ".project-config/project.yaml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := pluginFilePath("/target", tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pluginFilePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("pluginFilePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluginFiles(t *testing.T) {
+	dir := t.TempDir()
+	response := `cat <<'EOF'
+{"files": [{"path": "internal/rpc/psg_rpc_gen.go", "content": "package rpc\n", "disclaimer": "slash"}]}
+EOF
+`
+
+	g := &Generator{ProjectName: "sctest", Plugins: []Plugin{writeTestPlugin(t, dir, "rpc", response)}}
+
+	files, err := g.pluginFiles("/target", []ds.Files{{SourceName: "embedded/templates/main/Makefile.tmpl", DestName: "/target/Makefile"}})
+	if err != nil {
+		t.Fatalf("pluginFiles() error = %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("pluginFiles() returned %d files, want 1", len(files))
+	}
+
+	got := files[0]
+	if got.DestName != "/target/internal/rpc/psg_rpc_gen.go" || got.OldDestName != got.DestName ||
+		got.Plugin != "rpc" || string(got.Content) != "package rpc\n" || got.CommentStyle != "slash" {
+		t.Errorf("pluginFiles() = %+v", got)
+	}
+
+	// A plugin may not take over a file generated from a template
+	_, err = g.pluginFiles("/target", []ds.Files{{SourceName: "embedded/templates/rpc.go.tmpl", DestName: "/target/internal/rpc/psg_rpc_gen.go"}})
+	if err == nil || !strings.Contains(err.Error(), "already generated by embedded/templates/rpc.go.tmpl") {
+		t.Errorf("pluginFiles() error = %v, want collision with the template", err)
+	}
+}
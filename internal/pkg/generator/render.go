@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+	"github.com/pkg/errors"
+)
+
+// renderFiles renders the code of files from tmpls over a pool of workers, one per CPU, with the user code
+// of filesDiff. All files are rendered and the error of the first failed file in files order
+// is returned, so the reported error does not depend on scheduling.
+func renderFiles(tmpls *templater.Templates, files []ds.Files, filesDiff ds.FilesDiff) error {
+	workers := min(runtime.GOMAXPROCS(0), len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				dest := files[i].DestName
+				errs[i] = renderFile(tmpls, &files[i], filesDiff.UserContent[dest], filesDiff.UserRegions[dest])
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func renderFile(tmpls *templater.Templates, file *ds.Files, userCode []byte, regions map[string]string) error {
+	var err error
+
+	if file.Plugin != "" {
+		file.Code, err = templater.GenerateByContent(file.Content, file.CommentStyle, file.ParamsTmpl, userCode, file.DestName)
+		if err != nil {
+			return errors.Wrapf(err, "Error generate %s by plugin %s", file.DestName, file.Plugin)
+		}
+	} else {
+		tmpl, err := tmpls.GetTemplate(file.SourceName)
+		if err != nil {
+			return fmt.Errorf("failed to get template %s: %w", file.SourceName, err)
+		}
+
+		file.Code, err = templater.GenerateByTmpl(tmpl, file.ParamsTmpl, userCode, file.DestName)
+		if err != nil {
+			return errors.Wrap(err, "Error generate")
+		}
+	}
+
+	if len(regions) == 0 {
+		return nil
+	}
+
+	code, err := templater.InjectRegions(file.Code.Bytes(), regions)
+	if err != nil {
+		return errors.Wrapf(err, "Error keep user code regions in %s", file.DestName)
+	}
+
+	file.Code = bytes.NewBuffer(code)
+
+	return nil
+}
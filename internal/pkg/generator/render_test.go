@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+)
+
+func embeddedTemplates(t *testing.T) *templater.Templates {
+	t.Helper()
+
+	tmpls, err := templater.NewTemplates(nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tmpls
+}
+
+func TestRenderFiles(t *testing.T) {
+	files := make([]ds.Files, 0, 32)
+
+	for i := range 32 {
+		name := string(rune('a'+i%26)) + ".go"
+		files = append(files, ds.Files{
+			DestName:     "/target/" + name,
+			Plugin:       "rpc",
+			Content:      []byte("package " + name[:1] + "\n"),
+			CommentStyle: "slash",
+		})
+	}
+
+	filesDiff := ds.FilesDiff{UserContent: map[string][]byte{"/target/b.go": []byte("\nfunc user() {}\n")}}
+
+	if err := renderFiles(embeddedTemplates(t), files, filesDiff); err != nil {
+		t.Fatalf("renderFiles() error = %v", err)
+	}
+
+	for _, file := range files {
+		code := file.Code.String()
+		if !strings.Contains(code, string(file.Content)) {
+			t.Errorf("%s: code %q does not contain the plugin content", file.DestName, code)
+		}
+
+		if hasUserCode := strings.HasSuffix(code, "func user() {}\n"); hasUserCode != (file.DestName == "/target/b.go") {
+			t.Errorf("%s: user code appended = %v", file.DestName, hasUserCode)
+		}
+	}
+}
+
+func TestRenderFiles_FirstError(t *testing.T) {
+	files := []ds.Files{
+		{DestName: "/target/a.go", Plugin: "rpc", Content: []byte("package a\n")},
+		{DestName: "/target/b.go", SourceName: "embedded/templates/missing/first.go.tmpl"},
+		{DestName: "/target/c.go", Plugin: "rpc", Content: []byte("package c\n")},
+		{DestName: "/target/d.go", SourceName: "embedded/templates/missing/second.go.tmpl"},
+	}
+
+	// The reported error does not depend on which worker fails first
+	for range 10 {
+		err := renderFiles(embeddedTemplates(t), files, ds.FilesDiff{})
+		if err == nil || !strings.Contains(err.Error(), "missing/first.go.tmpl") {
+			t.Fatalf("renderFiles() error = %v, want the error of missing/first.go.tmpl", err)
+		}
+	}
+}
+
+func TestRenderFiles_Regions(t *testing.T) {
+	files := []ds.Files{{
+		DestName:     "/target/a.go",
+		Plugin:       "rpc",
+		Content:      []byte("package a\n\n// psg:begin:funcs\n// psg:end:funcs\n"),
+		CommentStyle: "slash",
+	}}
+
+	filesDiff := ds.FilesDiff{UserRegions: map[string]map[string]string{"/target/a.go": {"funcs": "func user() {}\n"}}}
+
+	if err := renderFiles(embeddedTemplates(t), files, filesDiff); err != nil {
+		t.Fatalf("renderFiles() error = %v", err)
+	}
+
+	if code := files[0].Code.String(); !strings.Contains(code, "// psg:begin:funcs\nfunc user() {}\n// psg:end:funcs\n") {
+		t.Errorf("region code is not kept: %q", code)
+	}
+
+	filesDiff.UserRegions["/target/a.go"] = map[string]string{"removed": "func user() {}\n"}
+
+	if err := renderFiles(embeddedTemplates(t), files, filesDiff); err == nil || !strings.Contains(err.Error(), "/target/a.go") {
+		t.Errorf("renderFiles() error = %v, want an error about the lost region", err)
+	}
+}
@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/migrate"
+)
+
+// ReportFormatJSON is the only --report format
+const ReportFormatJSON = "json"
+
+// Report describes a generation for CI: the changed files, the user code kept in them,
+// the post_generate steps and the warnings. Paths are relative to the target.
+// A dry run reports what the generation would do.
+type Report struct {
+	Target string `json:"target"`
+	DryRun bool   `json:"dry_run"`
+	Changes
+	Ignored      []string        `json:"ignored"`   // files the generator never overwrites
+	Preserved    []PreservedFile `json:"preserved"` // regenerated files with user code
+	Merged       []string        `json:"merged"`    // hand edits merged with the new generation
+	Conflicts    []string        `json:"conflicts"` // hand edits written with conflict markers
+	PostGenerate []StepResult    `json:"post_generate"`
+	Warnings     []string        `json:"warnings"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// PreservedFile is a regenerated file with user code below the disclaimer or in named regions
+type PreservedFile struct {
+	Path          string   `json:"path"`
+	UserCodeBytes int      `json:"user_code_bytes"`
+	Regions       []string `json:"regions,omitempty"`
+}
+
+// StepResult is a post_generate command run
+type StepResult struct {
+	Step       string `json:"step"`
+	Command    string `json:"command"`
+	DurationMs int64  `json:"duration_ms"`
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// newReport describes the rendered files before they are applied
+func (g *Generator) newReport(targetPath string, files []ds.Files, filesDiff ds.FilesDiff, orphans []ds.Files, merge mergeResult, changes Changes) Report {
+	r := Report{
+		Target:    targetPath,
+		DryRun:    g.DryRun,
+		Changes:   changes,
+		Merged:    relPaths(targetPath, merge.Merged),
+		Conflicts: relPaths(targetPath, merge.Conflicts),
+	}
+
+	for _, file := range files {
+		if _, ex := filesDiff.IgnoreFiles[file.DestName]; ex {
+			r.Ignored = append(r.Ignored, relPath(targetPath, file.DestName))
+
+			continue
+		}
+
+		userCode := filesDiff.UserContent[file.DestName]
+		regions := filesDiff.UserRegions[file.DestName]
+
+		if len(userCode) == 0 && len(regions) == 0 {
+			continue
+		}
+
+		preserved := PreservedFile{Path: relPath(targetPath, file.DestName), UserCodeBytes: len(userCode)}
+		for id := range regions {
+			preserved.Regions = append(preserved.Regions, id)
+		}
+
+		sort.Strings(preserved.Regions)
+		r.Preserved = append(r.Preserved, preserved)
+	}
+
+	sort.Strings(r.Ignored)
+	sort.Slice(r.Preserved, func(i, j int) bool { return r.Preserved[i].Path < r.Preserved[j].Path })
+
+	for _, d := range g.ConfigDeprecations {
+		for _, w := range d.Warnings {
+			r.Warnings = append(r.Warnings, deprecationWarning(d.File, w))
+		}
+	}
+
+	// The error of the config paths fails the generation itself
+	if copyConfig, err := g.copiesConfig(targetPath); err == nil && copyConfig {
+		configDir := filepath.Dir(g.ConfigPath)
+
+		for _, include := range g.ConfigIncludes {
+			if _, ok := includeRelPath(configDir, include); !ok {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("included config %s is outside of the config directory %s, not copied",
+					include, configDir))
+			}
+		}
+	}
+
+	for _, orphan := range orphans {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("content of %s adopted to %s",
+			relPath(targetPath, orphan.OldDestName), relPath(targetPath, orphan.DestName)))
+	}
+
+	if g.Force {
+		for _, path := range merge.Unmerged {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("hand edits of %s overwritten, a copy is kept in %s",
+				relPath(targetPath, path), projectBackupDir))
+		}
+	}
+
+	return r
+}
+
+// deprecationWarning describes a use of a deprecated feature in a file of the config
+func deprecationWarning(file string, w migrate.DeprecationWarning) string {
+	if w.Line > 0 {
+		file = fmt.Sprintf("%s:%d", file, w.Line)
+	}
+
+	return fmt.Sprintf("%s: deprecated %s, removed in %s: %s", file, w.Feature, w.RemovalVer, w.MigrationHint)
+}
+
+// WriteJSON writes the report as indented JSON, empty lists as [] rather than null
+func (r Report) WriteJSON(w io.Writer) error {
+	for _, list := range []*[]string{&r.Created, &r.Updated, &r.Removed, &r.Ignored, &r.Merged, &r.Conflicts, &r.Warnings} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+
+	if r.Renamed == nil {
+		r.Renamed = []FileRename{}
+	}
+
+	if r.Preserved == nil {
+		r.Preserved = []PreservedFile{}
+	}
+
+	if r.PostGenerate == nil {
+		r.PostGenerate = []StepResult{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
+
+func relPaths(targetPath string, paths []string) []string {
+	rels := make([]string, 0, len(paths))
+	for _, path := range paths {
+		rels = append(rels, relPath(targetPath, path))
+	}
+
+	return rels
+}
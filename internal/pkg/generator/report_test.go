@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	cfg "github.com/Educentr/go-project-starter/internal/pkg/config"
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/migrate"
+)
+
+func TestGenerator_NewReport(t *testing.T) {
+	root := t.TempDir()
+
+	files := []ds.Files{
+		{DestName: filepath.Join(root, "Makefile")},
+		{DestName: filepath.Join(root, "b.go")},
+		{DestName: filepath.Join(root, "a.go")},
+		{DestName: filepath.Join(root, "plain.go")},
+	}
+
+	filesDiff := emptyFilesDiff()
+	filesDiff.IgnoreFiles[filepath.Join(root, "Makefile")] = struct{}{}
+	filesDiff.UserContent = map[string][]byte{filepath.Join(root, "a.go"): []byte("func a() {}\n")}
+	filesDiff.UserRegions = map[string]map[string]string{
+		filepath.Join(root, "b.go"): {"imports": "", "handlers": "x"},
+	}
+	orphans := []ds.Files{{OldDestName: filepath.Join(root, "old.go"), DestName: filepath.Join(root, "old.go.orphan")}}
+
+	merge := mergeResult{
+		Merged:   []string{filepath.Join(root, "merged.go")},
+		Unmerged: []string{filepath.Join(root, "edited.go")},
+	}
+
+	configDir := filepath.Join(root, "config")
+
+	g := &Generator{
+		DryRun:         true,
+		Force:          true,
+		ConfigPath:     filepath.Join(configDir, "project.yaml"),
+		ConfigIncludes: []string{filepath.Join(configDir, "cli.yaml"), filepath.Join(root, "shared", "kafka.yaml")},
+		ConfigDeprecations: []cfg.Deprecations{{
+			File: filepath.Join(configDir, "project.yaml"),
+			Warnings: []migrate.DeprecationWarning{{
+				Feature:       "empty_config_available",
+				RemovalVer:    "0.13.0",
+				MigrationHint: "Use `optional: true` in application transport config",
+				Line:          7,
+			}},
+		}},
+	}
+	r := g.newReport(root, files, filesDiff, orphans, merge, Changes{Created: []string{"a.go"}})
+
+	if r.Target != root || !r.DryRun || !reflect.DeepEqual(r.Created, []string{"a.go"}) {
+		t.Errorf("newReport() = %+v", r)
+	}
+
+	if !reflect.DeepEqual(r.Ignored, []string{"Makefile"}) {
+		t.Errorf("Ignored = %v", r.Ignored)
+	}
+
+	wantPreserved := []PreservedFile{
+		{Path: "a.go", UserCodeBytes: len("func a() {}\n")},
+		{Path: "b.go", Regions: []string{"handlers", "imports"}},
+	}
+	if !reflect.DeepEqual(r.Preserved, wantPreserved) {
+		t.Errorf("Preserved = %+v, want %+v", r.Preserved, wantPreserved)
+	}
+
+	if !reflect.DeepEqual(r.Merged, []string{"merged.go"}) {
+		t.Errorf("Merged = %v", r.Merged)
+	}
+
+	wantWarnings := []string{
+		filepath.Join(configDir, "project.yaml") + ":7: deprecated empty_config_available, removed in 0.13.0: " +
+			"Use `optional: true` in application transport config",
+		"included config " + filepath.Join(root, "shared", "kafka.yaml") + " is outside of the config directory " +
+			configDir + ", not copied",
+		"content of old.go adopted to old.go.orphan",
+		"hand edits of edited.go overwritten, a copy is kept in " + projectBackupDir,
+	}
+	if !reflect.DeepEqual(r.Warnings, wantWarnings) {
+		t.Errorf("Warnings = %v, want %v", r.Warnings, wantWarnings)
+	}
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	r := Report{
+		Target:  "/project",
+		Changes: Changes{Renamed: []FileRename{{From: "old.go", To: "new.go"}}},
+		PostGenerate: []StepResult{
+			{Step: "tidy", Command: "go mod tidy", DurationMs: 12},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	if strings.Contains(buf.String(), "null") {
+		t.Errorf("WriteJSON() wrote null lists:\n%s", buf.String())
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+
+	for _, key := range []string{"created", "updated", "renamed", "removed", "ignored", "preserved", "merged", "conflicts", "post_generate", "warnings"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("WriteJSON() has no %q", key)
+		}
+	}
+
+	if _, ok := got["error"]; ok {
+		t.Error("WriteJSON() wrote empty error")
+	}
+
+	renamed := got["renamed"].([]any)[0].(map[string]any)
+	if renamed["from"] != "old.go" || renamed["to"] != "new.go" {
+		t.Errorf("renamed = %v", renamed)
+	}
+}
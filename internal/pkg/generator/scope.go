@@ -0,0 +1,249 @@
+package generator
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/pkg/errors"
+)
+
+// Filter kinds of --only
+const (
+	ScopeApp       = "app"
+	ScopeTransport = "transport"
+	ScopePath      = "path"
+)
+
+// ScopeFilter selects files for a selective regeneration: the files of an application,
+// of a transport, or the files matching a glob relative to the target
+type ScopeFilter struct {
+	Kind  string
+	Value string
+}
+
+func (f ScopeFilter) String() string {
+	return f.Kind + "=" + f.Value
+}
+
+// ParseScopeFilter parses an --only value: app=<name>, transport=<name> or path=<glob>
+func ParseScopeFilter(value string) (ScopeFilter, error) {
+	kind, val, ok := strings.Cut(value, "=")
+	if !ok || val == "" {
+		return ScopeFilter{}, errors.Errorf("invalid filter %q (expected app=<name>, transport=<name> or path=<glob>)", value)
+	}
+
+	switch kind {
+	case ScopeApp, ScopeTransport:
+	case ScopePath:
+		if _, err := path.Match(val, ""); err != nil {
+			return ScopeFilter{}, errors.Wrapf(err, "invalid filter %q", value)
+		}
+	default:
+		return ScopeFilter{}, errors.Errorf("unknown filter %q (expected app, transport or path)", kind)
+	}
+
+	return ScopeFilter{Kind: kind, Value: val}, nil
+}
+
+// match reports whether a generated file is selected by the filter
+func (f ScopeFilter) match(targetPath string, file ds.Files) bool {
+	switch f.Kind {
+	case ScopeApp:
+		return file.App == f.Value
+	case ScopeTransport:
+		return file.Transport == f.Value
+	default:
+		return f.matchPath(targetPath, file.DestName)
+	}
+}
+
+// matchPath matches the glob against the path relative to the target and its parent directories,
+// so a directory selects everything in it
+func (f ScopeFilter) matchPath(targetPath, p string) bool {
+	if f.Kind != ScopePath {
+		return false
+	}
+
+	for rel := filepath.ToSlash(relPath(targetPath, p)); rel != "." && rel != "/"; rel = path.Dir(rel) {
+		if ok, _ := path.Match(f.Value, rel); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scope is the part of the target a selective regeneration may change. A nil scope is the whole target.
+type scope struct {
+	targetPath string
+	filters    []ScopeFilter
+	files      map[string]struct{} // selected files by new and old name
+	ownDirs    map[string]bool     // directories with generated files: true if all of them are selected
+}
+
+// contains reports whether a target path belongs to the scope: a selected file, a file matching
+// a path filter, or a file in a directory only selected files are generated into. The last two
+// let stale generated files of the scope be removed.
+func (s *scope) contains(p string) bool {
+	if s == nil {
+		return true
+	}
+
+	if _, ok := s.files[p]; ok {
+		return true
+	}
+
+	for _, f := range s.filters {
+		if f.matchPath(s.targetPath, p) {
+			return true
+		}
+	}
+
+	return s.ownDirs[filepath.Dir(p)]
+}
+
+// keepChecksums merges the checksums of a regeneration into the recorded ones:
+// the recorded checksums of files out of the scope are kept
+func (s *scope) keepChecksums(targetPath string, recorded, generated map[string]string) map[string]string {
+	if s == nil {
+		return generated
+	}
+
+	for rel, checksum := range recorded {
+		if _, ok := generated[rel]; !ok && !s.contains(filepath.Join(targetPath, filepath.FromSlash(rel))) {
+			generated[rel] = checksum
+		}
+	}
+
+	return generated
+}
+
+// selectScope narrows files and directories to the ones selected by g.Only and sets g.scope.
+// Without filters everything is selected.
+func (g *Generator) selectScope(targetPath string, dirs, files []ds.Files) ([]ds.Files, []ds.Files, error) {
+	g.scope = nil
+
+	if len(g.Only) == 0 {
+		return dirs, files, nil
+	}
+
+	if err := g.checkScopeFilters(); err != nil {
+		return nil, nil, err
+	}
+
+	s := &scope{
+		targetPath: targetPath,
+		filters:    g.Only,
+		files:      make(map[string]struct{}),
+		ownDirs:    make(map[string]bool),
+	}
+
+	selected := []ds.Files{}
+
+	for _, file := range files {
+		ok := false
+
+		for _, f := range g.Only {
+			if f.match(targetPath, file) {
+				ok = true
+
+				break
+			}
+		}
+
+		dir := filepath.Dir(file.DestName)
+		if own, seen := s.ownDirs[dir]; seen {
+			s.ownDirs[dir] = own && ok
+		} else {
+			s.ownDirs[dir] = ok
+		}
+
+		if !ok {
+			continue
+		}
+
+		s.files[file.DestName] = struct{}{}
+		s.files[file.OldDestName] = struct{}{}
+		selected = append(selected, file)
+	}
+
+	if len(selected) == 0 {
+		return nil, nil, errors.Errorf("--only %s selects no files", scopeFiltersString(g.Only))
+	}
+
+	// Only the directories selected files are generated into
+	selectedDirs := []ds.Files{}
+
+	for _, dir := range dirs {
+		prefix := filepath.Clean(dir.DestName) + string(filepath.Separator)
+
+		for _, file := range selected {
+			if strings.HasPrefix(file.DestName, prefix) {
+				selectedDirs = append(selectedDirs, dir)
+
+				break
+			}
+		}
+	}
+
+	g.scope = s
+
+	return selectedDirs, selected, nil
+}
+
+// checkScopeFilters reports filters naming an application or a transport missing in the config
+func (g *Generator) checkScopeFilters() error {
+	for _, f := range g.Only {
+		switch f.Kind {
+		case ScopeApp:
+			found := false
+
+			for _, app := range g.Applications {
+				if app.Name == f.Value {
+					found = true
+
+					break
+				}
+			}
+
+			if !found {
+				return errors.Errorf("--only %s: unknown application %s", f, f.Value)
+			}
+		case ScopeTransport:
+			if _, ok := g.Transports[f.Value]; !ok {
+				return errors.Errorf("--only %s: unknown transport %s", f, f.Value)
+			}
+		}
+	}
+
+	return nil
+}
+
+func scopeFiltersString(filters []ScopeFilter) string {
+	values := make([]string, 0, len(filters))
+	for _, f := range filters {
+		values = append(values, f.String())
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// forApp marks files as generated for an application
+func forApp(files []ds.Files, app string) []ds.Files {
+	for i := range files {
+		files[i].App = app
+	}
+
+	return files
+}
+
+// forTransport marks files as generated for a transport
+func forTransport(files []ds.Files, transport string) []ds.Files {
+	for i := range files {
+		files[i].Transport = transport
+	}
+
+	return files
+}
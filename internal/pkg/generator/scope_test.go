@@ -0,0 +1,196 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+)
+
+func TestParseScopeFilter(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ScopeFilter
+		wantErr bool
+	}{
+		{value: "app=api", want: ScopeFilter{Kind: ScopeApp, Value: "api"}},
+		{value: "transport=sys", want: ScopeFilter{Kind: ScopeTransport, Value: "sys"}},
+		{value: "path=internal/app/*", want: ScopeFilter{Kind: ScopePath, Value: "internal/app/*"}},
+		{value: "api", wantErr: true},
+		{value: "app=", wantErr: true},
+		{value: "worker=sender", wantErr: true},
+		{value: "path=[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseScopeFilter(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseScopeFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseScopeFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerator_SelectScope(t *testing.T) {
+	root := t.TempDir()
+	p := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+
+	dirs := []ds.Files{{DestName: p("cmd/api")}, {DestName: p("cmd/admin")}, {DestName: p("configs")}}
+	files := []ds.Files{
+		{DestName: p("cmd/api/psg_main_gen.go"), OldDestName: p("cmd/api/psg_main_gen.go"), App: "api"},
+		{DestName: p("cmd/admin/psg_main_gen.go"), OldDestName: p("cmd/admin/psg_main_gen.go"), App: "admin"},
+		{DestName: p("internal/app/transport/sys/psg_handler_gen.go"), OldDestName: p("internal/app/transport/sys/psg_handler_gen.go"), Transport: "sys"},
+		{DestName: p("configs/golangci-lint.yml"), OldDestName: p("configs/golangci-lint.yml")},
+		{DestName: p("psg_root_gen.go"), OldDestName: p("psg_root_gen.go")},
+	}
+
+	g := &Generator{
+		Applications: ds.Apps{{Name: "api"}, {Name: "admin"}},
+		Transports:   ds.Transports{"sys": {}},
+	}
+
+	tests := []struct {
+		name        string
+		only        []ScopeFilter
+		wantFiles   []string
+		wantDirs    []string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "application",
+			only:        []ScopeFilter{{Kind: ScopeApp, Value: "api"}},
+			wantFiles:   []string{"cmd/api/psg_main_gen.go"},
+			wantDirs:    []string{"cmd/api"},
+			contains:    []string{"cmd/api/psg_stale_gen.go"},
+			notContains: []string{"cmd/admin/psg_main_gen.go", "psg_stale_gen.go", "configs/golangci-lint.yml"},
+		},
+		{
+			name:        "transport",
+			only:        []ScopeFilter{{Kind: ScopeTransport, Value: "sys"}},
+			wantFiles:   []string{"internal/app/transport/sys/psg_handler_gen.go"},
+			contains:    []string{"internal/app/transport/sys/psg_old_gen.go"},
+			notContains: []string{"cmd/api/psg_main_gen.go"},
+		},
+		{
+			name:        "path",
+			only:        []ScopeFilter{{Kind: ScopePath, Value: "configs"}},
+			wantFiles:   []string{"configs/golangci-lint.yml"},
+			wantDirs:    []string{"configs"},
+			contains:    []string{"configs/deleted.yml"},
+			notContains: []string{"psg_root_gen.go"},
+		},
+		{
+			name:        "any filter selects",
+			only:        []ScopeFilter{{Kind: ScopeApp, Value: "admin"}, {Kind: ScopePath, Value: "psg_*_gen.go"}},
+			wantFiles:   []string{"cmd/admin/psg_main_gen.go", "psg_root_gen.go"},
+			wantDirs:    []string{"cmd/admin"},
+			contains:    []string{"psg_stale_gen.go"},
+			notContains: []string{"cmd/api/psg_main_gen.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g.Only = tt.only
+
+			gotDirs, gotFiles, err := g.selectScope(root, dirs, files)
+			if err != nil {
+				t.Fatalf("selectScope() error = %v", err)
+			}
+
+			if got := relNames(root, gotFiles); strings.Join(got, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("selectScope() files = %v, want %v", got, tt.wantFiles)
+			}
+
+			if got := relNames(root, gotDirs); strings.Join(got, ",") != strings.Join(tt.wantDirs, ",") {
+				t.Errorf("selectScope() dirs = %v, want %v", got, tt.wantDirs)
+			}
+
+			for _, rel := range tt.contains {
+				if !g.scope.contains(p(rel)) {
+					t.Errorf("scope does not contain %s", rel)
+				}
+			}
+
+			for _, rel := range tt.notContains {
+				if g.scope.contains(p(rel)) {
+					t.Errorf("scope contains %s", rel)
+				}
+			}
+		})
+	}
+
+	t.Run("without filters", func(t *testing.T) {
+		g.Only = nil
+
+		_, gotFiles, err := g.selectScope(root, dirs, files)
+		if err != nil {
+			t.Fatalf("selectScope() error = %v", err)
+		}
+
+		if len(gotFiles) != len(files) || !g.scope.contains(p("anything")) {
+			t.Errorf("selectScope() without filters narrowed the scope")
+		}
+	})
+
+	for _, only := range [][]ScopeFilter{
+		{{Kind: ScopeApp, Value: "unknown"}},
+		{{Kind: ScopeTransport, Value: "unknown"}},
+		{{Kind: ScopePath, Value: "nothing/*"}},
+	} {
+		g.Only = only
+
+		if _, _, err := g.selectScope(root, dirs, files); err == nil {
+			t.Errorf("selectScope(%s) error = nil, want error", scopeFiltersString(only))
+		}
+	}
+}
+
+func TestScope_KeepChecksums(t *testing.T) {
+	root := t.TempDir()
+
+	s := &scope{
+		targetPath: root,
+		files:      map[string]struct{}{filepath.Join(root, "cmd", "api", "psg_main_gen.go"): {}},
+		ownDirs:    map[string]bool{filepath.Join(root, "cmd", "api"): true},
+	}
+
+	recorded := map[string]string{
+		"cmd/api/psg_main_gen.go":   "old",
+		"cmd/api/psg_stale_gen.go":  "stale",
+		"cmd/admin/psg_main_gen.go": "admin",
+	}
+	generated := map[string]string{"cmd/api/psg_main_gen.go": "new"}
+
+	got := s.keepChecksums(root, recorded, generated)
+	want := map[string]string{
+		"cmd/api/psg_main_gen.go":   "new",
+		"cmd/admin/psg_main_gen.go": "admin",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("keepChecksums() = %v, want %v", got, want)
+	}
+
+	for rel, checksum := range want {
+		if got[rel] != checksum {
+			t.Errorf("keepChecksums()[%s] = %q, want %q", rel, got[rel], checksum)
+		}
+	}
+}
+
+func relNames(root string, files []ds.Files) []string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, filepath.ToSlash(relPath(root, file.DestName)))
+	}
+
+	return names
+}
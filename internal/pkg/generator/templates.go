@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// ListTemplates writes which template, embedded or from the overlay, or which plugin produces each output file.
+// Overlay files that produce nothing, e.g. because of a wrong path, are listed after the table.
+func (g *Generator) ListTemplates(w io.Writer) error {
+	targetPath, err := filepath.Abs(g.TargetDir)
+	if err != nil {
+		return errors.Wrap(err, "Error target path")
+	}
+
+	_, files, err := g.collectFiles(targetPath)
+	if err != nil {
+		return errors.Wrap(err, "Error collect files")
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].DestName < files[j].DestName })
+
+	used := make(map[string]struct{}, len(files))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "OUTPUT\tORIGIN\tTEMPLATE")
+
+	for _, file := range files {
+		used[file.SourceName] = struct{}{}
+		origin, source := g.templates.TemplateOrigin(file.SourceName)
+		if file.Plugin != "" {
+			origin, source = originPlugin, file.Plugin
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", filepath.ToSlash(relPath(targetPath, file.DestName)), origin, source)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	overlayTemplates, err := g.templates.OverlayTemplates()
+	if err != nil {
+		return err
+	}
+
+	unused := []string{}
+
+	for _, name := range overlayTemplates {
+		if _, ok := used[name]; !ok {
+			_, source := g.templates.TemplateOrigin(name)
+			unused = append(unused, source)
+		}
+	}
+
+	if len(unused) > 0 {
+		fmt.Fprintf(w, "\nUnused overlay templates (path does not match a generated template directory):\n")
+
+		for _, source := range unused {
+			fmt.Fprintf(w, "  %s\n", source)
+		}
+	}
+
+	return nil
+}
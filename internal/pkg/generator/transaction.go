@@ -0,0 +1,480 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+	"github.com/Educentr/go-project-starter/internal/pkg/templater"
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"github.com/pkg/errors"
+)
+
+const (
+	stageDirPattern  = templater.StageDirPrefix
+	backupDirPattern = "psg-backup-"
+	newFilePerm      = 0666
+)
+
+// stagedFile is a rendered file waiting in the stage directory
+type stagedFile struct {
+	dest   string
+	staged string
+}
+
+// touchedPath is a path changed by the transaction.
+// backup is empty if the path did not exist before the change.
+type touchedPath struct {
+	path   string
+	backup string
+	mode   os.FileMode
+}
+
+// fsTransaction applies a regeneration to the target tree as a single unit.
+//
+// Rendered files are first written to a stage directory inside the target (same filesystem,
+// so moving them in is a rename) and validated. Every path is snapshotted before it is
+// changed, so Rollback restores the tree if applying or a post_generate step fails.
+type fsTransaction struct {
+	root      string
+	stageDir  string
+	backupDir string
+
+	staged      []stagedFile
+	stagedDests map[string]struct{}
+	unchecked   map[string]struct{}
+	touched     []touchedPath
+	seen        map[string]struct{}
+	createdDirs []string
+	keepBackup  bool
+	existing    map[string]struct{} // paths of the tree recorded by TrackNew, nil if not tracked
+	log         *slog.Logger        // slog.Default() unless set by the generator
+}
+
+func newFSTransaction(root string) (*fsTransaction, error) {
+	if err := os.MkdirAll(root, tools.DefaultDirPerm); err != nil {
+		return nil, errors.Wrap(err, "create target directory")
+	}
+
+	if err := removeStaleStageDirs(root); err != nil {
+		return nil, err
+	}
+
+	stageDir, err := os.MkdirTemp(root, stageDirPattern)
+	if err != nil {
+		return nil, errors.Wrap(err, "create stage directory")
+	}
+
+	backupDir, err := os.MkdirTemp("", backupDirPattern)
+	if err != nil {
+		os.RemoveAll(stageDir)
+
+		return nil, errors.Wrap(err, "create backup directory")
+	}
+
+	return &fsTransaction{
+		root:        root,
+		stageDir:    stageDir,
+		backupDir:   backupDir,
+		stagedDests: make(map[string]struct{}),
+		unchecked:   make(map[string]struct{}),
+		seen:        make(map[string]struct{}),
+		log:         slog.Default(),
+	}, nil
+}
+
+// removeStaleStageDirs removes the stage directories left in root by runs killed before Close
+func removeStaleStageDirs(root string) error {
+	stale, err := filepath.Glob(filepath.Join(root, stageDirPattern+"*"))
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range stale {
+		if err := os.RemoveAll(dir); err != nil {
+			return errors.Wrap(err, "remove stale stage directory")
+		}
+	}
+
+	return nil
+}
+
+// Stage writes the content of dest into the stage directory. The target tree is not touched.
+// Staging the same dest again replaces its content, e.g. files shared by several workers.
+func (tx *fsTransaction) Stage(dest string, code *bytes.Buffer) error {
+	rel, err := filepath.Rel(tx.root, dest)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("file %s is outside of target directory %s", dest, tx.root)
+	}
+
+	staged := filepath.Join(tx.stageDir, rel)
+
+	if err := os.MkdirAll(filepath.Dir(staged), tools.DefaultDirPerm); err != nil {
+		return errors.Wrap(err, "create stage subdirectory")
+	}
+
+	if err := os.WriteFile(staged, code.Bytes(), newFilePerm); err != nil {
+		return errors.Wrapf(err, "stage file %s", rel)
+	}
+
+	// Keep the mode of an existing file, as rewriting it in place would
+	if st, err := os.Stat(dest); err == nil {
+		if err := os.Chmod(staged, st.Mode().Perm()); err != nil {
+			return errors.Wrapf(err, "stage file %s", rel)
+		}
+	}
+
+	if _, ok := tx.stagedDests[dest]; ok {
+		return nil
+	}
+
+	tx.stagedDests[dest] = struct{}{}
+	tx.staged = append(tx.staged, stagedFile{dest: dest, staged: staged})
+
+	return nil
+}
+
+// SkipValidation excludes a staged dest from Validate
+func (tx *fsTransaction) SkipValidation(dest string) {
+	tx.unchecked[dest] = struct{}{}
+}
+
+// Validate checks staged files before anything is written to the target tree.
+// Go files must parse, otherwise a broken template would replace working code.
+func (tx *fsTransaction) Validate() error {
+	fset := token.NewFileSet()
+
+	for _, f := range tx.staged {
+		if _, ok := tx.unchecked[f.dest]; ok || filepath.Ext(f.dest) != ".go" {
+			continue
+		}
+
+		if _, err := parser.ParseFile(fset, f.staged, nil, parser.SkipObjectResolution); err != nil {
+			rel, _ := filepath.Rel(tx.root, f.dest)
+
+			return errors.Wrapf(err, "generated file %s is not valid Go", rel)
+		}
+	}
+
+	return nil
+}
+
+// MakeDirs creates directories and remembers the ones that did not exist
+func (tx *fsTransaction) MakeDirs(dirs []ds.Files) error {
+	for _, dir := range dirs {
+		if err := tx.mkdirAll(dir.DestName, 0700); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tx *fsTransaction) mkdirAll(dir string, perm os.FileMode) error {
+	for p := dir; ; p = filepath.Dir(p) {
+		if _, err := os.Lstat(p); err == nil || p == filepath.Dir(p) {
+			break
+		}
+
+		tx.createdDirs = append(tx.createdDirs, p)
+	}
+
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	return nil
+}
+
+// Snapshot records the current state of path so Rollback can restore it.
+// Only the first snapshot of a path counts.
+func (tx *fsTransaction) Snapshot(path string) error {
+	if _, ok := tx.seen[path]; ok {
+		return nil
+	}
+
+	st, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		tx.seen[path] = struct{}{}
+		tx.touched = append(tx.touched, touchedPath{path: path})
+
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "snapshot %s", path)
+	}
+
+	if !st.Mode().IsRegular() {
+		return errors.Errorf("snapshot %s: not a regular file", path)
+	}
+
+	backup := filepath.Join(tx.backupDir, strconv.Itoa(len(tx.touched)))
+
+	if err := copyFileMode(path, backup, st.Mode().Perm()); err != nil {
+		return errors.Wrapf(err, "snapshot %s", path)
+	}
+
+	tx.seen[path] = struct{}{}
+	tx.touched = append(tx.touched, touchedPath{path: path, backup: backup, mode: st.Mode().Perm()})
+
+	return nil
+}
+
+// Rename moves oldPath to newPath
+func (tx *fsTransaction) Rename(oldPath, newPath string) error {
+	if err := tx.Snapshot(oldPath); err != nil {
+		return err
+	}
+
+	if err := tx.Snapshot(newPath); err != nil {
+		return err
+	}
+
+	return os.Rename(oldPath, newPath)
+}
+
+// Remove deletes path
+func (tx *fsTransaction) Remove(path string) error {
+	if err := tx.Snapshot(path); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// CopyFile copies src over dst
+func (tx *fsTransaction) CopyFile(src, dst string) error {
+	if err := tx.mkdirAll(filepath.Dir(dst), tools.DefaultDirPerm); err != nil {
+		return err
+	}
+
+	if err := tx.Snapshot(dst); err != nil {
+		return err
+	}
+
+	return tools.CopyFile(src, dst)
+}
+
+// WriteFile writes data to path in place, for files that are not validated as generated code
+func (tx *fsTransaction) WriteFile(path string, data []byte) error {
+	if err := tx.mkdirAll(filepath.Dir(path), tools.DefaultDirPerm); err != nil {
+		return err
+	}
+
+	if err := tx.Snapshot(path); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, newFilePerm)
+}
+
+// Apply moves staged files into the target tree
+func (tx *fsTransaction) Apply() error {
+	for _, f := range tx.staged {
+		if err := tx.mkdirAll(filepath.Dir(f.dest), tools.DefaultDirPerm); err != nil {
+			return err
+		}
+
+		if err := tx.Snapshot(f.dest); err != nil {
+			return err
+		}
+
+		if err := os.Rename(f.staged, f.dest); err != nil {
+			return errors.Wrapf(err, "move %s into place", f.dest)
+		}
+	}
+
+	tx.staged = nil
+
+	return os.RemoveAll(tx.stageDir)
+}
+
+// TrackNew records the paths of the tree, Rollback removes the paths created after it: the output
+// of post_generate steps. A .git directory created after it is removed too; objects written into
+// a repository that existed are kept, removing them could break it.
+func (tx *fsTransaction) TrackNew() error {
+	tx.existing = make(map[string]struct{})
+
+	return filepath.WalkDir(tx.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		tx.existing[path] = struct{}{}
+
+		if d.IsDir() && path == filepath.Join(tx.root, ".git") {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+}
+
+// removeNew removes the paths created since TrackNew
+func (tx *fsTransaction) removeNew() error {
+	if tx.existing == nil {
+		return nil
+	}
+
+	var created []string
+
+	err := filepath.WalkDir(tx.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if _, ok := tx.existing[path]; !ok {
+			created = append(created, path)
+
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() && path == filepath.Join(tx.root, ".git") {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range created {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	if len(created) > 0 {
+		tx.log.Debug("remove paths created by post generate steps", "paths", len(created))
+	}
+
+	return nil
+}
+
+// Rollback removes paths created since TrackNew, restores every touched path and removes
+// directories created by the transaction
+func (tx *fsTransaction) Rollback() error {
+	var firstErr error
+
+	if err := tx.removeNew(); err != nil {
+		firstErr = errors.Wrap(err, "remove files created by post_generate steps")
+	}
+
+	for i := len(tx.touched) - 1; i >= 0; i-- {
+		t := tx.touched[i]
+
+		var err error
+		if t.backup == "" {
+			err = os.Remove(t.path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			err = restoreFile(t.backup, t.path, t.mode)
+		}
+
+		if err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "restore %s", t.path)
+		}
+	}
+
+	// Deepest first; directories that got foreign content stay
+	sort.Slice(tx.createdDirs, func(i, j int) bool {
+		return len(tx.createdDirs[i]) > len(tx.createdDirs[j])
+	})
+
+	for _, dir := range tx.createdDirs {
+		os.Remove(dir)
+	}
+
+	if firstErr != nil {
+		tx.keepBackup = true
+
+		return errors.WithMessagef(firstErr, "backup kept in %s", tx.backupDir)
+	}
+
+	tx.log.Warn("rollback", "restored", len(tx.touched))
+
+	return nil
+}
+
+// Close removes the stage and backup directories
+func (tx *fsTransaction) Close() {
+	os.RemoveAll(tx.stageDir)
+
+	if !tx.keepBackup {
+		os.RemoveAll(tx.backupDir)
+	}
+}
+
+// rollbackWith undoes the transaction after cause and reports both errors if the rollback fails
+func (tx *fsTransaction) rollbackWith(cause error) error {
+	if err := tx.Rollback(); err != nil {
+		return fmt.Errorf("%w; rollback failed: %s", cause, err)
+	}
+
+	return fmt.Errorf("%w (changes rolled back)", cause)
+}
+
+func copyFileMode(src, dst string, perm os.FileMode) error {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer source.Close()
+
+	destination, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(destination, source); err != nil {
+		destination.Close()
+
+		return err
+	}
+
+	return destination.Close()
+}
+
+// restoreFile puts the backup back atomically: copy next to path, then rename over it
+func restoreFile(backup, path string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), tools.DefaultDirPerm); err != nil {
+		return err
+	}
+
+	tmp := path + ".psg-restore"
+
+	if err := copyFileMode(backup, tmp, perm); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,277 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestFSTransaction_ApplyAndRollback(t *testing.T) {
+	root := t.TempDir()
+
+	existing := filepath.Join(root, "main.go")
+	obsolete := filepath.Join(root, "psg_old_gen.go")
+	renamedFrom := filepath.Join(root, "old_name.go")
+	renamedTo := filepath.Join(root, "new_name.go")
+	created := filepath.Join(root, "internal", "app", "new.go")
+
+	writeTestFile(t, existing, "package main\n")
+	writeTestFile(t, obsolete, "package main\n// old\n")
+	writeTestFile(t, renamedFrom, "package main\n// renamed\n")
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if err := tx.Stage(existing, bytes.NewBufferString("package main\n// regenerated\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Stage(created, bytes.NewBufferString("package app\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing reaches the target before Apply
+	if got := readTestFile(t, existing); got != "package main\n" {
+		t.Fatalf("file changed before Apply: %q", got)
+	}
+
+	if err := tx.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if err := tx.Rename(renamedFrom, renamedTo); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if err := tx.Remove(obsolete); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readTestFile(t, existing); got != "package main\n// regenerated\n" {
+		t.Errorf("after Apply main.go = %q", got)
+	}
+
+	if got := readTestFile(t, created); got != "package app\n" {
+		t.Errorf("after Apply new.go = %q", got)
+	}
+
+	if _, err := os.Stat(tx.stageDir); !os.IsNotExist(err) {
+		t.Errorf("stage directory not removed after Apply: %v", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if got := readTestFile(t, existing); got != "package main\n" {
+		t.Errorf("after Rollback main.go = %q", got)
+	}
+
+	if got := readTestFile(t, obsolete); got != "package main\n// old\n" {
+		t.Errorf("after Rollback obsolete file = %q", got)
+	}
+
+	if got := readTestFile(t, renamedFrom); got != "package main\n// renamed\n" {
+		t.Errorf("after Rollback renamed file = %q", got)
+	}
+
+	for _, path := range []string{renamedTo, created, filepath.Join(root, "internal")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s exists after Rollback", path)
+		}
+	}
+}
+
+func TestFSTransaction_Validate(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "broken.go")
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if err := tx.Stage(dest, bytes.NewBufferString("package main\nfunc {\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	err = tx.Validate()
+	if err == nil || !strings.Contains(err.Error(), "broken.go is not valid Go") {
+		t.Fatalf("Validate() error = %v, want invalid Go error", err)
+	}
+
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("invalid file reached the target tree")
+	}
+	tx.SkipValidation(dest)
+
+	if err := tx.Validate(); err != nil {
+		t.Errorf("Validate() error = %v for a file excluded from validation", err)
+	}
+}
+
+func TestFSTransaction_StageTwice(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "pkg", "app", "daemon", "psg_job_gen.go")
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	for _, code := range []string{"package daemon\n// first\n", "package daemon\n// second\n"} {
+		if err := tx.Stage(dest, bytes.NewBufferString(code)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tx.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if got := readTestFile(t, dest); got != "package daemon\n// second\n" {
+		t.Errorf("after Apply psg_job_gen.go = %q, want the last staged content", got)
+	}
+}
+
+func TestFSTransaction_StageOutsideRoot(t *testing.T) {
+	root := t.TempDir()
+
+	tx, err := newFSTransaction(filepath.Join(root, "project"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if err := tx.Stage(filepath.Join(root, "other", "main.go"), bytes.NewBufferString("package main\n")); err == nil {
+		t.Error("Stage() outside of target directory succeeded")
+	}
+}
+
+func TestFSTransaction_Close(t *testing.T) {
+	root := t.TempDir()
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx.Close()
+
+	for _, dir := range []string{tx.stageDir, tx.backupDir} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s exists after Close", dir)
+		}
+	}
+}
+
+func TestFSTransaction_TrackNew(t *testing.T) {
+	root := t.TempDir()
+	kept := filepath.Join(root, "pkg", "api", "oas_gen.go")
+	writeTestFile(t, kept, "package api\n")
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if err := tx.TrackNew(); err != nil {
+		t.Fatal(err)
+	}
+
+	// What post_generate steps create: a repository, generated code, a new directory
+	writeTestFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeTestFile(t, filepath.Join(root, "pkg", "api", "oas_server_gen.go"), "package api\n")
+	writeTestFile(t, filepath.Join(root, "bin", "api"), "binary")
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	for _, path := range []string{".git", "pkg/api/oas_server_gen.go", "bin"} {
+		if _, err := os.Stat(filepath.Join(root, path)); !os.IsNotExist(err) {
+			t.Errorf("%s exists after Rollback", path)
+		}
+	}
+
+	if got := readTestFile(t, kept); got != "package api\n" {
+		t.Errorf("after Rollback oas_gen.go = %q, want it kept", got)
+	}
+}
+
+func TestFSTransaction_TrackNew_ExistingRepository(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if err := tx.TrackNew(); err != nil {
+		t.Fatal(err)
+	}
+
+	object := filepath.Join(root, ".git", "objects", "ab", "cdef")
+	writeTestFile(t, object, "blob")
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if _, err := os.Stat(object); err != nil {
+		t.Errorf("object written into the existing repository removed by Rollback: %v", err)
+	}
+}
+
+func TestNewFSTransaction_RemovesStaleStage(t *testing.T) {
+	root := t.TempDir()
+	stale := filepath.Join(root, stageDirPattern+"999")
+	writeTestFile(t, filepath.Join(stale, "cmd", "api", "psg_main_gen.go"), "package main\n")
+
+	tx, err := newFSTransaction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Close()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale stage directory %s exists after newFSTransaction", stale)
+	}
+}
@@ -5,7 +5,7 @@ import "strings"
 // TransportInfo contains transport information for dashboard generation.
 type TransportInfo struct {
 	Name          string
-	GeneratorType string // "ogen", "ogen_client", "template"
+	GeneratorType string // "ogen", "ogen_client", "template", "buf_server", "buf_client"
 }
 
 // Datasource represents a resolved Grafana datasource for templates.
@@ -78,8 +78,8 @@ func (g Config) HasLoki() bool {
 	return g.HasDatasourceType(DatasourceLoki)
 }
 
-// GetDashboardRows returns all dashboard rows based on configured datasources and transports.
-func (g Config) GetDashboardRows(appName string, transports []TransportInfo) []Row {
+// GetDashboardRows returns all dashboard rows based on configured datasources, transports and scheduler jobs.
+func (g Config) GetDashboardRows(appName string, transports []TransportInfo, schedulerJobs []string) []Row {
 	var rows []Row
 
 	// 1. Logs row (if Loki datasource exists)
@@ -120,6 +120,26 @@ func (g Config) GetDashboardRows(appName string, transports []TransportInfo) []R
 				})
 			}
 		}
+
+		// 5. gRPC Server rows for each buf_server transport
+		for _, t := range transports {
+			if t.GeneratorType == "buf_server" {
+				rows = append(rows, Row{
+					Title:     "gRPC Server: " + t.Name,
+					Collapsed: true,
+					Panels:    DefaultGRPCServerPanels(t.Name),
+				})
+			}
+		}
+
+		// 6. Scheduler rows for each job
+		for _, job := range schedulerJobs {
+			rows = append(rows, Row{
+				Title:     "Scheduler: " + job,
+				Collapsed: true,
+				Panels:    DefaultSchedulerJobPanels(job),
+			})
+		}
 	}
 
 	return rows
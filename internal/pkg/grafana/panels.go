@@ -285,3 +285,149 @@ func DefaultLogsPanels(appName string) []Panel {
 		},
 	}
 }
+
+// DefaultGRPCServerPanels returns gRPC server metrics panels for a specific server.
+func DefaultGRPCServerPanels(serverName string) []Panel {
+	return []Panel{
+		{
+			Title:      "gRPC Status Codes",
+			Type:       "timeseries",
+			Width:      panelWidthFull,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr: `sum by(grpc_code) ` +
+						`(increase(grpc_server_handled_total{server_name="` + serverName + `"}[$__rate_interval]))`,
+					LegendFormat: "{{grpc_code}}",
+					RefID:        "A",
+				},
+			},
+		},
+		{
+			Title:      "Errors",
+			Type:       "timeseries",
+			Width:      panelWidthHalf,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr: `sum by(grpc_service, grpc_method, grpc_code) ` +
+						`(increase(grpc_server_handled_total{server_name="` + serverName + `",` +
+						`grpc_code!="OK"}[$__rate_interval]))`,
+					LegendFormat: "{{grpc_service}}/{{grpc_method}} {{grpc_code}}",
+					RefID:        "A",
+				},
+			},
+		},
+		{
+			Title:      "Requests by Method",
+			Type:       "timeseries",
+			Width:      panelWidthHalf,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr: `sum by(grpc_service, grpc_method) ` +
+						`(increase(grpc_server_started_total{server_name="` + serverName + `"}[$__rate_interval]))`,
+					LegendFormat: "{{grpc_service}}/{{grpc_method}}",
+					RefID:        "A",
+				},
+			},
+		},
+		{
+			Title:      "Request Latency (s)",
+			Type:       "timeseries",
+			Width:      panelWidthFull,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr: `histogram_quantile(0.99, sum by(le, grpc_method) ` +
+						`(rate(grpc_server_handling_seconds_bucket{server_name="` + serverName +
+						`"}[$__rate_interval])))`,
+					LegendFormat: "p99 {{grpc_method}}",
+					RefID:        "A",
+				},
+				{
+					Expr: `histogram_quantile(0.95, sum by(le, grpc_method) ` +
+						`(rate(grpc_server_handling_seconds_bucket{server_name="` + serverName +
+						`"}[$__rate_interval])))`,
+					LegendFormat: "p95 {{grpc_method}}",
+					RefID:        "B",
+				},
+			},
+		},
+	}
+}
+
+// DefaultSchedulerJobPanels returns metrics panels for a single scheduler job.
+func DefaultSchedulerJobPanels(jobName string) []Panel {
+	return []Panel{
+		{
+			Title:      "Runs by Status",
+			Type:       "timeseries",
+			Width:      panelWidthHalf,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr: `sum by(status) ` +
+						`(increase(scheduler_job_runs_total{job="` + jobName + `"}[$__rate_interval]))`,
+					LegendFormat: "{{status}}",
+					RefID:        "A",
+				},
+			},
+		},
+		{
+			Title:      "Skipped Runs",
+			Type:       "timeseries",
+			Width:      panelWidthHalf,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr: `sum by(reason) ` +
+						`(increase(scheduler_job_skipped_total{job="` + jobName + `"}[$__rate_interval]))`,
+					LegendFormat: "{{reason}}",
+					RefID:        "A",
+				},
+			},
+		},
+		{
+			Title:      "Run Duration (s)",
+			Type:       "timeseries",
+			Width:      panelWidthHalf,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr: `histogram_quantile(0.99, sum by(le) ` +
+						`(rate(scheduler_job_duration_seconds_bucket{job="` + jobName + `"}[$__rate_interval])))`,
+					LegendFormat: "p99",
+					RefID:        "A",
+				},
+				{
+					Expr: `histogram_quantile(0.5, sum by(le) ` +
+						`(rate(scheduler_job_duration_seconds_bucket{job="` + jobName + `"}[$__rate_interval])))`,
+					LegendFormat: "p50",
+					RefID:        "B",
+				},
+			},
+		},
+		{
+			Title:      "Time Since Last Success (s)",
+			Type:       "timeseries",
+			Width:      panelWidthHalf,
+			Height:     panelHeightM,
+			Datasource: "prometheus",
+			Targets: []PanelTarget{
+				{
+					Expr:         `time() - max(scheduler_job_last_success_timestamp_seconds{job="` + jobName + `"})`,
+					LegendFormat: "since last success",
+					RefID:        "A",
+				},
+			},
+		},
+	}
+}
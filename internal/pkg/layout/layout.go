@@ -0,0 +1,137 @@
+// Package layout tracks how the file layout of generated projects changes between generator releases.
+// Every layout change is a Migration tied to the meta.yaml version it produces. A project generated
+// by an older release is upgraded by the pending migrations, one after another, so a project several
+// releases behind ends up in the current layout.
+package layout
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Migration moves a project from the layout of version Version-1 to the layout of Version
+type Migration struct {
+	Version     int
+	Description string
+	Renames     []Rename  // file renames and path moves
+	Rewrites    []Rewrite // rewrites of the user code kept in the migrated files
+}
+
+// Rename maps a path of the layout after a migration to the path the file had before it.
+// Paths are slash-separated and relative to the target, the path is returned unchanged if the rule does not apply.
+type Rename func(path string) string
+
+// Rewrite replaces Old with New in the user code of files generated before a migration
+type Rewrite struct {
+	Old string
+	New string
+}
+
+// Move is a file renamed by a migration
+type Move struct {
+	Version int
+	From    string
+	To      string
+}
+
+// MoveDir is a Rename of files moved from directory from to directory to, at any depth of the tree
+func MoveDir(from, to string) Rename {
+	return func(p string) string {
+		switch {
+		case strings.HasPrefix(p, to+"/"):
+			return from + strings.TrimPrefix(p, to)
+		case strings.Contains(p, "/"+to+"/"):
+			return strings.Replace(p, "/"+to+"/", "/"+from+"/", 1)
+		}
+
+		return p
+	}
+}
+
+// Current is the layout version of projects written by this generator
+func Current() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Check returns an error if a project of layout version is newer than this generator knows
+func Check(version int) error {
+	if version > Current() {
+		return fmt.Errorf("project layout version %d is newer than %d supported by this generator, upgrade go-project-starter", version, Current())
+	}
+
+	return nil
+}
+
+// Pending returns the migrations a project of layout version from needs, in order
+func Pending(from int) []Migration {
+	for i, m := range migrations {
+		if m.Version > from {
+			return migrations[i:]
+		}
+	}
+
+	return nil
+}
+
+// OldPath returns the path a file of the current layout had in a project of layout version from
+func OldPath(p string, from int) string {
+	pending := Pending(from)
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		p = pending[i].oldPath(p)
+	}
+
+	return p
+}
+
+// Moves lists the renames of a file of the current layout made by the migrations from layout version from, oldest first
+func Moves(p string, from int) []Move {
+	pending := Pending(from)
+	moves := []Move{}
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		old := pending[i].oldPath(p)
+		if old != p {
+			moves = append([]Move{{Version: pending[i].Version, From: old, To: p}}, moves...)
+		}
+
+		p = old
+	}
+
+	return moves
+}
+
+// RewriteCode applies the rewrites of the migrations from layout version from to user code, in order
+func RewriteCode(code string, from int) string {
+	for _, m := range Pending(from) {
+		for _, rw := range m.Rewrites {
+			code = strings.ReplaceAll(code, rw.Old, rw.New)
+		}
+	}
+
+	return code
+}
+
+// oldPath undoes the renames of the migration, the last rename first
+func (m Migration) oldPath(p string) string {
+	for i := len(m.Renames) - 1; i >= 0; i-- {
+		p = m.Renames[i](p)
+	}
+
+	return p
+}
+
+// validate checks that the versions of the migrations follow each other starting from 2
+func validate(list []Migration) error {
+	for i, m := range list {
+		if m.Version != i+2 {
+			return fmt.Errorf("migration %q has version %d, want %d", m.Description, m.Version, i+2)
+		}
+
+		if m.Description == "" {
+			return fmt.Errorf("migration to version %d has no description", m.Version)
+		}
+	}
+
+	return nil
+}
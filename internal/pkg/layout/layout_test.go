@@ -0,0 +1,99 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrations_Valid(t *testing.T) {
+	if err := validate(migrations); err != nil {
+		t.Fatalf("validate(migrations) error = %v", err)
+	}
+
+	if err := validate([]Migration{{Version: 2, Description: "a"}, {Version: 4, Description: "b"}}); err == nil {
+		t.Error("validate() with a gap in versions error = nil, want error")
+	}
+
+	if err := validate([]Migration{{Version: 2}}); err == nil {
+		t.Error("validate() without description error = nil, want error")
+	}
+}
+
+func TestOldPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		from int
+		want string
+	}{
+		{name: "current layout", path: "pkg/ds/psg_types_gen.go", from: Current(), want: "pkg/ds/psg_types_gen.go"},
+		{name: "v3 tests", path: "tests/api/psg_config_gen.go", from: 3, want: "tests/psg_config_gen.go"},
+		{name: "v2 ds", path: "pkg/ds/psg_types_gen.go", from: 2, want: "pkg/app/ds/psg_types_gen.go"},
+		{name: "v2 nested ds", path: "internal/pkg/ds/psg_types_gen.go", from: 2, want: "internal/pkg/app/ds/psg_types_gen.go"},
+		{name: "v2 not ds", path: "pkg/dsl/psg_types_gen.go", from: 2, want: "pkg/dsl/psg_types_gen.go"},
+		{name: "v1 go file", path: "cmd/api/psg_main_gen.go", from: 1, want: "cmd/api/main.go"},
+		{name: "v1 test file", path: "cmd/api/psg_main_test.go", from: 1, want: "cmd/api/main_test.go"},
+		{name: "v1 all steps", path: "tests/api/psg_config_gen.go", from: 1, want: "tests/config.go"},
+		{name: "v1 not go", path: "configs/api.yaml", from: 1, want: "configs/api.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OldPath(tt.path, tt.from); got != tt.want {
+				t.Errorf("OldPath(%q, %d) = %q, want %q", tt.path, tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoves(t *testing.T) {
+	got := Moves("pkg/ds/psg_types_gen.go", 1)
+	want := []Move{
+		{Version: 2, From: "pkg/app/ds/types.go", To: "pkg/app/ds/psg_types_gen.go"},
+		{Version: 3, From: "pkg/app/ds/psg_types_gen.go", To: "pkg/ds/psg_types_gen.go"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Moves() = %+v, want %+v", got, want)
+	}
+
+	if got := Moves("pkg/ds/psg_types_gen.go", Current()); len(got) != 0 {
+		t.Errorf("Moves() of the current layout = %+v, want none", got)
+	}
+}
+
+func TestRewriteCode(t *testing.T) {
+	code := "import \"example.com/svc/pkg/app/ds\"\n"
+
+	if got, want := RewriteCode(code, 2), "import \"example.com/svc/pkg/ds\"\n"; got != want {
+		t.Errorf("RewriteCode(v2) = %q, want %q", got, want)
+	}
+
+	if got := RewriteCode(code, 3); got != code {
+		t.Errorf("RewriteCode(v3) = %q, want unchanged", got)
+	}
+}
+
+func TestPending(t *testing.T) {
+	if got := len(Pending(1)); got != len(migrations) {
+		t.Errorf("len(Pending(1)) = %d, want %d", got, len(migrations))
+	}
+
+	if got := Pending(Current()); len(got) != 0 {
+		t.Errorf("Pending(Current()) = %+v, want none", got)
+	}
+
+	if got := Pending(3); len(got) != 1 || got[0].Version != 4 {
+		t.Errorf("Pending(3) = %+v, want version 4", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(Current()); err != nil {
+		t.Errorf("Check(Current()) error = %v", err)
+	}
+
+	if err := Check(Current() + 1); err == nil {
+		t.Error("Check(newer) error = nil, want error")
+	}
+}
@@ -0,0 +1,54 @@
+package layout
+
+import (
+	"path"
+	"strings"
+)
+
+// migrations of the project layout, ordered by version. A new layout change is appended with the next version,
+// released migrations are never changed: projects in the field are at any of their versions.
+var migrations = []Migration{
+	{
+		Version:     2,
+		Description: "prefix generated Go files with psg_ and suffix them with _gen",
+		Renames:     []Rename{unprefixGenerated},
+	},
+	{
+		Version:     3,
+		Description: "move pkg/app/ds to pkg/ds",
+		Renames:     []Rename{MoveDir("pkg/app/ds", "pkg/ds")},
+		Rewrites:    []Rewrite{{Old: `/pkg/app/ds"`, New: `/pkg/ds"`}},
+	},
+	{
+		Version:     4,
+		Description: "move generated tests to tests/<app>",
+		Renames:     []Rename{flattenTests},
+	},
+}
+
+// unprefixGenerated maps dir/psg_name_gen.go to dir/name.go and dir/psg_name_test.go to dir/name_test.go
+func unprefixGenerated(p string) string {
+	dir, name := path.Split(p)
+	if !strings.HasPrefix(name, "psg_") {
+		return p
+	}
+
+	switch {
+	case strings.HasSuffix(name, "_test.go"):
+		return dir + strings.TrimPrefix(name, "psg_")
+	case strings.HasSuffix(name, "_gen.go"):
+		return dir + strings.TrimSuffix(strings.TrimPrefix(name, "psg_"), "_gen.go") + ".go"
+	}
+
+	return p
+}
+
+// flattenTests maps tests/<app>/<file> to tests/<file>
+func flattenTests(p string) string {
+	parts := strings.SplitN(p, "/", 3)
+	if len(parts) != 3 || parts[0] != "tests" {
+		return p
+	}
+
+	return path.Join("tests", parts[2])
+}
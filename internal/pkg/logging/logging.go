@@ -0,0 +1,59 @@
+// Package logging builds the logger of the generator itself: the messages of loading the config,
+// rendering the templates and writing the files, leveled by --verbose and --quiet.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats of the log
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Formats are the formats accepted by New
+var Formats = []string{FormatText, FormatJSON}
+
+// Options of the logger
+type Options struct {
+	Verbose bool   // log debug messages: every file rendered, copied or skipped
+	Quiet   bool   // log warnings and errors only
+	Format  string // text (default) or json
+}
+
+// Level returns the minimal level of the messages logged with the options
+func (o Options) Level() (slog.Level, error) {
+	switch {
+	case o.Verbose && o.Quiet:
+		return 0, fmt.Errorf("verbose and quiet can not be used together")
+	case o.Verbose:
+		return slog.LevelDebug, nil
+	case o.Quiet:
+		return slog.LevelWarn, nil
+	default:
+		return slog.LevelInfo, nil
+	}
+}
+
+// New returns a logger writing to w with the options
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	level, err := opts.Level()
+	if err != nil {
+		return nil, err
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	switch opts.Format {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	}
+
+	return nil, fmt.Errorf("unknown log format %q, expected one of: %s", opts.Format, strings.Join(Formats, ", "))
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestOptions_Level(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    slog.Level
+		wantErr bool
+	}{
+		{name: "default", opts: Options{}, want: slog.LevelInfo},
+		{name: "verbose", opts: Options{Verbose: true}, want: slog.LevelDebug},
+		{name: "quiet", opts: Options{Quiet: true}, want: slog.LevelWarn},
+		{name: "both", opts: Options{Verbose: true, Quiet: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.Level()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Level() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Level() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := New(&buf, Options{Quiet: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("rendered", "file", "main.go")
+	logger.Warn("deprecated", "feature", "rest.version")
+
+	if out := buf.String(); strings.Contains(out, "rendered") || !strings.Contains(out, "feature=rest.version") {
+		t.Errorf("New(quiet) logged\n%s", out)
+	}
+
+	buf.Reset()
+
+	if logger, err = New(&buf, Options{Verbose: true, Format: FormatJSON}); err != nil {
+		t.Fatalf("New(json) error = %v", err)
+	}
+
+	logger.Debug("rendered", "file", "main.go")
+
+	var record map[string]any
+	if err = json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("New(json) logged invalid JSON %q: %v", buf.String(), err)
+	}
+
+	if record["level"] != "DEBUG" || record["msg"] != "rendered" || record["file"] != "main.go" {
+		t.Errorf("New(json) logged %v", record)
+	}
+
+	if _, err = New(&buf, Options{Format: "xml"}); err == nil {
+		t.Error("New(xml) error = nil, want error")
+	}
+}
@@ -6,15 +6,30 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-const curVer = 4
-
 type Meta struct {
-	Path    string `yaml:"-"`
-	Version int    `yaml:"version"`
+	Path string `yaml:"-"`
+	// Version is the layout version of the project, see package layout
+	Version int `yaml:"version"`
+	// Layout migrations applied to the project, in order
+	Migrations []AppliedMigration `yaml:"migrations,omitempty"`
+	// Checksums of the generated part of each file written by the last generation,
+	// keyed by path relative to the target directory
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+
+	// notFound is set when the project has no meta.yaml yet
+	notFound bool
+}
+
+// AppliedMigration is a layout migration applied by a generation
+type AppliedMigration struct {
+	Version     int    `yaml:"version"`
+	Description string `yaml:"description"`
+	AppliedAt   string `yaml:"applied_at"`
 }
 
 func GetDefaultMeta(path string) Meta {
@@ -36,11 +51,14 @@ func GetMeta(baseDir, metaPath string) (Meta, error) {
 	meta = GetDefaultMeta(realMetaPath)
 
 	source, err := os.ReadFile(realMetaPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		meta.notFound = true
+
+		return meta, nil
+	}
+
 	if err != nil {
-		errNotFound := &fs.PathError{}
-		if errors.As(err, &errNotFound) {
-			return meta, nil
-		}
+		return meta, err
 	}
 
 	if err := yaml.Unmarshal(source, &meta); err != nil {
@@ -52,13 +70,32 @@ func GetMeta(baseDir, metaPath string) (Meta, error) {
 	return meta, nil
 }
 
-func (m *Meta) Save() error {
-	m.Version = curVer
+// NotFound reports whether the project has no meta.yaml yet
+func (m *Meta) NotFound() bool {
+	return m.notFound
+}
+
+// RecordMigration moves the project to the layout version of an applied migration
+func (m *Meta) RecordMigration(version int, description string, at time.Time) {
+	m.Version = version
+	m.Migrations = append(m.Migrations, AppliedMigration{
+		Version:     version,
+		Description: description,
+		AppliedAt:   at.UTC().Format(time.RFC3339),
+	})
+}
 
+func (m *Meta) Save() error {
 	data, err := yaml.Marshal(m)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(m.Path, data, 0644)
+	if err := os.WriteFile(m.Path, data, 0644); err != nil {
+		return err
+	}
+
+	m.notFound = false
+
+	return nil
 }
@@ -3,10 +3,12 @@ package migrate
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +18,7 @@ type DeprecationWarning struct {
 	Description   string
 	RemovalVer    string
 	MigrationHint string
+	Line          int // line of project.yaml using the feature, 0 if not known
 }
 
 // MigrationResult contains the result of a migration
@@ -30,6 +33,7 @@ type MigrationResult struct {
 type Migrator struct {
 	configPath string
 	dryRun     bool
+	to         string // generator version to migrate to, every rule is applied if empty
 }
 
 // Version constants for deprecation tracking
@@ -39,17 +43,24 @@ const (
 )
 
 // Deprecation removal version constants - each deprecation has its own removal version
-// (currently none pending)
+const (
+	RemovalVersionTransportStringArray = "0.12.0"
+	RemovalVersionEmptyConfigAvailable = "0.13.0"
+)
 
 // Error message constants
 const (
 	errMsgReadConfigFile  = "failed to read config file"
 	errMsgParseConfigFile = "failed to parse config file"
+	errMsgWriteConfigFile = "failed to write config file"
 )
 
 // File permission for config files
 const configFilePermission = 0o600
 
+// backupExt is appended to the config path to get the path of its copy made before migration
+const backupExt = ".bak"
+
 // New creates a new Migrator
 func New(configPath string, dryRun bool) *Migrator {
 	return &Migrator{
@@ -58,33 +69,149 @@ func New(configPath string, dryRun bool) *Migrator {
 	}
 }
 
-// Migrate performs the migration and returns the result
+// SetTargetVersion limits the migration to the rules of generator versions up to version
+func (m *Migrator) SetTargetVersion(version string) error {
+	if !semver.IsValid(canonical(version)) {
+		return fmt.Errorf("invalid version %q", version)
+	}
+
+	m.to = version
+
+	return nil
+}
+
+// Migrate applies the rules up to the target version and returns the result.
+// The config is rewritten through yaml.Node, so comments and key order are kept;
+// the original file is copied to <config>.bak first, or to <config>.bak.2, ... if a backup exists already.
 func (m *Migrator) Migrate() (*MigrationResult, error) {
 	result := &MigrationResult{
 		OriginalPath: m.configPath,
 	}
 
-	// No migrations currently needed — transport string array format removed in v0.12.0
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, errMsgReadConfigFile)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, errMsgParseConfigFile)
+	}
+
+	for _, rule := range rules {
+		if m.to != "" && versionLess(m.to, rule.DeprecatedIn) {
+			continue
+		}
+
+		nodes := rule.find(&doc)
+		if len(nodes) == 0 {
+			continue
+		}
+
+		for _, node := range nodes {
+			if err := rule.Fix(&doc, node); err != nil {
+				return nil, errors.Wrapf(err, "failed to migrate %s at line %d", rule.Feature, node.Line)
+			}
+
+			slog.Debug("migrate", "feature", rule.Feature, "line", node.Line, "deprecated_in", rule.DeprecatedIn)
+		}
+
+		result.Modified = true
+		result.Warnings = append(result.Warnings, rule.warning())
+	}
+
+	if !result.Modified || m.dryRun {
+		return result, nil
+	}
+
+	out, err := encode(&doc)
+	if err != nil {
+		return nil, errors.Wrap(err, errMsgWriteConfigFile)
+	}
+
+	if result.BackupPath, err = backupPath(m.configPath); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(result.BackupPath, data, configFilePermission); err != nil {
+		return nil, errors.Wrap(err, "failed to write backup")
+	}
+
+	if err := os.WriteFile(m.configPath, out, configFilePermission); err != nil {
+		return nil, errors.Wrap(err, errMsgWriteConfigFile)
+	}
+
 	return result, nil
 }
 
-// CheckDeprecations checks config for deprecated features without migrating
+// backupPath returns the first free name of path.bak, path.bak.2, ...: backups of earlier migrations are kept
+func backupPath(path string) (string, error) {
+	backup := path + backupExt
+
+	for i := 2; ; i++ {
+		_, err := os.Lstat(backup)
+		if os.IsNotExist(err) {
+			return backup, nil
+		}
+
+		if err != nil {
+			return "", errors.Wrap(err, "failed to check backup")
+		}
+
+		backup = fmt.Sprintf("%s%s.%d", path, backupExt, i)
+	}
+}
+
+// CheckDeprecations checks config for deprecated features without migrating.
+// Only deprecations still supported by the current version are reported, configs with removed
+// features fail to load with a hint to run migrate.
 func CheckDeprecations(configPath string) ([]DeprecationWarning, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, errors.Wrap(err, errMsgReadConfigFile)
 	}
 
-	var config map[string]any
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	return CheckDeprecationsData(data)
+}
+
+// CheckDeprecationsData is CheckDeprecations for the content of a config file
+func CheckDeprecationsData(data []byte) ([]DeprecationWarning, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, errors.Wrap(err, errMsgParseConfigFile)
 	}
 
 	var warnings []DeprecationWarning
 
+	for _, rule := range rules {
+		if !versionLess(CurrentVersion, rule.RemovalVer) {
+			continue
+		}
+
+		for _, node := range rule.find(&doc) {
+			w := rule.warning()
+			w.Line = node.Line
+			warnings = append(warnings, w)
+		}
+	}
+
 	return warnings, nil
 }
 
+// LogWarnings logs a warning for every deprecated feature used in file
+func LogWarnings(logger *slog.Logger, file string, warnings []DeprecationWarning) {
+	for _, w := range warnings {
+		logger.Warn("deprecated config feature",
+			"file", file,
+			"line", w.Line,
+			"feature", w.Feature,
+			"description", w.Description,
+			"removal", w.RemovalVer,
+			"migration", w.MigrationHint,
+		)
+	}
+}
+
 // PrintWarnings prints deprecation warnings to stderr
 func PrintWarnings(warnings []DeprecationWarning) {
 	if len(warnings) == 0 {
@@ -95,7 +222,12 @@ func PrintWarnings(warnings []DeprecationWarning) {
 	fmt.Fprintln(os.Stderr, "========================")
 
 	for _, w := range warnings {
-		fmt.Fprintf(os.Stderr, "\n[DEPRECATED] %s\n", w.Feature)
+		if w.Line > 0 {
+			fmt.Fprintf(os.Stderr, "\n[DEPRECATED] %s (line %d)\n", w.Feature, w.Line)
+		} else {
+			fmt.Fprintf(os.Stderr, "\n[DEPRECATED] %s\n", w.Feature)
+		}
+
 		fmt.Fprintf(os.Stderr, "  Description: %s\n", w.Description)
 		fmt.Fprintf(os.Stderr, "  Will be removed in: %s\n", w.RemovalVer)
 		fmt.Fprintf(os.Stderr, "  Migration: %s\n", w.MigrationHint)
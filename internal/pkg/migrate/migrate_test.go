@@ -1,6 +1,9 @@
 package migrate
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -46,26 +49,155 @@ func TestNew(t *testing.T) {
 }
 
 func TestMigrator_Migrate(t *testing.T) {
-	m := New("/path/to/config.yaml", false)
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "project.yaml")
 
-	result, err := m.Migrate()
+	original := `# Project config
+main:
+  name: myproject # service name
+rest:
+  - name: api
+    generator_type: ogen
+    empty_config_available: true
+applications:
+  - name: server
+    transport:
+      - api # public API
+      - sys
+`
+	if err := os.WriteFile(configPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	result, err := New(configPath, false).Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if !result.Modified || len(result.Warnings) != 2 {
+		t.Fatalf("Migrate() = %+v, want 2 rules applied", result)
+	}
+
+	want := `# Project config
+main:
+  name: myproject # service name
+rest:
+  - name: api
+    generator_type: ogen
+applications:
+  - name: server
+    transport:
+      - name: api # public API
+        config:
+          optional: true
+      - name: sys
+`
+
+	got, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read migrated config: %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("Migrate() wrote\n%s\nwant\n%s", got, want)
+	}
+
+	backup, err := os.ReadFile(result.BackupPath)
+	if err != nil || string(backup) != original || result.BackupPath != configPath+".bak" {
+		t.Errorf("Migrate() backup %q = %q, %v", result.BackupPath, backup, err)
+	}
+
+	// A migrated config needs nothing more
+	result, err = New(configPath, false).Migrate()
+	if err != nil || result.Modified {
+		t.Errorf("Migrate() of a migrated config = %+v, %v", result, err)
+	}
+}
+
+func TestMigrator_Migrate_KeepsBackup(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "project.yaml")
+	original := "applications:\n  - name: server\n    transport:\n      - sys\n"
+
+	// The backup of an earlier migration is not overwritten
+	if err := os.WriteFile(configPath+".bak", []byte("old backup\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write backup: %v", err)
+	}
+
+	if err := os.WriteFile(configPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	result, err := New(configPath, false).Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if result.BackupPath != configPath+".bak.2" {
+		t.Errorf("Migrate() BackupPath = %q, want %q", result.BackupPath, configPath+".bak.2")
+	}
+
+	if backup, _ := os.ReadFile(result.BackupPath); string(backup) != original {
+		t.Errorf("Migrate() backup = %q, want %q", backup, original)
+	}
+
+	if backup, _ := os.ReadFile(configPath + ".bak"); string(backup) != "old backup\n" {
+		t.Errorf("Migrate() overwrote the existing backup: %q", backup)
+	}
+}
+
+func TestMigrator_Migrate_DryRun(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "project.yaml")
+	original := "applications:\n  - name: server\n    transport:\n      - sys\n"
+
+	if err := os.WriteFile(configPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
 
+	result, err := New(configPath, true).Migrate()
 	if err != nil {
-		t.Errorf("Migrate() error = %v, want nil", err)
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if !result.Modified || result.BackupPath != "" {
+		t.Errorf("Migrate() = %+v, want modified without backup", result)
+	}
+
+	if got, _ := os.ReadFile(configPath); string(got) != original {
+		t.Errorf("Migrate() in dry run changed the config:\n%s", got)
+	}
+}
+
+func TestMigrator_SetTargetVersion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "project.yaml")
+	original := "rest:\n  - name: api\n    empty_config_available: false\napplications:\n  - name: server\n    transport:\n      - api\n"
+
+	if err := os.WriteFile(configPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	m := New(configPath, false)
+
+	for _, version := range []string{"latest", "1.x"} {
+		if err := m.SetTargetVersion(version); err == nil {
+			t.Errorf("SetTargetVersion(%q) error = nil, want error", version)
+		}
 	}
 
-	if result == nil {
-		t.Error("Migrate() result = nil, want non-nil")
-		return
+	// empty_config_available is deprecated in 0.11.0
+	if err := m.SetTargetVersion("0.10.0"); err != nil {
+		t.Fatalf("SetTargetVersion() error = %v", err)
 	}
 
-	if result.OriginalPath != "/path/to/config.yaml" {
-		t.Errorf("Migrate() OriginalPath = %q, want %q", result.OriginalPath, "/path/to/config.yaml")
+	result, err := m.Migrate()
+	if err != nil || len(result.Warnings) != 1 || result.Warnings[0].Feature != "transport string array" {
+		t.Errorf("Migrate() to 0.10.0 = %+v, %v, want transport string array only", result, err)
 	}
+}
 
-	// Currently no migrations, so Modified should be false
-	if result.Modified {
-		t.Error("Migrate() Modified = true, want false (no migrations)")
+func TestMigrator_Migrate_FileErrors(t *testing.T) {
+	if _, err := New("/nonexistent/path/config.yaml", false).Migrate(); err == nil ||
+		!strings.Contains(err.Error(), "failed to read config file") {
+		t.Errorf("Migrate() error = %v, want read error", err)
 	}
 }
 
@@ -92,7 +224,7 @@ rest:
 			wantWarnings: 0,
 		},
 		{
-			name: "empty_config_available is ignored (removed in 0.13.0)",
+			name: "empty_config_available",
 			configContent: `
 main:
   name: myproject
@@ -101,7 +233,22 @@ rest:
     generator_type: ogen
     empty_config_available: true
 `,
-			wantWarnings: 0,
+			wantWarnings: 1,
+			wantFeature:  "empty_config_available",
+		},
+		{
+			name: "transport string array",
+			configContent: `
+main:
+  name: myproject
+applications:
+  - name: server
+    transport:
+      - api
+      - name: sys
+`,
+			wantWarnings: 1,
+			wantFeature:  "transport string array",
 		},
 	}
 
@@ -308,6 +455,38 @@ func TestDeprecationWarning_Fields(t *testing.T) {
 	}
 }
 
+func TestLogWarnings(t *testing.T) {
+	var buf bytes.Buffer
+
+	LogWarnings(slog.New(slog.NewJSONHandler(&buf, nil)), "project.yaml", []DeprecationWarning{{
+		Feature:       "test_feature",
+		Description:   "Test description",
+		RemovalVer:    "1.0.0",
+		MigrationHint: "Do this instead",
+		Line:          7,
+	}})
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("LogWarnings() logged invalid JSON %q: %v", buf.String(), err)
+	}
+
+	want := map[string]any{
+		"level":     "WARN",
+		"file":      "project.yaml",
+		"line":      float64(7),
+		"feature":   "test_feature",
+		"removal":   "1.0.0",
+		"migration": "Do this instead",
+	}
+
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("LogWarnings() %s = %v, want %v", key, record[key], value)
+		}
+	}
+}
+
 func TestMigrationResult_Fields(t *testing.T) {
 	r := MigrationResult{
 		Modified:     true,
@@ -0,0 +1,199 @@
+package migrate
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/tools"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is a config migration: where a deprecated format is found and how it is rewritten
+type Rule struct {
+	Feature       string
+	Description   string
+	DeprecatedIn  string // generator version introducing the new format, migrate --to applies rules up to it
+	RemovalVer    string // generator version no longer loading the old format
+	MigrationHint string
+	// Path selects the nodes the rule looks at: mapping keys separated by dots, * for every item of a sequence
+	Path string
+	// Match reports whether a selected node uses the deprecated format
+	Match func(node *yaml.Node) bool
+	// Fix rewrites a matched node in place, doc is the whole config for rules touching other sections
+	Fix func(doc, node *yaml.Node) error
+}
+
+// rules of config migrations, ordered by DeprecatedIn. A rule is kept after the removal of its
+// format: configs written for older versions are still migrated by it.
+var rules = []Rule{
+	{
+		Feature:       "transport string array",
+		Description:   "applications[].transport as a list of transport names",
+		DeprecatedIn:  "0.10.0",
+		RemovalVer:    RemovalVersionTransportStringArray,
+		MigrationHint: "Use object format: `- name: transport_name`",
+		Path:          "applications.*.transport.*",
+		Match:         isScalar,
+		Fix:           nameToObject,
+	},
+	{
+		Feature:       "empty_config_available",
+		Description:   "rest[].empty_config_available makes the transport optional in every application",
+		DeprecatedIn:  "0.11.0",
+		RemovalVer:    RemovalVersionEmptyConfigAvailable,
+		MigrationHint: "Use `optional: true` in application transport config",
+		Path:          "rest.*",
+		Match:         func(node *yaml.Node) bool { return tools.MappingValue(node, "empty_config_available") != nil },
+		Fix:           emptyConfigToOptional,
+	},
+}
+
+// find returns the nodes of the config matched by the rule
+func (r Rule) find(doc *yaml.Node) []*yaml.Node {
+	var found []*yaml.Node
+
+	for _, node := range selectNodes(doc, r.Path) {
+		if r.Match(node) {
+			found = append(found, node)
+		}
+	}
+
+	return found
+}
+
+func (r Rule) warning() DeprecationWarning {
+	return DeprecationWarning{
+		Feature:       r.Feature,
+		Description:   r.Description,
+		RemovalVer:    r.RemovalVer,
+		MigrationHint: r.MigrationHint,
+	}
+}
+
+// selectNodes returns the nodes at path, see Rule.Path
+func selectNodes(doc *yaml.Node, path string) []*yaml.Node {
+	nodes := []*yaml.Node{doc}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		nodes = []*yaml.Node{doc.Content[0]}
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		var next []*yaml.Node
+
+		for _, node := range nodes {
+			switch {
+			case key == "*" && node.Kind == yaml.SequenceNode:
+				next = append(next, node.Content...)
+			case node.Kind == yaml.MappingNode:
+				if value := tools.MappingValue(node, key); value != nil {
+					next = append(next, value)
+				}
+			}
+		}
+
+		nodes = next
+	}
+
+	return nodes
+}
+
+func scalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+func isScalar(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode
+}
+
+// nameToObject turns a list item `- foo` into `- name: foo`, comments stay with the name
+func nameToObject(_, node *yaml.Node) error {
+	name := *node
+	name.HeadComment, name.FootComment = "", ""
+
+	*node = yaml.Node{
+		Kind:        yaml.MappingNode,
+		Tag:         "!!map",
+		HeadComment: node.HeadComment,
+		FootComment: node.FootComment,
+		Line:        node.Line,
+		Column:      node.Column,
+		Content:     []*yaml.Node{scalar("!!str", "name"), &name},
+	}
+
+	return nil
+}
+
+// emptyConfigToOptional removes empty_config_available from a rest transport and, if it was true,
+// makes the transport optional in the applications using it
+func emptyConfigToOptional(doc, node *yaml.Node) error {
+	enabled := false
+	if err := tools.MappingValue(node, "empty_config_available").Decode(&enabled); err != nil {
+		return err
+	}
+
+	tools.RemoveMappingKey(node, "empty_config_available")
+
+	name := tools.MappingValue(node, "name")
+	if !enabled || name == nil {
+		return nil
+	}
+
+	for _, transport := range selectNodes(doc, "applications.*.transport.*") {
+		if transport.Kind == yaml.ScalarNode {
+			if transport.Value != name.Value {
+				continue
+			}
+
+			if err := nameToObject(doc, transport); err != nil {
+				return err
+			}
+		}
+
+		if value := tools.MappingValue(transport, "name"); value == nil || value.Value != name.Value {
+			continue
+		}
+
+		config := tools.MappingValue(transport, "config")
+		if config == nil || config.Kind != yaml.MappingNode {
+			config = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			tools.SetMappingValue(transport, "config", config)
+		}
+
+		tools.SetMappingValue(config, "optional", scalar("!!bool", "true"))
+	}
+
+	return nil
+}
+
+// encode writes the config back with the indentation of project.yaml examples
+func encode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// canonical adds the v prefix semver expects
+func canonical(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+
+	return "v" + version
+}
+
+// versionLess reports whether generator version a is older than b
+func versionLess(a, b string) bool {
+	return semver.Compare(canonical(a), canonical(b)) < 0
+}
@@ -0,0 +1,60 @@
+package migrate
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRules_Ordered(t *testing.T) {
+	for i := 1; i < len(rules); i++ {
+		if versionLess(rules[i].DeprecatedIn, rules[i-1].DeprecatedIn) {
+			t.Errorf("rule %q (%s) is after %q (%s)", rules[i].Feature, rules[i].DeprecatedIn, rules[i-1].Feature, rules[i-1].DeprecatedIn)
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Feature == "" || rule.Path == "" || rule.Match == nil || rule.Fix == nil || rule.MigrationHint == "" {
+			t.Errorf("rule %+v is incomplete", rule)
+		}
+	}
+}
+
+func TestSelectNodes(t *testing.T) {
+	var doc yaml.Node
+
+	config := "applications:\n  - name: a\n    transport: [x, y]\n  - name: b\n  - name: c\n    transport: [z]\n"
+	if err := yaml.Unmarshal([]byte(config), &doc); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	var got []string
+	for _, node := range selectNodes(&doc, "applications.*.transport.*") {
+		got = append(got, node.Value)
+	}
+
+	if len(got) != 3 || got[0] != "x" || got[1] != "y" || got[2] != "z" {
+		t.Errorf("selectNodes() = %v, want [x y z]", got)
+	}
+
+	if got := selectNodes(&doc, "rest.*"); len(got) != 0 {
+		t.Errorf("selectNodes() of a missing key = %v", got)
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"0.9.0", "0.10.0", true},
+		{"0.12.0", "v0.12.0", false},
+		{"0.13.0", "0.12.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := versionLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("versionLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package setup
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 
@@ -56,6 +57,8 @@ func New(opts Options) (*Setup, error) {
 		configDir = filepath.Join(opts.TargetDir, configDir)
 	}
 
+	slog.Debug("load setup config", "dir", configDir)
+
 	// Load setup config (or defaults)
 	setupCfg, err := LoadConfig(configDir)
 	if err != nil {
@@ -64,6 +67,8 @@ func New(opts Options) (*Setup, error) {
 
 	// Load project config for application info
 	projectCfg, err := config.GetConfig(configDir, "project.yaml")
+	projectCfg.LogDeprecations(slog.Default())
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to load project config: %w", err)
 	}
@@ -79,6 +84,8 @@ func New(opts Options) (*Setup, error) {
 
 // Run executes the setup command
 func (s *Setup) Run(cmd Command) error {
+	slog.Debug("run setup", "command", string(cmd), "target", s.TargetDir, "dry_run", s.DryRun)
+
 	switch cmd {
 	case CommandAll:
 		return s.runFullWizard()
@@ -1,6 +1,8 @@
 package templater
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -21,6 +23,15 @@ const (
 	extService = ".service"
 )
 
+// Disclaimer comment styles for content generated outside of templates, e.g. by plugins
+const (
+	CommentAuto  = "auto"  // chosen by file extension, as for templates
+	CommentHash  = "hash"  // # ...
+	CommentSlash = "slash" // // ...
+	CommentDash  = "dash"  // -- ...
+	CommentHTML  = "html"  // <!-- ... -->
+)
+
 func isFileIgnored(fName string) bool {
 	switch fName {
 	case ".keep":
@@ -80,7 +91,6 @@ func makeFinishDisclaimer(fName string) (string, error) {
 
 func makeComment(fName, text string) (string, error) {
 	var (
-		res         string
 		commPrefix  string
 		commPostfix string = ""
 	)
@@ -134,9 +144,80 @@ func makeComment(fName, text string) (string, error) {
 		return "", fmt.Errorf("unknown ext: %s", fName)
 	}
 
+	return formatComment(commPrefix, commPostfix, text), nil
+}
+
+func formatComment(commPrefix, commPostfix, text string) string {
+	res := ""
+
 	for _, ln := range strings.Split(text, "\n") {
 		res += commPrefix + " " + ln + " " + commPostfix + "\n"
 	}
 
-	return res, nil
+	return res
+}
+
+// makeStyledDisclaimers returns the start and finish disclaimers for fName commented in style.
+// CommentAuto picks the comment syntax by file extension.
+func makeStyledDisclaimers(fName, style string) (string, string, error) {
+	var commPrefix, commPostfix string
+
+	switch style {
+	case "", CommentAuto:
+		start, err := makeStartDisclaimer(fName)
+		if err != nil {
+			return "", "", err
+		}
+
+		finish, err := makeFinishDisclaimer(fName)
+
+		return start, finish, err
+	case CommentHash:
+		commPrefix = "#"
+	case CommentSlash:
+		commPrefix = "//"
+	case CommentDash:
+		commPrefix = "--"
+	case CommentHTML:
+		commPrefix, commPostfix = "<!--", "-->"
+	default:
+		return "", "", fmt.Errorf("unknown comment style: %s", style)
+	}
+
+	if isFileIgnored(filepath.Base(fName)) {
+		return "", "", nil
+	}
+
+	return formatComment(commPrefix, commPostfix, disclaimerTop), formatComment(commPrefix, commPostfix, disclaimerBottom), nil
+}
+
+// GeneratedPart returns the part of a file owned by the generator: everything up to and including
+// the disclaimer line except the bodies of user code regions. Files without disclaimer support are
+// owned by the generator entirely.
+func GeneratedPart(fName string, content []byte) []byte {
+	genCode, _ := SplitGenerated(fName, content)
+
+	return []byte(stripRegions(string(genCode)))
+}
+
+// SplitGenerated splits a file into the part up to and including the disclaimer line and the user
+// code after it. Files without disclaimer support or without the disclaimer have no user code.
+func SplitGenerated(fName string, content []byte) ([]byte, []byte) {
+	if isFileIgnored(filepath.Base(fName)) {
+		return content, nil
+	}
+
+	genCode, userCode, err := splitDisclaimer(string(content))
+	if err != nil {
+		return content, nil
+	}
+
+	return []byte(genCode), []byte(userCode)
+}
+
+// GeneratedChecksum returns the checksum of the generated part of a file as stored in meta.yaml
+func GeneratedChecksum(fName string, content []byte) string {
+	sum := sha256.Sum256(GeneratedPart(fName, content))
+
+	return "sha256:" + hex.EncodeToString(sum[:])
 }
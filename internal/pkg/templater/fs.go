@@ -5,6 +5,7 @@ import (
 	"embed"
 	"io/fs"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/Educentr/go-project-starter/internal/pkg/ds"
@@ -25,11 +26,12 @@ const (
 	ciGitLabFileName = ".gitlab-ci.yml"
 
 	// Embedded template path prefixes (always use forward slashes for embed.FS)
-	embedTransportPrefix = "embedded/templates/transport"
-	embedWorkerPrefix    = "embedded/templates/worker"
-	embedConfigSuffix    = "config"
-	embedFilesSuffix     = "files"
-	embedSharedSuffix    = "shared"
+	embedTransportPrefix  = "embedded/templates/transport"
+	embedWorkerPrefix     = "embedded/templates/worker"
+	embedRepositoryPrefix = "embedded/templates/repository"
+	embedConfigSuffix     = "config"
+	embedFilesSuffix      = "files"
+	embedSharedSuffix     = "shared"
 )
 
 //go:embed all:embedded
@@ -48,14 +50,14 @@ func embedJoin(elem ...string) string {
 	return strings.Join(elem, "/")
 }
 
-func GetTemplates(templateFS embed.FS, prefix string, params any) (dirs []ds.Files, files []ds.Files, err error) {
+func GetTemplates(fsys fs.FS, prefix string, params any) (dirs []ds.Files, files []ds.Files, err error) {
 	dirs = []ds.Files{}
 	files = []ds.Files{}
 
 	// embed.FS always uses forward slashes, even on Windows
 	trimCnt := len(strings.Split(prefix, "/"))
 
-	err = fs.WalkDir(templateFS, prefix, func(path string, d fs.DirEntry, err error) error {
+	err = fs.WalkDir(fsys, prefix, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -90,8 +92,8 @@ func GetTemplates(templateFS embed.FS, prefix string, params any) (dirs []ds.Fil
 	return
 }
 
-func GetMainTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, "embedded/templates/main", params)
+func (t *Templates) GetMainTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/main", params)
 	if err != nil {
 		err = errors.Wrap(err, "error while get main templates")
 		return
@@ -189,23 +191,32 @@ func GetMainTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files
 	return
 }
 
-// GetDocsTemplates returns documentation templates (mkdocs.yml, docs/index.md).
+// GetDocsTemplates returns documentation templates (mkdocs.yml, docs/index.md, docs/topology.md).
 // Returns nil if documentation is not enabled.
-func GetDocsTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+func (t *Templates) GetDocsTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
 	if !params.Documentation.IsEnabled() {
 		return nil, nil, nil
 	}
 
-	dirs, files, err = GetTemplates(templates, "embedded/templates/docs", params)
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/docs", params)
 	if err != nil {
 		err = errors.Wrap(err, "error while get docs templates")
+
+		return
+	}
+
+	// The topology page is generated only when documentation.topology is set
+	if params.Documentation.Topology == "" {
+		files = slices.DeleteFunc(files, func(f ds.Files) bool {
+			return f.DestName == filepath.Join("docs", "topology.md")
+		})
 	}
 
 	return
 }
 
-func GetLoggerTemplates(path string, dst string, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, embedJoin("embedded/templates/logger", path), params)
+func (t *Templates) GetLoggerTemplates(path string, dst string, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, embedJoin("embedded/templates/logger", path), params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			// Logger templates moved to runtime, return empty
@@ -228,8 +239,8 @@ func GetLoggerTemplates(path string, dst string, params GeneratorParams) (dirs [
 	return
 }
 
-func GetWorkerTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, embedJoin(embedWorkerPrefix, embedFilesSuffix), params)
+func (t *Templates) GetWorkerTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, embedJoin(embedWorkerPrefix, embedFilesSuffix), params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -245,8 +256,8 @@ func GetWorkerTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Fil
 	return
 }
 
-func GetWorkerGeneratorTemplates(generatorType string, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, embedJoin(embedWorkerPrefix, generatorType, embedConfigSuffix), params)
+func (t *Templates) GetWorkerGeneratorTemplates(generatorType string, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, embedJoin(embedWorkerPrefix, generatorType, embedConfigSuffix), params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -262,8 +273,8 @@ func GetWorkerGeneratorTemplates(generatorType string, params GeneratorParams) (
 	return
 }
 
-func GetTransportTemplates(transportType ds.TransportType, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, embedJoin(embedTransportPrefix, string(transportType), embedFilesSuffix), params)
+func (t *Templates) GetTransportTemplates(transportType ds.TransportType, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, embedJoin(embedTransportPrefix, string(transportType), embedFilesSuffix), params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -279,8 +290,8 @@ func GetTransportTemplates(transportType ds.TransportType, params GeneratorParam
 	return
 }
 
-func GetTransportGeneratorTemplates(transportType ds.TransportType, generatorType string, params GeneratorHandlerParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, embedJoin(embedTransportPrefix, string(transportType), generatorType, embedConfigSuffix), params)
+func (t *Templates) GetTransportGeneratorTemplates(transportType ds.TransportType, generatorType string, params GeneratorHandlerParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, embedJoin(embedTransportPrefix, string(transportType), generatorType, embedConfigSuffix), params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -331,7 +342,7 @@ var (
 // 	}
 // )
 
-func GetWorkerRunnerTemplates(template string, params GeneratorRunnerParams) (dirs, files []ds.Files, err error) {
+func (t *Templates) GetWorkerRunnerTemplates(template string, params GeneratorRunnerParams) (dirs, files []ds.Files, err error) {
 	cacheKey := embedJoin(embedWorkerPrefix, template, embedFilesSuffix)
 
 	// ToDo если включить кеширование то кешируются и параметры, а не только файлы
@@ -342,7 +353,7 @@ func GetWorkerRunnerTemplates(template string, params GeneratorRunnerParams) (di
 	// 	return v.dirs, v.files, nil
 	// }
 
-	dirs, files, err = GetTemplates(templates, cacheKey, params)
+	dirs, files, err = GetTemplates(t.fsys, cacheKey, params)
 	if err != nil {
 		err = errors.Wrapf(err, "error while get worker runner templates `%s`", cacheKey)
 
@@ -363,8 +374,8 @@ func GetWorkerRunnerTemplates(template string, params GeneratorRunnerParams) (di
 
 }
 
-func GetWorkerRunnerSharedTemplates(template string, params GeneratorParams) (dirs, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, embedJoin(embedWorkerPrefix, template, embedSharedSuffix), params)
+func (t *Templates) GetWorkerRunnerSharedTemplates(template string, params GeneratorParams) (dirs, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, embedJoin(embedWorkerPrefix, template, embedSharedSuffix), params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -380,7 +391,7 @@ func GetWorkerRunnerSharedTemplates(template string, params GeneratorParams) (di
 	return
 }
 
-func GetTransportHandlerTemplates(transport ds.TransportType, template string, params GeneratorHandlerParams) (dirs, files []ds.Files, err error) {
+func (t *Templates) GetTransportHandlerTemplates(transport ds.TransportType, template string, params GeneratorHandlerParams) (dirs, files []ds.Files, err error) {
 	cacheKey := embedJoin(embedTransportPrefix, string(transport), template, embedFilesSuffix)
 
 	// ToDo если включить кеширование то кешируются и параметры, а не только файлы
@@ -391,7 +402,7 @@ func GetTransportHandlerTemplates(transport ds.TransportType, template string, p
 	// 	return v.dirs, v.files, nil
 	// }
 
-	dirs, files, err = GetTemplates(templates, cacheKey, params)
+	dirs, files, err = GetTemplates(t.fsys, cacheKey, params)
 	if err != nil {
 		err = errors.Wrapf(err, "error while get transport handler templates `%s`", cacheKey)
 
@@ -412,8 +423,8 @@ func GetTransportHandlerTemplates(transport ds.TransportType, template string, p
 
 }
 
-func GetAppTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, "embedded/templates/app/files", params)
+func (t *Templates) GetAppTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/app/files", params)
 	if err != nil {
 		err = errors.Wrap(err, "error while get app templates")
 
@@ -449,7 +460,7 @@ func GetAppTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Fil
 		cmdTemplateDir = "embedded/templates/app/cmd_cli"
 	}
 
-	dirsC, filesC, err := GetTemplates(templates, cmdTemplateDir, params)
+	dirsC, filesC, err := GetTemplates(t.fsys, cmdTemplateDir, params)
 	if err != nil {
 		err = errors.Wrap(err, "error while get app templates")
 
@@ -477,7 +488,7 @@ type GeneratorCLIParams struct {
 }
 
 // GetCLIHandlerTemplates returns templates for CLI handler
-func GetCLIHandlerTemplates(cli *ds.CLIApp, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+func (t *Templates) GetCLIHandlerTemplates(cli *ds.CLIApp, params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
 	cliParams := GeneratorCLIParams{
 		GeneratorParams: params,
 		CLI:             cli,
@@ -491,7 +502,7 @@ func GetCLIHandlerTemplates(cli *ds.CLIApp, params GeneratorParams) (dirs []ds.F
 
 	templatePath := embedJoin(embedTransportPrefix, "cli", generatorType, embedFilesSuffix)
 
-	dirs, files, err = GetTemplates(templates, templatePath, cliParams)
+	dirs, files, err = GetTemplates(t.fsys, templatePath, cliParams)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -516,8 +527,8 @@ func GetCLIHandlerTemplates(cli *ds.CLIApp, params GeneratorParams) (dirs []ds.F
 }
 
 // GetGrafanaProvisioningTemplates returns Grafana provisioning templates (datasources and dashboard provider config)
-func GetGrafanaProvisioningTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, "embedded/templates/grafana/provisioning", params)
+func (t *Templates) GetGrafanaProvisioningTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/grafana/provisioning", params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -543,8 +554,8 @@ func GetGrafanaProvisioningTemplates(params GeneratorParams) (dirs []ds.Files, f
 }
 
 // GetGrafanaDashboardTemplates returns Grafana dashboard templates for an application
-func GetGrafanaDashboardTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, "embedded/templates/grafana/dashboards", params)
+func (t *Templates) GetGrafanaDashboardTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/grafana/dashboards", params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -576,8 +587,8 @@ func GetGrafanaDashboardTemplates(params GeneratorAppParams) (dirs []ds.Files, f
 }
 
 // GetPrometheusTemplates returns Prometheus configuration templates for dev environment
-func GetPrometheusTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, "embedded/templates/dev-infra/prometheus", params)
+func (t *Templates) GetPrometheusTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/dev-infra/prometheus", params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -603,8 +614,8 @@ func GetPrometheusTemplates(params GeneratorParams) (dirs []ds.Files, files []ds
 }
 
 // GetLokiTemplates returns Loki configuration templates for dev environment
-func GetLokiTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
-	dirs, files, err = GetTemplates(templates, "embedded/templates/dev-infra/loki", params)
+func (t *Templates) GetLokiTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files, err error) {
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/dev-infra/loki", params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -630,7 +641,7 @@ func GetLokiTemplates(params GeneratorParams) (dirs []ds.Files, files []ds.Files
 }
 
 // GetTestTemplates returns GOAT integration test templates for an application
-func GetTestTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Files, err error) {
+func (t *Templates) GetTestTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Files, err error) {
 	// Skip if test generation is not enabled
 	if !params.Application.GoatTests {
 		return nil, nil, nil
@@ -641,7 +652,7 @@ func GetTestTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Fi
 		return nil, nil, nil
 	}
 
-	dirs, files, err = GetTemplates(templates, "embedded/templates/tests/files", params)
+	dirs, files, err = GetTemplates(t.fsys, "embedded/templates/tests/files", params)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			err = nil
@@ -670,7 +681,7 @@ func GetTestTemplates(params GeneratorAppParams) (dirs []ds.Files, files []ds.Fi
 
 // GetKafkaDriverTemplates returns Kafka driver templates for auto-generated producers/consumers
 // kafkaType should be "producer" or "consumer"
-func GetKafkaDriverTemplates(kafka ds.KafkaConfig, params GeneratorParams) ([]ds.Files, []ds.Files, error) {
+func (t *Templates) GetKafkaDriverTemplates(kafka ds.KafkaConfig, params GeneratorParams) ([]ds.Files, []ds.Files, error) {
 	// Only generate templates for segmentio driver (not custom)
 	if kafka.IsCustomDriver() {
 		return nil, nil, nil
@@ -683,7 +694,7 @@ func GetKafkaDriverTemplates(kafka ds.KafkaConfig, params GeneratorParams) ([]ds
 
 	templatePath := embedJoin("embedded/templates/driver/kafka", kafka.Type, embedFilesSuffix)
 
-	dirs, files, err := GetTemplates(templates, templatePath, kafkaParams)
+	dirs, files, err := GetTemplates(t.fsys, templatePath, kafkaParams)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return nil, nil, nil
@@ -706,11 +717,69 @@ func GetKafkaDriverTemplates(kafka ds.KafkaConfig, params GeneratorParams) ([]ds
 	return dirs, files, nil
 }
 
+// GetRepositoryTemplates returns templates for a repository.
+// It generates:
+// - internal/pkg/repository/{name} - Repository interface and driver implementation
+// - db/{name} - sqlc config, schema and queries
+// - tests/mocks/repository/{name}/doc.go - mockgen directive for Repository
+func (t *Templates) GetRepositoryTemplates(repo ds.Repository, params GeneratorParams) ([]ds.Files, []ds.Files, error) {
+	repoParams := GeneratorRepositoryParams{
+		GeneratorParams: params,
+		Repository:      repo,
+	}
+
+	templatePath := embedJoin(embedRepositoryPrefix, repo.TypeDB, repo.DriverDB)
+
+	dirs, files, err := GetTemplates(t.fsys, templatePath, repoParams)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error while get repository templates for %s", repo.Name)
+	}
+
+	// Top-level template directories map to destination roots
+	destRoots := map[string]string{
+		embedFilesSuffix: filepath.Join("internal/pkg/repository", repo.Name),
+		"db":             filepath.Join("db", repo.Name),
+		"mocks":          filepath.Join(mocksPath, "repository", repo.Name),
+	}
+
+	remap := func(list []ds.Files) ([]ds.Files, error) {
+		result := make([]ds.Files, 0, len(list))
+
+		for _, f := range list {
+			if f.DestName == "" {
+				continue
+			}
+
+			top, rest, _ := strings.Cut(filepath.ToSlash(f.DestName), "/")
+
+			root, ok := destRoots[top]
+			if !ok {
+				return nil, errors.Errorf("unexpected repository template path %s", f.SourceName)
+			}
+
+			f.DestName = filepath.Join(root, rest)
+			result = append(result, f)
+		}
+
+		return result, nil
+	}
+
+	if dirs, err = remap(dirs); err != nil {
+		return nil, nil, err
+	}
+
+	if files, err = remap(files); err != nil {
+		return nil, nil, err
+	}
+
+	return dirs, files, nil
+}
+
 // GetMockTemplates returns mock templates for an application with ogen_clients.
 // It generates:
 // - tests/{app_name}/mocks.go - MockServers struct and MocksSetup
 // - tests/{app_name}/mocks/{transport_name}/doc.go - for each ogen_client transport
-func GetMockTemplates(params GeneratorAppParams) ([]ds.Files, []ds.Files, error) {
+func (t *Templates) GetMockTemplates(params GeneratorAppParams) ([]ds.Files, []ds.Files, error) {
 	// Skip if no ogen clients
 	if !params.Application.HasOgenClients() {
 		return nil, nil, nil
@@ -763,7 +832,7 @@ func GetMockTemplates(params GeneratorAppParams) ([]ds.Files, []ds.Files, error)
 
 // GetPackagingTemplates returns packaging templates for an application (nfpm, systemd, scripts).
 // It generates files per application when packaging artifacts (deb/rpm/apk) are enabled.
-func GetPackagingTemplates(params GeneratorAppParams) ([]ds.Files, []ds.Files, error) {
+func (t *Templates) GetPackagingTemplates(params GeneratorAppParams) ([]ds.Files, []ds.Files, error) {
 	// Skip if packaging is not enabled
 	if !params.Artifacts.HasPackaging() {
 		return nil, nil, nil
@@ -0,0 +1,238 @@
+package templater
+
+import (
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// embedTemplatesRoot is the embedded directory a templates overlay mirrors
+const embedTemplatesRoot = "embedded/templates"
+
+// Template origins reported by TemplateOrigin
+const (
+	OriginEmbedded = "embedded"
+	OriginOverlay  = "overlay"
+)
+
+// Templates is a set of templates: the embedded ones, optionally under an overlay directory.
+// Parsed templates are cached per set, so generators using different overlays can run concurrently.
+type Templates struct {
+	fsys       fs.FS // the embedded tree, under the overlay if there is one
+	overlay    fs.FS
+	overlayDir string
+	cache      *TemplateCache // shared by the copies of the set with other loggers
+	log        *slog.Logger   // slog.Default() if nil
+}
+
+// embeddedTemplates is the set without overlay, shared by all generators
+var embeddedTemplates = newTemplates(templates, nil, "")
+
+func newTemplates(fsys, overlay fs.FS, overlayDir string) *Templates {
+	return &Templates{
+		fsys:       fsys,
+		overlay:    overlay,
+		overlayDir: overlayDir,
+		cache:      &TemplateCache{templates: make(map[string]Template)},
+	}
+}
+
+// WithLogger returns the set logging to log, the cache of parsed templates is shared with t
+func (t *Templates) WithLogger(log *slog.Logger) *Templates {
+	withLog := *t
+	withLog.log = log
+
+	return &withLog
+}
+
+// logger returns the logger of the set
+func (t *Templates) logger() *slog.Logger {
+	if t.log == nil {
+		return slog.Default()
+	}
+
+	return t.log
+}
+
+// overlayFS serves files of overlay on top of base. Overlay paths are relative to embedTemplatesRoot:
+// <dir>/main/Makefile.tmpl shadows embedded/templates/main/Makefile.tmpl. Files present only in the
+// overlay are added to the directories they are in, so walking a template prefix picks them up.
+type overlayFS struct {
+	base    fs.FS
+	overlay fs.FS
+}
+
+// overlayName maps an embedded path to the overlay, ok is false for paths outside of embedTemplatesRoot
+func overlayName(name string) (string, bool) {
+	if name == embedTemplatesRoot {
+		return ".", true
+	}
+
+	if rest, ok := strings.CutPrefix(name, embedTemplatesRoot+"/"); ok {
+		return rest, true
+	}
+
+	return "", false
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	oName, ok := overlayName(name)
+	if !ok {
+		return o.base.Open(name)
+	}
+
+	if st, err := fs.Stat(o.overlay, oName); err == nil && !st.IsDir() {
+		return o.overlay.Open(oName)
+	}
+
+	f, err := o.base.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Directory added by the overlay
+		return o.overlay.Open(oName)
+	}
+
+	return f, err
+}
+
+func (o overlayFS) ReadFile(name string) ([]byte, error) {
+	f, err := o.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	baseEntries, baseErr := fs.ReadDir(o.base, name)
+
+	oName, ok := overlayName(name)
+	if !ok {
+		return baseEntries, baseErr
+	}
+
+	overlayEntries, overlayErr := fs.ReadDir(o.overlay, oName)
+
+	if baseErr != nil && overlayErr != nil {
+		return nil, baseErr
+	}
+
+	merged := make(map[string]fs.DirEntry, len(baseEntries)+len(overlayEntries))
+
+	for _, e := range baseEntries {
+		merged[e.Name()] = e
+	}
+
+	for _, e := range overlayEntries {
+		if prev, ok := merged[e.Name()]; ok && prev.IsDir() != e.IsDir() {
+			return nil, errors.Errorf("templates overlay: %s is a %s in overlay but not in embedded templates",
+				path.Join(oName, e.Name()), entryKind(e))
+		}
+
+		merged[e.Name()] = e
+	}
+
+	entries := make([]fs.DirEntry, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, nil
+}
+
+func entryKind(e fs.DirEntry) string {
+	if e.IsDir() {
+		return "directory"
+	}
+
+	return "file"
+}
+
+// NewTemplates returns the set with the templates in dir layered over the embedded ones, dir is read
+// from fsys or from the OS file system if fsys is nil. An empty dir gives the embedded templates only.
+// The set logs to log, slog.Default() if nil.
+func NewTemplates(fsys fs.FS, dir string, log *slog.Logger) (*Templates, error) {
+	if dir == "" {
+		return embeddedTemplates.WithLogger(log), nil
+	}
+
+	var overlay fs.FS = os.DirFS(dir)
+
+	if fsys != nil {
+		sub, err := fs.Sub(fsys, filepath.ToSlash(dir))
+		if err != nil {
+			return nil, errors.Wrap(err, "templates dir")
+		}
+
+		overlay = sub
+	}
+
+	st, err := fs.Stat(overlay, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "templates dir "+dir)
+	}
+
+	if !st.IsDir() {
+		return nil, errors.Errorf("templates dir %s is not a directory", dir)
+	}
+
+	set := newTemplates(overlayFS{base: templates, overlay: overlay}, overlay, dir).WithLogger(log)
+	set.logger().Debug("templates overlay", "dir", dir)
+
+	return set, nil
+}
+
+// TemplateOrigin reports whether a template source is served from the overlay or the embedded tree,
+// with the path to show for it
+func (t *Templates) TemplateOrigin(sourceName string) (string, string) {
+	rel := strings.TrimPrefix(sourceName, embedTemplatesRoot+"/")
+
+	if t.overlay != nil {
+		if oName, ok := overlayName(sourceName); ok {
+			if st, err := fs.Stat(t.overlay, oName); err == nil && !st.IsDir() {
+				return OriginOverlay, filepath.Join(t.overlayDir, filepath.FromSlash(oName))
+			}
+		}
+	}
+
+	return OriginEmbedded, rel
+}
+
+// OverlayTemplates returns the files of the overlay as embedded source names, sorted
+func (t *Templates) OverlayTemplates() ([]string, error) {
+	if t.overlay == nil {
+		return nil, nil
+	}
+
+	names := []string{}
+
+	err := fs.WalkDir(t.overlay, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			names = append(names, path.Join(embedTemplatesRoot, p))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "walk templates dir")
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
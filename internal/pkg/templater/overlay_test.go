@@ -0,0 +1,159 @@
+package templater
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func writeOverlayFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestTemplates(t *testing.T, dir string) *Templates {
+	t.Helper()
+
+	tmpls, err := NewTemplates(nil, dir, nil)
+	if err != nil {
+		t.Fatalf("NewTemplates() error = %v", err)
+	}
+
+	return tmpls
+}
+
+func TestNewTemplates(t *testing.T) {
+	dir := t.TempDir()
+
+	writeOverlayFile(t, dir, "main/Makefile.tmpl", "overlay makefile\n")
+	writeOverlayFile(t, dir, "main/extra.txt.tmpl", "extra\n")
+	writeOverlayFile(t, dir, "main/newdir/notes.md.tmpl", "notes\n")
+
+	tmpls := newTestTemplates(t, dir)
+
+	t.Run("overlay file shadows embedded one", func(t *testing.T) {
+		tmpl, err := tmpls.GetTemplate("embedded/templates/main/Makefile.tmpl")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tmpl.Tmpl != "overlay makefile\n" {
+			t.Errorf("GetTemplate() = %q, want overlay content", tmpl.Tmpl)
+		}
+
+		// Other sets are not affected by the overlay
+		embedded, err := GetTemplate("embedded/templates/main/Makefile.tmpl")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if embedded.Tmpl == tmpl.Tmpl {
+			t.Error("GetTemplate() of the embedded set returns overlay content")
+		}
+
+		origin, source := tmpls.TemplateOrigin("embedded/templates/main/Makefile.tmpl")
+		if origin != OriginOverlay || source != filepath.Join(dir, "main", "Makefile.tmpl") {
+			t.Errorf("TemplateOrigin() = %s, %s", origin, source)
+		}
+	})
+
+	t.Run("embedded files without overlay are kept", func(t *testing.T) {
+		origin, source := tmpls.TemplateOrigin("embedded/templates/main/.gitignore.tmpl")
+		if origin != OriginEmbedded || source != "main/.gitignore.tmpl" {
+			t.Errorf("TemplateOrigin() = %s, %s", origin, source)
+		}
+	})
+
+	t.Run("overlay adds files and directories", func(t *testing.T) {
+		dirs, files, err := GetTemplates(tmpls.fsys, "embedded/templates/main", nil)
+		if err != nil {
+			t.Fatalf("GetTemplates() error = %v", err)
+		}
+
+		found := map[string]bool{}
+		for _, f := range files {
+			found[filepath.ToSlash(f.DestName)] = true
+		}
+
+		for _, want := range []string{"Makefile", ".gitignore", "extra.txt", "newdir/notes.md"} {
+			if !found[want] {
+				t.Errorf("GetTemplates() has no %s", want)
+			}
+		}
+
+		hasDir := false
+		for _, d := range dirs {
+			if d.DestName == "newdir" {
+				hasDir = true
+			}
+		}
+
+		if !hasDir {
+			t.Error("GetTemplates() has no newdir directory")
+		}
+	})
+
+	t.Run("overlay templates are listed", func(t *testing.T) {
+		names, err := tmpls.OverlayTemplates()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "embedded/templates/main/Makefile.tmpl,embedded/templates/main/extra.txt.tmpl,embedded/templates/main/newdir/notes.md.tmpl"
+		if got := strings.Join(names, ","); got != want {
+			t.Errorf("OverlayTemplates() = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestNewTemplates_Errors(t *testing.T) {
+	if _, err := NewTemplates(nil, filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Error("NewTemplates() with missing directory succeeded")
+	}
+
+	dir := t.TempDir()
+
+	// main/scripts is a directory in the embedded tree
+	writeOverlayFile(t, dir, "main/scripts", "not a directory\n")
+
+	tmpls := newTestTemplates(t, dir)
+
+	if _, _, err := GetTemplates(tmpls.fsys, "embedded/templates/main", nil); err == nil {
+		t.Error("GetTemplates() with file shadowing a directory succeeded")
+	}
+}
+
+func TestNewTemplates_FS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/templates/main/Makefile.tmpl": {Data: []byte("fs makefile\n")},
+	}
+
+	tmpls, err := NewTemplates(fsys, "config/templates", nil)
+	if err != nil {
+		t.Fatalf("NewTemplates() error = %v", err)
+	}
+
+	tmpl, err := tmpls.GetTemplate("embedded/templates/main/Makefile.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tmpl.Tmpl != "fs makefile\n" {
+		t.Errorf("GetTemplate() = %q, want overlay content", tmpl.Tmpl)
+	}
+
+	if _, err := NewTemplates(fsys, "config/missing", nil); err == nil {
+		t.Error("NewTemplates() with missing directory succeeded")
+	}
+}
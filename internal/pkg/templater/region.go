@@ -0,0 +1,164 @@
+package templater
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// regionRx matches a line marking the begin or end of a named user code region. The marker is
+// the only text on its line, commented in the syntax of the file:
+//
+//	// psg:begin:imports
+//	// psg:end:imports
+var regionRx = regexp.MustCompile(`^[ \t]*(?://|#|--|<!--)?[ \t]*psg:(begin|end):([A-Za-z0-9_.-]+)[ \t]*(?:-->)?[ \t]*\r?$`)
+
+// region is a named user code region, its body is content[begin:end]
+type region struct {
+	id    string
+	begin int
+	end   int
+}
+
+// findRegions returns the regions of content in order. Regions can not be nested and their ids
+// must be unique in a file.
+func findRegions(content string) ([]region, error) {
+	regions := []region{}
+	seen := make(map[string]struct{})
+
+	var open *region
+
+	for offset := 0; offset < len(content); {
+		lineEnd := strings.IndexByte(content[offset:], '\n')
+		next := len(content)
+
+		if lineEnd != -1 {
+			next = offset + lineEnd + 1
+		}
+
+		m := regionRx.FindStringSubmatch(strings.TrimSuffix(content[offset:next], "\n"))
+		if m == nil {
+			offset = next
+
+			continue
+		}
+
+		kind, id := m[1], m[2]
+
+		switch {
+		case kind == "begin" && open != nil:
+			return nil, errors.Errorf("region %s begins before region %s ends", id, open.id)
+		case kind == "begin":
+			if _, ex := seen[id]; ex {
+				return nil, errors.Errorf("duplicate region %s", id)
+			}
+
+			seen[id] = struct{}{}
+			open = &region{id: id, begin: next}
+		case open == nil || open.id != id:
+			return nil, errors.Errorf("end of region %s without begin", id)
+		default:
+			open.end = offset
+			regions = append(regions, *open)
+			open = nil
+		}
+
+		offset = next
+	}
+
+	if open != nil {
+		return nil, errors.Errorf("region %s is not closed", open.id)
+	}
+
+	return regions, nil
+}
+
+// ExtractRegions returns the bodies of the user code regions in the generated part of a file by id
+func ExtractRegions(genCode string) (map[string]string, error) {
+	regions, err := findRegions(genCode)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make(map[string]string, len(regions))
+	for _, r := range regions {
+		bodies[r.id] = genCode[r.begin:r.end]
+	}
+
+	return bodies, nil
+}
+
+// InjectRegions replaces the bodies of the regions in the generated part of code with the saved ones.
+// Regions the new code does not declare keep their default body. A saved region with code that the
+// new code does not declare any more is an error: the code would be lost.
+func InjectRegions(code []byte, saved map[string]string) ([]byte, error) {
+	if len(saved) == 0 {
+		return code, nil
+	}
+
+	genCode, userCode, err := splitDisclaimer(string(code))
+	if err != nil {
+		genCode, userCode = string(code), ""
+	}
+
+	regions, err := findRegions(genCode)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := make(map[string]struct{}, len(regions))
+	for _, r := range regions {
+		declared[r.id] = struct{}{}
+	}
+
+	for id, body := range saved {
+		if _, ex := declared[id]; !ex && strings.TrimSpace(body) != "" {
+			return nil, errors.Errorf("region %s with user code is not declared by the template any more", id)
+		}
+	}
+
+	var res strings.Builder
+
+	res.Grow(len(code))
+
+	offset := 0
+
+	for _, r := range regions {
+		body, ok := saved[r.id]
+		if !ok {
+			continue
+		}
+
+		res.WriteString(genCode[offset:r.begin])
+		res.WriteString(body)
+		offset = r.end
+	}
+
+	res.WriteString(genCode[offset:])
+	res.WriteString(userCode)
+
+	return []byte(res.String()), nil
+}
+
+// stripRegions empties the bodies of the regions in the generated part of a file.
+// Region bodies belong to the user, so they are not a part of the generated checksum.
+func stripRegions(genCode string) string {
+	regions, err := findRegions(genCode)
+	if err != nil || len(regions) == 0 {
+		return genCode
+	}
+
+	var res strings.Builder
+
+	offset := 0
+
+	for _, r := range regions {
+		res.WriteString(genCode[offset:r.begin])
+		offset = r.end
+	}
+
+	res.WriteString(genCode[offset:])
+
+	return res.String()
+}
@@ -0,0 +1,163 @@
+package templater
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:    "no regions",
+			content: "package main\n",
+			want:    map[string]string{},
+		},
+		{
+			name:    "regions in different comment styles",
+			content: "a\n\t// psg:begin:imports\n\t\"strings\"\n\t// psg:end:imports\n# psg:begin:env\nA=1\n# psg:end:env\n<!-- psg:begin:docs -->\n<!-- psg:end:docs -->\n",
+			want:    map[string]string{"imports": "\t\"strings\"\n", "env": "A=1\n", "docs": ""},
+		},
+		{
+			name:    "marker must be alone on its line",
+			content: "s := \"psg:begin:x\" // psg:begin:y\n",
+			want:    map[string]string{},
+		},
+		{
+			name:    "CRLF line endings",
+			content: "// psg:begin:x\r\nfoo\r\n// psg:end:x\r\n",
+			want:    map[string]string{"x": "foo\r\n"},
+		},
+		{
+			name:    "nested",
+			content: "// psg:begin:a\n// psg:begin:b\n// psg:end:b\n// psg:end:a\n",
+			wantErr: "region b begins before region a ends",
+		},
+		{
+			name:    "duplicate",
+			content: "// psg:begin:a\n// psg:end:a\n// psg:begin:a\n// psg:end:a\n",
+			wantErr: "duplicate region a",
+		},
+		{
+			name:    "end without begin",
+			content: "// psg:end:a\n",
+			wantErr: "end of region a without begin",
+		},
+		{
+			name:    "not closed",
+			content: "// psg:begin:a\nfoo\n",
+			wantErr: "region a is not closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractRegions(tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExtractRegions() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ExtractRegions() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ExtractRegions() = %q, want %q", got, tt.want)
+			}
+
+			for id, body := range tt.want {
+				if got[id] != body {
+					t.Errorf("ExtractRegions()[%s] = %q, want %q", id, got[id], body)
+				}
+			}
+		})
+	}
+}
+
+func TestInjectRegions(t *testing.T) {
+	disclaimerLine := "// " + disclaimer + "\n"
+	code := "import (\n\t// psg:begin:imports\n\t// psg:end:imports\n)\n\nvar (\n\t// psg:begin:vars\n\tdefaultVar = 1\n\t// psg:end:vars\n)\n" + disclaimerLine
+
+	tests := []struct {
+		name    string
+		code    string
+		saved   map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "nothing saved",
+			code:  code,
+			saved: nil,
+			want:  code,
+		},
+		{
+			name:  "saved bodies replace defaults",
+			code:  code,
+			saved: map[string]string{"imports": "\t\"strings\"\n", "vars": ""},
+			want:  "import (\n\t// psg:begin:imports\n\t\"strings\"\n\t// psg:end:imports\n)\n\nvar (\n\t// psg:begin:vars\n\t// psg:end:vars\n)\n" + disclaimerLine,
+		},
+		{
+			name:  "regions after the disclaimer are user code",
+			code:  code + "// psg:begin:imports\n// psg:end:imports\n",
+			saved: map[string]string{"imports": "\t\"os\"\n"},
+			want:  "import (\n\t// psg:begin:imports\n\t\"os\"\n\t// psg:end:imports\n)\n\nvar (\n\t// psg:begin:vars\n\tdefaultVar = 1\n\t// psg:end:vars\n)\n" + disclaimerLine + "// psg:begin:imports\n// psg:end:imports\n",
+		},
+		{
+			name:  "empty region removed from template",
+			code:  code,
+			saved: map[string]string{"fields": "\n"},
+			want:  code,
+		},
+		{
+			name:    "region with code removed from template",
+			code:    code,
+			saved:   map[string]string{"fields": "\tName string\n"},
+			wantErr: "region fields with user code is not declared by the template any more",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InjectRegions([]byte(tt.code), tt.saved)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("InjectRegions() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("InjectRegions() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("InjectRegions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratedChecksum_Regions(t *testing.T) {
+	disclaimerLine := "// " + disclaimer + "\n"
+	generated := "import (\n\t// psg:begin:imports\n\t// psg:end:imports\n)\n" + disclaimerLine
+	withRegion := "import (\n\t// psg:begin:imports\n\t\"strings\"\n\t// psg:end:imports\n)\n" + disclaimerLine
+	edited := "import (\n\t\"os\"\n\t// psg:begin:imports\n\t// psg:end:imports\n)\n" + disclaimerLine
+
+	if GeneratedChecksum("main.go", []byte(generated)) != GeneratedChecksum("main.go", []byte(withRegion)) {
+		t.Errorf("code in a region changed the generated checksum")
+	}
+
+	if GeneratedChecksum("main.go", []byte(generated)) == GeneratedChecksum("main.go", []byte(edited)) {
+		t.Errorf("code outside of regions did not change the generated checksum")
+	}
+}
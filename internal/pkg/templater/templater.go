@@ -14,6 +14,7 @@ import (
 
 	"github.com/Educentr/go-project-starter/internal/pkg/ds"
 	"github.com/Educentr/go-project-starter/internal/pkg/grafana"
+	"github.com/Educentr/go-project-starter/internal/pkg/layout"
 	"github.com/pkg/errors"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -53,6 +54,7 @@ type GeneratorParams struct {
 	Workers             ds.Workers
 	JSONSchemas         ds.JSONSchemas
 	Kafka               ds.KafkaConfigs
+	Repositories        ds.Repositories
 	// GRPCVersion   string
 	// Transports ds.Transtorts
 	// Models ???
@@ -67,10 +69,10 @@ type GeneratorParams struct {
 // 	ImportPath  string
 // }
 
+// GeneratorRepositoryParams holds parameters for repository template generation
 type GeneratorRepositoryParams struct {
-	Name       string //unused
-	TypeDB     string //unused
-	DriverName string //unused
+	GeneratorParams
+	Repository ds.Repository
 }
 
 type GeneratorAppParams struct {
@@ -102,6 +104,7 @@ type GeneratorKafkaParams struct {
 type Template struct {
 	Name string
 	Tmpl string
+	body *template.Template // Tmpl parsed by GetTemplate, nil for templates made by hand
 }
 
 type TemplateCache struct {
@@ -119,33 +122,55 @@ var (
 	// importErrRx = regexp.MustCompile(`(\d+):(\d+): `)
 )
 
-var cache = TemplateCache{
-	templates: make(map[string]Template),
+// GetTemplate reads and parses an embedded template, see Templates.GetTemplate
+func GetTemplate(filename string) (Template, error) {
+	return embeddedTemplates.GetTemplate(filename)
 }
 
-func GetTemplate(filename string) (Template, error) {
-	cache.Lock()
-	defer cache.Unlock()
+// GetTemplate reads and parses a template of the set once, later calls get it from the cache.
+// Parsing is done without the lock: files are rendered concurrently and most templates are distinct.
+func (t *Templates) GetTemplate(filename string) (Template, error) {
+	t.cache.Lock()
+	tmpl, ok := t.cache.templates[filename]
+	t.cache.Unlock()
 
-	if tmpl, ok := cache.templates[filename]; ok {
+	if ok {
 		return tmpl, nil
 	}
 
-	file, err := templates.ReadFile(filename)
+	file, err := fs.ReadFile(t.fsys, filename)
 	if err != nil {
 		return Template{}, fmt.Errorf("failed to read %s: %w", filename, err)
 	}
 
-	tmpl := Template{
+	body, err := parseTemplate(filename, string(file))
+	if err != nil {
+		return Template{}, err
+	}
+
+	origin, source := t.TemplateOrigin(filename)
+	t.logger().Debug("parse template", "template", source, "origin", origin)
+
+	tmpl = Template{
 		Name: filename,
 		Tmpl: string(file),
+		body: body,
 	}
 
-	cache.templates[filename] = tmpl
+	t.cache.Lock()
+	defer t.cache.Unlock()
+
+	if cached, ok := t.cache.templates[filename]; ok {
+		return cached, nil
+	}
+
+	t.cache.templates[filename] = tmpl
 
 	return tmpl, nil
 }
 
+// GenerateFilenameByTmpl renders the name of the file into DestName and sets OldDestName to the name
+// the file has in a project of layout version lastVer
 func GenerateFilenameByTmpl(file *ds.Files, targetPath string, lastVer int) error {
 	buf := new(strings.Builder)
 
@@ -180,26 +205,8 @@ func GenerateFilenameByTmpl(file *ds.Files, targetPath string, lastVer int) erro
 		}
 	}
 
-	oldFileName := destFileName
-
-	// ToDo сделать миграции
-	switch {
-	case lastVer < 2:
-		oldFileName = buf.String()
-	case lastVer < 3:
-		if strings.Contains(destFileName, "pkg/ds") {
-			oldFileName = strings.ReplaceAll(destFileName, "pkg/ds", "pkg/app/ds")
-		}
-	case lastVer < 4:
-		// Migration v3→v4: tests/{app_name}/{file} -> old location was tests/{file}
-		if strings.HasPrefix(destFileName, "tests/") {
-			parts := strings.SplitN(destFileName, "/", 3)
-			if len(parts) == 3 {
-				// New: tests/apibalancer/psg_config_gen.go → Old: tests/psg_config_gen.go
-				oldFileName = filepath.Join("tests", parts[2])
-			}
-		}
-	}
+	// The file may still have the name of the layout the project was generated with
+	oldFileName := layout.OldPath(filepath.ToSlash(destFileName), lastVer)
 
 	file.DestName = filepath.Join(targetPath, destFileName)
 	file.OldDestName = filepath.Join(targetPath, oldFileName)
@@ -212,12 +219,18 @@ func GenerateFilenameByTmpl(file *ds.Files, targetPath string, lastVer int) erro
 // CI должен проверять, что массив строк "никогда" не уменьшается, что бы не нарушать обратную совместимость
 const (
 	disclaimer = "If you need you can add your code after this message"
+
+	// StageDirPrefix names the directories a regeneration stages files in inside the target.
+	// A directory left by a killed run is not a part of the project.
+	StageDirPrefix = ".psg-stage-"
 )
 
 var (
 	ignoreExistingPath = []string{
 		".git/",
 		"docs/",
+		".project-config/backup/",    // copies of hand-edited generated files
+		".project-config/snapshots/", // generated parts of files from the last generation
 	}
 	ignoreIfExistsFiles = map[string]struct{}{
 		".gitignore":           {},
@@ -246,7 +259,61 @@ func isFileIgnore(path string) bool {
 	return false
 }
 
-func GetUserCodeFromFiles(targetDir string, files []ds.Files) (ds.FilesDiff, error) {
+// HasGeneratedFiles reports whether the target has a file with the generator disclaimer.
+// A missing target has none.
+func HasGeneratedFiles(targetDir string) (bool, error) {
+	found := false
+
+	err := fs.WalkDir(os.DirFS(targetDir), ".", func(relPath string, d fs.DirEntry, err error) error {
+		if d == nil && errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipAll
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if relPath == ".git" || relPath == ".project-config" || strings.HasPrefix(d.Name(), StageDirPrefix) {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(filepath.Join(targetDir, relPath))
+		if err != nil {
+			return err
+		}
+
+		if _, _, err := splitDisclaimer(string(content)); err == nil {
+			found = true
+
+			return fs.SkipAll
+		}
+
+		return nil
+	})
+
+	return found, err
+}
+
+// GetUserCodeFromFiles walks the target and sorts its files against the generated set.
+// User code below the disclaimer goes to UserContent, bodies of psg:begin/psg:end regions to UserRegions.
+// In adopt mode content that cannot be kept in place does not fail the walk: the whole content of
+// a generated file without disclaimer or with broken regions, or user code of a stale generated file,
+// goes to Orphans.
+func GetUserCodeFromFiles(targetDir string, files []ds.Files, adopt bool) (ds.FilesDiff, error) {
+	return GetUserCodeFromScope(targetDir, files, adopt, nil)
+}
+
+// GetUserCodeFromScope is GetUserCodeFromFiles for a selective regeneration: files of the target
+// for which inScope is false are left out of the diff. A nil inScope selects every file.
+func GetUserCodeFromScope(targetDir string, files []ds.Files, adopt bool, inScope func(path string) bool) (ds.FilesDiff, error) {
 	filesDiff := ds.FilesDiff{
 		NewFiles:       make(map[string]struct{}),
 		IgnoreFiles:    make(map[string]struct{}),
@@ -255,7 +322,9 @@ func GetUserCodeFromFiles(targetDir string, files []ds.Files) (ds.FilesDiff, err
 		ObsoleteFiles:  make(map[string]struct{}),
 		OtherDirectory: make(map[string]struct{}),
 		UserContent:    make(map[string][]byte),
+		UserRegions:    make(map[string]map[string]string),
 		RenameFiles:    make(map[string]string),
+		Orphans:        make(map[string][]byte),
 	}
 
 	for _, file := range files {
@@ -280,12 +349,25 @@ func GetUserCodeFromFiles(targetDir string, files []ds.Files) (ds.FilesDiff, err
 	foundDirs := make(map[string]struct{})
 
 	err := fs.WalkDir(os.DirFS(targetDir), ".", func(relPath string, d fs.DirEntry, err error) error {
+		// A new project: there is no target and no user code yet
+		if d == nil && errors.Is(err, fs.ErrNotExist) {
+			return fs.SkipAll
+		}
+
 		if err != nil {
 			return err
 		}
 
+		if d.IsDir() && strings.HasPrefix(d.Name(), StageDirPrefix) {
+			return fs.SkipDir
+		}
+
 		path := filepath.Join(targetDir, relPath)
 
+		if inScope != nil && !d.IsDir() && !inScope(path) {
+			return nil
+		}
+
 		if isFileIgnore(relPath) {
 			delete(filesDiff.NewFiles, path)
 			filesDiff.IgnoreFiles[path] = struct{}{}
@@ -324,15 +406,35 @@ func GetUserCodeFromFiles(targetDir string, files []ds.Files) (ds.FilesDiff, err
 				return err
 			}
 
-			_, userData, err := splitDisclaimer(string(fileContent))
+			genCode, userData, err := splitDisclaimer(string(fileContent))
+			if err != nil {
+				if adopt {
+					filesDiff.Orphans[path] = fileContent
+
+					return nil
+				}
+
+				return errors.Wrap(err, "error split disclaimer in file "+path+" (rerun with --adopt to move its content to a .orphan file)")
+			}
+
+			regions, err := ExtractRegions(genCode)
 			if err != nil {
-				// ToDo сделать force режим
-				return errors.Wrap(err, "error split disclaimer in file "+path)
+				if adopt {
+					filesDiff.Orphans[path] = fileContent
+
+					return nil
+				}
+
+				return errors.Wrap(err, "error find user code regions in file "+path+" (rerun with --adopt to move its content to a .orphan file)")
 			}
 
 			if len(userData) > 0 {
 				filesDiff.UserContent[newFile] = []byte(userData)
 			}
+
+			if len(regions) > 0 {
+				filesDiff.UserRegions[newFile] = regions
+			}
 		} else {
 			// Files without disclaimer support (e.g., JSON) - just mark as other files
 			_, fname := filepath.Split(path)
@@ -347,15 +449,26 @@ func GetUserCodeFromFiles(targetDir string, files []ds.Files) (ds.FilesDiff, err
 				return err
 			}
 
-			_, userData, err := splitDisclaimer(string(fileContent))
+			genCode, userData, err := splitDisclaimer(string(fileContent))
 			if err == nil {
 				// File has disclaimer — it was generated by us but is no longer in template set
-				if len(userData) > 0 {
+				if stripRegions(genCode) != genCode {
+					// User code in regions is kept with the whole file
+					if !adopt {
+						return errors.New("found user code regions in stale gen file " + targetDir + " / " + path + " (rerun with --adopt to move it to a .orphan file)")
+					}
+
+					filesDiff.Orphans[path] = fileContent
+				} else if len(userData) > 0 {
 					// Has user code below disclaimer — cannot auto-delete
-					return errors.New("found user code in stale gen file " + targetDir + " / " + path)
+					if !adopt {
+						return errors.New("found user code in stale gen file " + targetDir + " / " + path + " (rerun with --adopt to move it to a .orphan file)")
+					}
+
+					filesDiff.Orphans[path] = []byte(userData)
 				}
 
-				// No user code — safe to delete
+				// No user code or it was adopted — safe to delete
 				filesDiff.ObsoleteFiles[path] = struct{}{}
 
 				return nil
@@ -395,6 +508,155 @@ func splitDisclaimer(fileContent string) (string, string, error) {
 
 const bufferSizeStep = 1024
 
+// Names of the disclaimer templates added to a template clone
+const (
+	startDisclaimerName  = "psg:start-disclaimer"
+	finishDisclaimerName = "psg:finish-disclaimer"
+)
+
+// trimSpace is the white space trim markers remove
+const trimSpace = " \t\r\n"
+
+// envVarRx matches ${VAR_NAME} references
+var envVarRx = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// templateFuncs are available in every template. Templates are parsed once, so the map is shared.
+var templateFuncs = template.FuncMap{
+	"ToLower":     strings.ToLower,
+	"ToUpper":     strings.ToUpper,
+	"ReplaceDash": func(s string) string { return strings.ReplaceAll(s, "-", "_") },
+	"Capitalize":  cases.Title(language.Und).String,
+	"CapitalizeFirst": func(s string) string {
+		if s == "" {
+			return s
+		}
+		// Split by both "-" and "_" to produce PascalCase
+		parts := strings.FieldsFunc(s, func(r rune) bool {
+			return r == '-' || r == '_'
+		})
+		for i, p := range parts {
+			if p == "" {
+				continue
+			}
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+		return strings.Join(parts, "")
+	},
+	"errorf": func(format string, args ...any) error { return fmt.Errorf(format, args...) },
+	"add":    func(a, b int) int { return a + b },
+	"escapeJSON": func(s string) string {
+		// Escape special characters for JSON string
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		return s
+	},
+	"ImageNameToPullerService": func(image string) string {
+		// Extract image name from path like ghcr.io/org/name:tag -> name
+		lastSlash := strings.LastIndex(image, "/")
+		name := image
+		if lastSlash >= 0 {
+			name = image[lastSlash+1:]
+		}
+		// Remove tag
+		if colonIndex := strings.Index(name, ":"); colonIndex >= 0 {
+			name = name[:colonIndex]
+		}
+		return name + "-image-puller"
+	},
+	"ToGitHubSecret": func(s string) string {
+		// Replace ${VAR_NAME} with ${{ secrets.VAR_NAME }} for GitHub Actions
+		return envVarRx.ReplaceAllString(s, `${{ secrets.$1 }}`)
+	},
+	// CLI template helpers for flag handling
+	"flagDefault": func(goType, defaultVal string) string {
+		if defaultVal != "" {
+			switch goType {
+			case "string":
+				return `"` + defaultVal + `"`
+			case "bool":
+				return defaultVal
+			default:
+				return defaultVal
+			}
+		}
+		switch goType {
+		case "string":
+			return `""`
+		case "int":
+			return "0"
+		case "bool":
+			return "false"
+		case "float64":
+			return "0"
+		case "time.Duration":
+			return "0"
+		default:
+			return `""`
+		}
+	},
+	"flagZeroCheck": func(goType, varName string) string {
+		switch goType {
+		case "string":
+			return "*" + varName + ` == ""`
+		case "int":
+			return "*" + varName + " == 0"
+		case "float64":
+			return "*" + varName + " == 0"
+		case "bool":
+			return "!*" + varName
+		case "time.Duration":
+			return "*" + varName + " == 0"
+		default:
+			return "*" + varName + ` == ""`
+		}
+	},
+	"flagDeref": func(goType, varName string) string {
+		return "*" + varName
+	},
+	"printf": fmt.Sprintf,
+	// Queue serialization template helpers
+	"serializeField": func(field ds.QueueField) string {
+		switch field.Type {
+		case "int":
+			return "\t_ = binary.Write(&buf, binary.LittleEndian, int64(task." + field.GoName + "))\n"
+		case "int64":
+			return "\t_ = binary.Write(&buf, binary.LittleEndian, task." + field.GoName + ")\n"
+		case "string":
+			return "\twriteString(&buf, task." + field.GoName + ")\n"
+		case "bool":
+			return "\t_ = binary.Write(&buf, binary.LittleEndian, task." + field.GoName + ")\n"
+		case "[]byte":
+			return "\twriteBytes(&buf, task." + field.GoName + ")\n"
+		case "[]int":
+			return "\twriteIntSlice(&buf, task." + field.GoName + ")\n"
+		case "[]int64":
+			return "\twriteInt64Slice(&buf, task." + field.GoName + ")\n"
+		default:
+			return ""
+		}
+	},
+	"deserializeField": func(field ds.QueueField) string {
+		switch field.Type {
+		case "int":
+			return "\t{\n\t\tvar v int64\n\t\tif err := binary.Read(r, binary.LittleEndian, &v); err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = int(v)\n\t}\n"
+		case "int64":
+			return "\tif err := binary.Read(r, binary.LittleEndian, &task." + field.GoName + "); err != nil {\n\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t}\n"
+		case "string":
+			return "\t{\n\t\tv, err := readString(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
+		case "bool":
+			return "\tif err := binary.Read(r, binary.LittleEndian, &task." + field.GoName + "); err != nil {\n\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t}\n"
+		case "[]byte":
+			return "\t{\n\t\tv, err := readBytes(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
+		case "[]int":
+			return "\t{\n\t\tv, err := readIntSlice(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
+		case "[]int64":
+			return "\t{\n\t\tv, err := readInt64Slice(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
+		default:
+			return ""
+		}
+	},
+}
+
 func GenerateByTmpl(tmpl Template, params any, userCode []byte, destPath string) (*bytes.Buffer, error) {
 	startDisclaimer, err := makeStartDisclaimer(destPath)
 	if err != nil {
@@ -406,164 +668,126 @@ func GenerateByTmpl(tmpl Template, params any, userCode []byte, destPath string)
 		return nil, err
 	}
 
-	var funcs = template.FuncMap{
-		"ToLower":     strings.ToLower,
-		"ToUpper":     strings.ToUpper,
-		"ReplaceDash": func(s string) string { return strings.ReplaceAll(s, "-", "_") },
-		"Capitalize":  cases.Title(language.Und).String,
-		"CapitalizeFirst": func(s string) string {
-			if s == "" {
-				return s
-			}
-			// Split by both "-" and "_" to produce PascalCase
-			parts := strings.FieldsFunc(s, func(r rune) bool {
-				return r == '-' || r == '_'
-			})
-			for i, p := range parts {
-				if p == "" {
-					continue
-				}
-				parts[i] = strings.ToUpper(p[:1]) + p[1:]
-			}
-			return strings.Join(parts, "")
-		},
-		"errorf": func(format string, args ...any) error { return fmt.Errorf(format, args...) },
-		"add":    func(a, b int) int { return a + b },
-		"escapeJSON": func(s string) string {
-			// Escape special characters for JSON string
-			s = strings.ReplaceAll(s, `\`, `\\`)
-			s = strings.ReplaceAll(s, `"`, `\"`)
-			return s
-		},
-		"ImageNameToPullerService": func(image string) string {
-			// Extract image name from path like ghcr.io/org/name:tag -> name
-			lastSlash := strings.LastIndex(image, "/")
-			name := image
-			if lastSlash >= 0 {
-				name = image[lastSlash+1:]
-			}
-			// Remove tag
-			if colonIndex := strings.Index(name, ":"); colonIndex >= 0 {
-				name = name[:colonIndex]
-			}
-			return name + "-image-puller"
-		},
-		"ToGitHubSecret": func(s string) string {
-			// Replace ${VAR_NAME} with ${{ secrets.VAR_NAME }} for GitHub Actions
-			re := regexp.MustCompile(`\$\{([^}]+)\}`)
-			return re.ReplaceAllString(s, `${{ secrets.$1 }}`)
-		},
-		// CLI template helpers for flag handling
-		"flagDefault": func(goType, defaultVal string) string {
-			if defaultVal != "" {
-				switch goType {
-				case "string":
-					return `"` + defaultVal + `"`
-				case "bool":
-					return defaultVal
-				default:
-					return defaultVal
-				}
-			}
-			switch goType {
-			case "string":
-				return `""`
-			case "int":
-				return "0"
-			case "bool":
-				return "false"
-			case "float64":
-				return "0"
-			case "time.Duration":
-				return "0"
-			default:
-				return `""`
-			}
-		},
-		"flagZeroCheck": func(goType, varName string) string {
-			switch goType {
-			case "string":
-				return "*" + varName + ` == ""`
-			case "int":
-				return "*" + varName + " == 0"
-			case "float64":
-				return "*" + varName + " == 0"
-			case "bool":
-				return "!*" + varName
-			case "time.Duration":
-				return "*" + varName + " == 0"
-			default:
-				return "*" + varName + ` == ""`
-			}
-		},
-		"flagDeref": func(goType, varName string) string {
-			return "*" + varName
-		},
-		"printf": fmt.Sprintf,
-		// Queue serialization template helpers
-		"serializeField": func(field ds.QueueField) string {
-			switch field.Type {
-			case "int":
-				return "\t_ = binary.Write(&buf, binary.LittleEndian, int64(task." + field.GoName + "))\n"
-			case "int64":
-				return "\t_ = binary.Write(&buf, binary.LittleEndian, task." + field.GoName + ")\n"
-			case "string":
-				return "\twriteString(&buf, task." + field.GoName + ")\n"
-			case "bool":
-				return "\t_ = binary.Write(&buf, binary.LittleEndian, task." + field.GoName + ")\n"
-			case "[]byte":
-				return "\twriteBytes(&buf, task." + field.GoName + ")\n"
-			case "[]int":
-				return "\twriteIntSlice(&buf, task." + field.GoName + ")\n"
-			case "[]int64":
-				return "\twriteInt64Slice(&buf, task." + field.GoName + ")\n"
-			default:
-				return ""
-			}
-		},
-		"deserializeField": func(field ds.QueueField) string {
-			switch field.Type {
-			case "int":
-				return "\t{\n\t\tvar v int64\n\t\tif err := binary.Read(r, binary.LittleEndian, &v); err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = int(v)\n\t}\n"
-			case "int64":
-				return "\tif err := binary.Read(r, binary.LittleEndian, &task." + field.GoName + "); err != nil {\n\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t}\n"
-			case "string":
-				return "\t{\n\t\tv, err := readString(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
-			case "bool":
-				return "\tif err := binary.Read(r, binary.LittleEndian, &task." + field.GoName + "); err != nil {\n\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t}\n"
-			case "[]byte":
-				return "\t{\n\t\tv, err := readBytes(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
-			case "[]int":
-				return "\t{\n\t\tv, err := readIntSlice(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
-			case "[]int64":
-				return "\t{\n\t\tv, err := readInt64Slice(r)\n\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"read " + field.Name + ": %w\", err)\n\t\t}\n\t\ttask." + field.GoName + " = v\n\t}\n"
-			default:
-				return ""
-			}
-		},
+	body := tmpl.body
+	if body == nil {
+		if body, err = parseTemplate(tmpl.Name, tmpl.Tmpl); err != nil {
+			return nil, err
+		}
+	}
+
+	// Templates used to be parsed together with the disclaimers, trim markers at the edges
+	// of a template still trim the disclaimer text next to them
+	separator := "\n"
+
+	if trimsBefore(tmpl.Tmpl) {
+		startDisclaimer = strings.TrimRight(startDisclaimer, trimSpace)
+	}
+
+	if trimsAfter(tmpl.Tmpl) {
+		separator = ""
+		finishDisclaimer = strings.TrimLeft(finishDisclaimer, trimSpace)
+	}
+
+	// The clone gets the disclaimers of this file, the cached template is shared by all files
+	page, err := body.Clone()
+	if err != nil {
+		return nil, errors.Wrap(err, "error clone template `"+tmpl.Name+"`")
+	}
+
+	if _, err = page.New(startDisclaimerName).Parse(startDisclaimer); err != nil {
+		return nil, errors.Wrap(err, "error parse disclaimer")
+	}
+
+	if _, err = page.New(finishDisclaimerName).Parse(separator + finishDisclaimer); err != nil {
+		return nil, errors.Wrap(err, "error parse disclaimer")
 	}
 
 	buf := &bytes.Buffer{}
 
 	buf.Grow(len(userCode) + bufferSizeStep*((len(startDisclaimer)+len(tmpl.Tmpl)+len(finishDisclaimer))/bufferSizeStep) + 1)
 
-	templatePackage, err := template.New(destPath).Funcs(funcs).Parse(startDisclaimer + tmpl.Tmpl + "\n" + finishDisclaimer)
-	if err != nil {
-		tmplLines, errGetLine := getTmplErrorLine(strings.SplitAfter(startDisclaimer+tmpl.Tmpl+"\n"+finishDisclaimer, "\n"), err.Error())
+	if err = page.ExecuteTemplate(buf, startDisclaimerName, params); err != nil {
+		return nil, errors.Wrap(err, "error execute disclaimer")
+	}
+
+	if err = page.Execute(buf, params); err != nil {
+		tmplLines, errGetLine := getTmplErrorLine(strings.SplitAfter(tmpl.Tmpl, "\n"), err.Error())
 		if errGetLine != nil {
 			tmplLines = errGetLine.Error()
 		}
 
-		return nil, errors.New("error parse template `" + tmpl.Name + "` at line " + tmplLines + ": " + err.Error())
+		return nil, errors.New("error execute template `" + tmpl.Name + "` at line " + tmplLines + ": " + err.Error())
+	}
+
+	if err = page.ExecuteTemplate(buf, finishDisclaimerName, params); err != nil {
+		return nil, errors.Wrap(err, "error execute disclaimer")
+	}
+
+	if len(userCode) > 0 {
+		buf.Write(userCode)
 	}
 
-	if err = templatePackage.Execute(buf, params); err != nil {
-		tmplLines, errGetLine := getTmplErrorLine(strings.SplitAfter(startDisclaimer+tmpl.Tmpl+"\n"+finishDisclaimer, "\n"), err.Error())
+	return buf, nil
+}
+
+// parseTemplate parses a template body with the template functions
+func parseTemplate(name, text string) (*template.Template, error) {
+	body, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		tmplLines, errGetLine := getTmplErrorLine(strings.SplitAfter(text, "\n"), err.Error())
 		if errGetLine != nil {
 			tmplLines = errGetLine.Error()
 		}
 
-		return nil, errors.New("error execute template `" + tmpl.Name + "` at line " + tmplLines + ": " + err.Error())
+		return nil, errors.New("error parse template `" + name + "` at line " + tmplLines + ": " + err.Error())
+	}
+
+	return body, nil
+}
+
+// trimsBefore reports whether a template starts with a left trim marker: {{- ...
+func trimsBefore(text string) bool {
+	text = strings.TrimLeft(text, trimSpace)
+
+	return len(text) > 3 && strings.HasPrefix(text, "{{-") && strings.ContainsRune(trimSpace, rune(text[3]))
+}
+
+// trimsAfter reports whether a template ends with a right trim marker: ... -}}
+func trimsAfter(text string) bool {
+	text = strings.TrimRight(text, trimSpace)
+
+	return len(text) > 3 && strings.HasSuffix(text, "-}}") && strings.ContainsRune(trimSpace, rune(text[len(text)-4]))
+}
+
+// GenerateByContent wraps content generated outside of templates, e.g. by a plugin, with the disclaimers
+// commented in style and appends user code. Content is written as is, only the disclaimers are executed.
+func GenerateByContent(content []byte, style string, params any, userCode []byte, destPath string) (*bytes.Buffer, error) {
+	startDisclaimer, finishDisclaimer, err := makeStyledDisclaimers(destPath, style)
+	if err != nil {
+		return nil, err
+	}
+
+	disclaimers, err := template.New(destPath).Parse(`{{ define "start" }}` + startDisclaimer + `{{ end }}{{ define "finish" }}` + finishDisclaimer + `{{ end }}`)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parse disclaimer")
+	}
+
+	buf := &bytes.Buffer{}
+
+	buf.Grow(len(userCode) + len(content) + len(startDisclaimer) + len(finishDisclaimer) + 1)
+
+	if err = disclaimers.ExecuteTemplate(buf, "start", params); err != nil {
+		return nil, errors.Wrap(err, "error execute disclaimer")
+	}
+
+	buf.Write(content)
+
+	if len(finishDisclaimer) > 0 && len(content) > 0 && content[len(content)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	if err = disclaimers.ExecuteTemplate(buf, "finish", params); err != nil {
+		return nil, errors.Wrap(err, "error execute disclaimer")
 	}
 
 	if len(userCode) > 0 {
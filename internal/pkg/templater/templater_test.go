@@ -1,10 +1,12 @@
 package templater
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/Educentr/go-project-starter/internal/pkg/ds"
 )
@@ -389,6 +391,54 @@ func TestSplitDisclaimer(t *testing.T) {
 	}
 }
 
+func TestGeneratedChecksum(t *testing.T) {
+	generated := "package main\n\n// " + disclaimer + "\n"
+
+	tests := []struct {
+		name     string
+		fName    string
+		a, b     string
+		wantSame bool
+	}{
+		{
+			name:     "user code below disclaimer is not part of checksum",
+			fName:    "psg_main_gen.go",
+			a:        generated,
+			b:        generated + "\nfunc myFunc() {}\n",
+			wantSame: true,
+		},
+		{
+			name:     "edit above disclaimer changes checksum",
+			fName:    "psg_main_gen.go",
+			a:        generated,
+			b:        "package main\n\nvar x = 1\n\n// " + disclaimer + "\n",
+			wantSame: false,
+		},
+		{
+			name:     "file without disclaimer support is hashed entirely",
+			fName:    "dashboard.json",
+			a:        `{"a": 1}`,
+			b:        `{"a": 2}`,
+			wantSame: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sumA := GeneratedChecksum(tt.fName, []byte(tt.a))
+			sumB := GeneratedChecksum(tt.fName, []byte(tt.b))
+
+			if !strings.HasPrefix(sumA, "sha256:") {
+				t.Errorf("GeneratedChecksum() = %q, want sha256: prefix", sumA)
+			}
+
+			if (sumA == sumB) != tt.wantSame {
+				t.Errorf("GeneratedChecksum() equal = %v, want %v", sumA == sumB, tt.wantSame)
+			}
+		})
+	}
+}
+
 func TestGetTmplErrorLine(t *testing.T) {
 	lines := []string{
 		"line1\n",
@@ -603,9 +653,106 @@ func TestGetTemplate(t *testing.T) {
 		if tmpl1.Tmpl != tmpl2.Tmpl {
 			t.Error("GetTemplate() cached template differs from original")
 		}
+
+		if tmpl1.body == nil || tmpl1.body != tmpl2.body {
+			t.Error("GetTemplate() template is not parsed once and cached")
+		}
+	})
+
+	t.Run("parse error is reported with the template name", func(t *testing.T) {
+		dir := t.TempDir()
+		writeOverlayFile(t, dir, "main/broken.tmpl", "line\n{{ .Broken \n")
+
+		_, err := newTestTemplates(t, dir).GetTemplate("embedded/templates/main/broken.tmpl")
+		if err == nil || !strings.Contains(err.Error(), "error parse template `embedded/templates/main/broken.tmpl`") {
+			t.Errorf("GetTemplate() error = %v, want parse error", err)
+		}
 	})
 }
 
+// TestGenerateByTmpl_TrimMarkers checks that a template parsed apart from the disclaimers renders
+// exactly as it did when both were parsed as one text
+func TestGenerateByTmpl_TrimMarkers(t *testing.T) {
+	params := GeneratorParams{ProjectName: "app", AppInfo: "test"}
+
+	tests := []struct {
+		name     string
+		tmpl     string
+		destPath string
+	}{
+		{name: "plain", tmpl: "package {{ .ProjectName }}\n", destPath: "/p/main.go"},
+		{name: "left trim", tmpl: "{{- $name := .ProjectName }}\nservices:\n  {{ $name }}:\n", destPath: "/p/compose.yaml"},
+		{name: "left trim after blank lines", tmpl: "\n\n{{- /* comment */}}\nservices: {}\n", destPath: "/p/compose.yaml"},
+		{name: "right trim", tmpl: "package main\n{{ range $i := .CI }}\nvar _ = {{ $i }}\n{{ end -}}\n", destPath: "/p/main.go"},
+		{name: "both trims", tmpl: "{{- if true }}\npackage main\n{{ end -}}\n\n", destPath: "/p/main.go"},
+		{name: "right trim into plain text comment", tmpl: "{{ .ProjectName }}\n{{- \"\" -}}\n", destPath: "/p/notes.txt"},
+		{name: "not a trim marker", tmpl: "{{-1}}\n", destPath: "/p/main.go"},
+		{name: "ignored file", tmpl: "{{- \"{}\" -}}\n", destPath: "/p/config.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, err := makeStartDisclaimer(tt.destPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			finish, err := makeFinishDisclaimer(tt.destPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want bytes.Buffer
+
+			whole := template.Must(template.New(tt.destPath).Funcs(templateFuncs).Parse(start + tt.tmpl + "\n" + finish))
+			if err := whole.Execute(&want, params); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := GenerateByTmpl(Template{Name: tt.name, Tmpl: tt.tmpl}, params, nil, tt.destPath)
+			if err != nil {
+				t.Fatalf("GenerateByTmpl() error = %v", err)
+			}
+
+			if got.String() != want.String() {
+				t.Errorf("GenerateByTmpl() = %q, want %q", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestGetRepositoryTemplates(t *testing.T) {
+	repo := ds.Repository{Name: "users", TypeDB: ds.RepositoryTypePostgres, DriverDB: ds.RepositoryDriverPgx}
+
+	_, files, err := embeddedTemplates.GetRepositoryTemplates(repo, GeneratorParams{ProjectPath: "github.com/org/svc"})
+	if err != nil {
+		t.Fatalf("GetRepositoryTemplates() unexpected error: %v", err)
+	}
+
+	got := make(map[string]bool, len(files))
+	for _, f := range files {
+		got[filepath.ToSlash(f.DestName)] = true
+
+		params, ok := f.ParamsTmpl.(GeneratorRepositoryParams)
+		if !ok || params.Repository.Name != "users" {
+			t.Errorf("GetRepositoryTemplates() %s params = %#v, want GeneratorRepositoryParams for users", f.DestName, f.ParamsTmpl)
+		}
+	}
+
+	for _, want := range []string{
+		"internal/pkg/repository/users/repository.go",
+		"internal/pkg/repository/users/postgres.go",
+		"db/users/sqlc.yaml",
+		"db/users/schema.sql",
+		"db/users/queries.sql",
+		"tests/mocks/repository/users/doc.go",
+	} {
+		if !got[want] {
+			t.Errorf("GetRepositoryTemplates() missing %s, got %v", want, got)
+		}
+	}
+}
+
 func TestIsFileIgnore(t *testing.T) {
 	tests := []struct {
 		name string
@@ -794,6 +941,108 @@ func TestGenerateByTmpl(t *testing.T) {
 	})
 }
 
+func TestGenerateByContent(t *testing.T) {
+	params := GeneratorParams{AppInfo: "test-info"}
+
+	tests := []struct {
+		name       string
+		content    string
+		style      string
+		userCode   string
+		destPath   string
+		want       []string
+		notWant    []string
+		wantErr    bool
+		wantSuffix string
+	}{
+		{
+			name:       "auto style for Go file keeps template syntax and user code",
+			content:    "package rpc\n\nvar tmpl = \"{{ .NotExecuted }}\"",
+			style:      CommentAuto,
+			userCode:   "\nfunc custom() {}\n",
+			destPath:   "/path/to/rpc.go",
+			want:       []string{"// Code generated by projectStarter generator", "// Generate info: test-info", `var tmpl = "{{ .NotExecuted }}"` + "\n//", disclaimer},
+			wantSuffix: disclaimer + " \n\nfunc custom() {}\n",
+		},
+		{
+			name:     "empty style is auto",
+			content:  "name: rpc\n",
+			destPath: "/path/to/rpc.yaml",
+			want:     []string{"# Code generated by projectStarter generator", "name: rpc\n# "},
+		},
+		{
+			name:     "explicit style for unknown extension",
+			content:  "service Rpc {}\n",
+			style:    CommentSlash,
+			destPath: "/path/to/api.rpc",
+			want:     []string{"// Code generated by projectStarter generator", "// " + disclaimer},
+		},
+		{
+			name:     "html style",
+			content:  "<rpc/>\n",
+			style:    CommentHTML,
+			destPath: "/path/to/rpc.xml",
+			want:     []string{"<!-- " + disclaimer + " -->"},
+		},
+		{
+			name:     "no disclaimer for JSON",
+			content:  `{"rpc": true}`,
+			style:    CommentHash,
+			destPath: "/path/to/rpc.json",
+			notWant:  []string{disclaimer},
+		},
+		{
+			name:     "unknown extension in auto style",
+			content:  "service Rpc {}\n",
+			destPath: "/path/to/api.rpc",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown style",
+			content:  "package rpc\n",
+			style:    "semicolon",
+			destPath: "/path/to/rpc.go",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := GenerateByContent([]byte(tt.content), tt.style, params, []byte(tt.userCode), tt.destPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateByContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			got := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("GenerateByContent() = %q, want to contain %q", got, want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("GenerateByContent() = %q, want not to contain %q", got, notWant)
+				}
+			}
+
+			if !strings.HasSuffix(got, tt.wantSuffix) {
+				t.Errorf("GenerateByContent() = %q, want suffix %q", got, tt.wantSuffix)
+			}
+
+			// The generated part is recognised as such when the file is read back
+			if _, userCode, err := splitDisclaimer(got); err == nil && userCode != tt.userCode {
+				t.Errorf("splitDisclaimer() user code = %q, want %q", userCode, tt.userCode)
+			}
+		})
+	}
+}
+
 func TestTemplateFuncs(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -894,7 +1143,7 @@ func TestGetUserCodeFromFiles(t *testing.T) {
 		}
 
 		// No files in template set — so psg_old_gen.go is not expected
-		filesDiff, err := GetUserCodeFromFiles(tmpDir, nil)
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, nil, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -915,7 +1164,7 @@ func TestGetUserCodeFromFiles(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, err := GetUserCodeFromFiles(tmpDir, nil)
+		_, err := GetUserCodeFromFiles(tmpDir, nil, false)
 		if err == nil {
 			t.Fatal("expected error for stale file with user code, got nil")
 		}
@@ -925,6 +1174,29 @@ func TestGetUserCodeFromFiles(t *testing.T) {
 		}
 	})
 
+	t.Run("stage directory of a killed run is skipped", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		staleFile := filepath.Join(tmpDir, StageDirPrefix+"999", "cmd", "api", "psg_main_gen.go")
+		if err := os.MkdirAll(filepath.Dir(staleFile), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		content := "package main\n\n" + disclaimerLine + "\n\nfunc myCustomCode() {}\n"
+		if err := os.WriteFile(staleFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, nil, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := filesDiff.ObsoleteFiles[staleFile]; ok {
+			t.Errorf("staged copy %s should NOT be in ObsoleteFiles", staleFile)
+		}
+	})
+
 	t.Run("obsolete file without disclaimer goes to OtherFiles", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -935,7 +1207,7 @@ func TestGetUserCodeFromFiles(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		filesDiff, err := GetUserCodeFromFiles(tmpDir, nil)
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, nil, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -963,7 +1235,7 @@ func TestGetUserCodeFromFiles(t *testing.T) {
 			{DestName: destName, OldDestName: destName},
 		}
 
-		filesDiff, err := GetUserCodeFromFiles(tmpDir, files)
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, files, false)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -972,4 +1244,134 @@ func TestGetUserCodeFromFiles(t *testing.T) {
 			t.Errorf("file in template set should NOT be in ObsoleteFiles")
 		}
 	})
+
+	t.Run("adopt moves user code of stale file to Orphans", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		staleFile := filepath.Join(tmpDir, "psg_old_gen.go")
+		content := "package main\n\n" + disclaimerLine + "\n\nfunc myCustomCode() {}\n"
+		if err := os.WriteFile(staleFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, nil, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := string(filesDiff.Orphans[staleFile]); got != "\nfunc myCustomCode() {}\n" {
+			t.Errorf("Orphans[%s] = %q, want user code", staleFile, got)
+		}
+
+		if _, ok := filesDiff.ObsoleteFiles[staleFile]; !ok {
+			t.Errorf("adopted stale file should be in ObsoleteFiles")
+		}
+	})
+
+	t.Run("generated file without disclaimer", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		destName := filepath.Join(tmpDir, "psg_handler_gen.go")
+		content := "package main\n\nfunc edited() {}\n"
+		if err := os.WriteFile(destName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		files := []ds.Files{
+			{DestName: destName, OldDestName: destName},
+		}
+
+		_, err := GetUserCodeFromFiles(tmpDir, files, false)
+		if err == nil || !strings.Contains(err.Error(), "error split disclaimer") {
+			t.Fatalf("expected split disclaimer error, got: %v", err)
+		}
+
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, files, true)
+		if err != nil {
+			t.Fatalf("adopt: unexpected error: %v", err)
+		}
+
+		if got := string(filesDiff.Orphans[destName]); got != content {
+			t.Errorf("Orphans[%s] = %q, want whole file content", destName, got)
+		}
+
+		if _, ok := filesDiff.UserContent[destName]; ok {
+			t.Errorf("adopted file should have no UserContent")
+		}
+	})
+
+	t.Run("regions of generated file go to UserRegions", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		destName := filepath.Join(tmpDir, "psg_main_gen.go")
+		content := "package main\n\nimport (\n\t// psg:begin:imports\n\t\"strings\"\n\t// psg:end:imports\n)\n\n" + disclaimerLine + "\n"
+		if err := os.WriteFile(destName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		files := []ds.Files{
+			{DestName: destName, OldDestName: destName},
+		}
+
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, files, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := filesDiff.UserRegions[destName]["imports"]; got != "\t\"strings\"\n" {
+			t.Errorf("UserRegions[%s][imports] = %q", destName, got)
+		}
+	})
+
+	t.Run("stale file with region code", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		staleFile := filepath.Join(tmpDir, "psg_old_gen.go")
+		content := "package main\n\n// psg:begin:funcs\nfunc myCustomCode() {}\n// psg:end:funcs\n\n" + disclaimerLine + "\n"
+		if err := os.WriteFile(staleFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := GetUserCodeFromFiles(tmpDir, nil, false)
+		if err == nil || !strings.Contains(err.Error(), "found user code regions in stale gen file") {
+			t.Fatalf("expected stale regions error, got: %v", err)
+		}
+
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, nil, true)
+		if err != nil {
+			t.Fatalf("adopt: unexpected error: %v", err)
+		}
+
+		if got := string(filesDiff.Orphans[staleFile]); got != content {
+			t.Errorf("Orphans[%s] = %q, want whole file content", staleFile, got)
+		}
+	})
+
+	t.Run("generated file with broken regions", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		destName := filepath.Join(tmpDir, "psg_main_gen.go")
+		content := "package main\n\n// psg:begin:imports\n\n" + disclaimerLine + "\n"
+		if err := os.WriteFile(destName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		files := []ds.Files{
+			{DestName: destName, OldDestName: destName},
+		}
+
+		_, err := GetUserCodeFromFiles(tmpDir, files, false)
+		if err == nil || !strings.Contains(err.Error(), "region imports is not closed") {
+			t.Fatalf("expected region error, got: %v", err)
+		}
+
+		filesDiff, err := GetUserCodeFromFiles(tmpDir, files, true)
+		if err != nil {
+			t.Fatalf("adopt: unexpected error: %v", err)
+		}
+
+		if got := string(filesDiff.Orphans[destName]); got != content {
+			t.Errorf("Orphans[%s] = %q, want whole file content", destName, got)
+		}
+	})
 }
@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/Educentr/go-project-starter/internal/pkg/ds"
 	"github.com/go-git/go-git/v5"
@@ -22,7 +25,9 @@ const (
 	msgStopGitHasUncommittedChanges = "git (%v) has uncommitted changes. Please commit them first"
 )
 
-func CheckGitStatus(paths ...string) (err error) {
+// CheckGitStatus returns an error if any of paths has uncommitted changes, paths outside of
+// a git repository are reported to log and skipped
+func CheckGitStatus(log *slog.Logger, paths ...string) (err error) {
 	var (
 		rep *git.Repository
 		wrt *git.Worktree
@@ -32,7 +37,7 @@ func CheckGitStatus(paths ...string) (err error) {
 	for _, path := range paths {
 		if rep, err = git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true}); err != nil {
 			if errors.Is(err, git.ErrRepositoryNotExists) {
-				log.Printf(msgStopFailedToOpenGitRepo, path, err)
+				log.Info("not a git repository, uncommitted changes are not checked", "path", path)
 				continue
 			}
 
@@ -40,13 +45,11 @@ func CheckGitStatus(paths ...string) (err error) {
 		}
 
 		if wrt, err = rep.Worktree(); err != nil {
-			log.Printf(msgStopFailedToGetGitWorktree, path, err)
-			return
+			return fmt.Errorf(msgStopFailedToGetGitWorktree, path, err) //nolint:goerr113,stylecheck
 		}
 
 		if sta, err = wrt.Status(); err != nil {
-			log.Printf(msgStopFailedToGetGitStatus, path, err)
-			return
+			return fmt.Errorf(msgStopFailedToGetGitStatus, path, err) //nolint:goerr113,stylecheck
 		}
 
 		if !sta.IsClean() {
@@ -57,6 +60,68 @@ func CheckGitStatus(paths ...string) (err error) {
 	return nil
 }
 
+// GitUncommittedChanges returns paths under dir, relative to it, that differ from HEAD in the
+// enclosing git repository. Returns nil if dir is not inside a git repository.
+func GitUncommittedChanges(dir string) ([]string, error) {
+	rep, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf(msgStopFailedToOpenGitRepo, dir, err) //nolint:goerr113,stylecheck
+	}
+
+	wrt, err := rep.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf(msgStopFailedToGetGitWorktree, dir, err) //nolint:goerr113,stylecheck
+	}
+
+	sta, err := wrt.Status()
+	if err != nil {
+		return nil, fmt.Errorf(msgStopFailedToGetGitStatus, dir, err) //nolint:goerr113,stylecheck
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve symlinks on both sides, e.g. /tmp on macOS
+	root := wrt.Filesystem.Root()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	if resolved, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = resolved
+	}
+
+	prefix, err := filepath.Rel(root, absDir)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]string, 0, len(sta))
+
+	for path, status := range sta {
+		if status.Worktree == git.Unmodified && status.Staging == git.Unmodified {
+			continue
+		}
+
+		rel, err := filepath.Rel(prefix, filepath.FromSlash(path))
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		changes = append(changes, filepath.ToSlash(rel))
+	}
+
+	sort.Strings(changes)
+
+	return changes, nil
+}
+
 func MakeDirs(dirs []ds.Files) error {
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir.DestName, 0700); err != nil && err != os.ErrExist {
@@ -140,3 +205,30 @@ func FileExists(filename string) retFileExist {
 
 	return ErrInvalid
 }
+
+// FileExistsFS is FileExists for a file of fsys, a nil fsys is the OS file system
+func FileExistsFS(fsys fs.FS, filename string) retFileExist {
+	if fsys == nil {
+		return FileExists(filename)
+	}
+
+	info, err := fs.Stat(fsys, filepath.ToSlash(filename))
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().IsRegular() {
+		return ErrExist
+	}
+
+	return ErrInvalid
+}
+
+// ReadFile reads a file of fsys, a nil fsys is the OS file system
+func ReadFile(fsys fs.FS, filename string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(filename)
+	}
+
+	return fs.ReadFile(fsys, filepath.ToSlash(filename))
+}
@@ -0,0 +1,309 @@
+// Package topology builds the graph of the applications of a project: the transports they serve,
+// the services they call, the kafka topics they produce and consume, their drivers and workers.
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+)
+
+// Kinds of nodes
+const (
+	KindApp       = "application"
+	KindTransport = "transport"
+	KindTopic     = "topic"
+	KindDriver    = "driver"
+	KindWorker    = "worker"
+	KindExternal  = "external" // service called by a client transport and not served by the project
+)
+
+// Kinds of edges
+const (
+	EdgeServes   = "serves"   // application -> transport
+	EdgeCalls    = "calls"    // application -> transport or external service, through a client transport
+	EdgeProduces = "produces" // application -> topic
+	EdgeConsumes = "consumes" // topic -> application
+	EdgeUses     = "uses"     // application -> driver
+	EdgeRuns     = "runs"     // application -> worker
+)
+
+// Formats of Write
+const (
+	FormatMermaid = "mermaid"
+	FormatDot     = "dot"
+	FormatJSON    = "json"
+)
+
+// Formats are the formats accepted by Write
+var Formats = []string{FormatMermaid, FormatDot, FormatJSON}
+
+// Node is an application or an entity it is connected to
+type Node struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Type  string `json:"type,omitempty"` // transport type: rest, grpc, ws
+	Port  string `json:"port,omitempty"`
+	Label string `json:"label"`
+}
+
+// Edge connects two nodes, in the direction of requests or messages
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"` // client transport of calls, consumer group of consumes
+}
+
+// Graph of the applications, nodes and edges are in a stable order: applications in the config order,
+// the rest by name
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+
+	ids map[string]bool
+}
+
+var idRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Build returns the graph of apps; transports are all transports of the project, used to find
+// the transport serving the spec of a client
+func Build(apps ds.Apps, transports ds.Transports) *Graph {
+	g := &Graph{Nodes: []Node{}, Edges: []Edge{}, ids: map[string]bool{}}
+
+	for _, app := range apps {
+		label := app.Name
+		if app.IsCLI() {
+			label += " (cli)"
+		}
+
+		appID := g.node(Node{Kind: KindApp, Name: app.Name, Label: label})
+
+		for _, name := range sortedKeys(app.Transports) {
+			transport := app.Transports[name]
+
+			if isClient(transport) {
+				g.edge(Edge{From: appID, To: g.provider(transport, transports), Kind: EdgeCalls, Label: transport.Name})
+
+				continue
+			}
+
+			g.edge(Edge{From: appID, To: g.transport(transport), Kind: EdgeServes})
+		}
+
+		for _, name := range sortedKeys(app.Kafka) {
+			kafka := app.Kafka[name]
+
+			for _, event := range kafka.Events {
+				topicID := g.node(Node{Kind: KindTopic, Name: event.Name, Label: event.Name})
+
+				if kafka.IsConsumer() {
+					g.edge(Edge{From: topicID, To: appID, Kind: EdgeConsumes, Label: kafka.Group})
+				} else {
+					g.edge(Edge{From: appID, To: topicID, Kind: EdgeProduces})
+				}
+			}
+		}
+
+		for _, name := range sortedKeys(app.Drivers) {
+			g.edge(Edge{From: appID, To: g.node(Node{Kind: KindDriver, Name: name, Label: name}), Kind: EdgeUses})
+		}
+
+		for _, name := range sortedKeys(app.Workers) {
+			g.edge(Edge{From: appID, To: g.node(Node{Kind: KindWorker, Name: name, Label: name}), Kind: EdgeRuns})
+		}
+	}
+
+	g.sort()
+
+	return g
+}
+
+// isClient reports whether the transport calls another service instead of serving one
+func isClient(transport ds.Transport) bool {
+	return transport.GeneratorType == "ogen_client" || transport.GeneratorType == "buf_client"
+}
+
+// provider returns the node of the transport serving the spec of a client, an external service if
+// the project does not serve it
+func (g *Graph) provider(client ds.Transport, transports ds.Transports) string {
+	for _, name := range sortedKeys(transports) {
+		transport := transports[name]
+
+		if transport.Type == client.Type && !isClient(transport) && slices.Equal(transport.SpecPath, client.SpecPath) {
+			return g.transport(transport)
+		}
+	}
+
+	return g.node(Node{Kind: KindExternal, Name: client.Name, Type: string(client.Type), Label: client.Name})
+}
+
+func (g *Graph) transport(transport ds.Transport) string {
+	label := string(transport.Type) + " " + transport.Name
+	if transport.Port != "" && transport.Port != "0" {
+		label += " :" + transport.Port
+	}
+
+	return g.node(Node{
+		Kind:  KindTransport,
+		Name:  transport.Name,
+		Type:  string(transport.Type),
+		Port:  transport.Port,
+		Label: label,
+	})
+}
+
+// node adds a node if there is none with its kind and name, it returns the node ID
+func (g *Graph) node(node Node) string {
+	node.ID = node.Kind + "_" + idRe.ReplaceAllString(node.Name, "_")
+
+	if !g.ids[node.ID] {
+		g.ids[node.ID] = true
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	return node.ID
+}
+
+func (g *Graph) edge(edge Edge) {
+	for _, existing := range g.Edges {
+		if existing == edge {
+			return
+		}
+	}
+
+	g.Edges = append(g.Edges, edge)
+}
+
+// kindOrder is the order of nodes of a kind in the graph
+var kindOrder = map[string]int{
+	KindApp:       0,
+	KindTransport: 1,
+	KindExternal:  2,
+	KindTopic:     3,
+	KindDriver:    4,
+	KindWorker:    5,
+}
+
+// sort orders nodes by kind, applications keep the config order and other nodes are sorted by name
+func (g *Graph) sort() {
+	sort.SliceStable(g.Nodes, func(i, j int) bool {
+		a, b := g.Nodes[i], g.Nodes[j]
+		if a.Kind != b.Kind {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+
+		return a.Kind != KindApp && a.Name < b.Name
+	})
+}
+
+// Write writes the graph in format: mermaid, dot or json
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatMermaid:
+		_, err := io.WriteString(w, g.Mermaid())
+
+		return err
+	case FormatDot:
+		_, err := io.WriteString(w, g.Dot())
+
+		return err
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(g)
+	}
+
+	return fmt.Errorf("unknown format %q, expected one of: %s", format, strings.Join(Formats, ", "))
+}
+
+// mermaidShapes are the node shapes of a mermaid flowchart by kind: opening and closing brackets
+var mermaidShapes = map[string][2]string{
+	KindApp:       {"[", "]"},
+	KindTransport: {"([", "])"},
+	KindExternal:  {"((", "))"},
+	KindTopic:     {"[/", "/]"},
+	KindDriver:    {"{{", "}}"},
+	KindWorker:    {"[[", "]]"},
+}
+
+// Mermaid returns the graph as a mermaid flowchart
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+
+	b.WriteString("flowchart LR\n")
+
+	for _, node := range g.Nodes {
+		shape := mermaidShapes[node.Kind]
+		fmt.Fprintf(&b, "    %s%s\"%s\"%s\n", node.ID, shape[0], mermaidText(node.Label), shape[1])
+	}
+
+	for _, edge := range g.Edges {
+		label := edge.Kind
+		if edge.Label != "" {
+			label += ": " + edge.Label
+		}
+
+		fmt.Fprintf(&b, "    %s -->|\"%s\"| %s\n", edge.From, mermaidText(label), edge.To)
+	}
+
+	return b.String()
+}
+
+// mermaidText escapes the quotes of a label, mermaid has no backslash escapes
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
+
+// dotShapes are the node shapes of a graphviz graph by kind
+var dotShapes = map[string]string{
+	KindApp:       "box",
+	KindTransport: "ellipse",
+	KindExternal:  "doublecircle",
+	KindTopic:     "parallelogram",
+	KindDriver:    "hexagon",
+	KindWorker:    "component",
+}
+
+// Dot returns the graph in the graphviz dot language
+func (g *Graph) Dot() string {
+	var b strings.Builder
+
+	b.WriteString("digraph topology {\n    rankdir=LR;\n")
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "    %q [label=%q, shape=%s];\n", node.ID, node.Label, dotShapes[node.Kind])
+	}
+
+	for _, edge := range g.Edges {
+		label := edge.Kind
+		if edge.Label != "" {
+			label += ": " + edge.Label
+		}
+
+		fmt.Fprintf(&b, "    %q -> %q [label=%q];\n", edge.From, edge.To, label)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,125 @@
+package topology
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/ds"
+)
+
+func testGraph() *Graph {
+	transports := ds.Transports{
+		"billing":        {Name: "billing", Type: ds.RestTransportType, GeneratorType: "ogen", Port: "8081", SpecPath: []string{"/cfg/billing.yaml"}},
+		"billing_client": {Name: "billing_client", Type: ds.RestTransportType, GeneratorType: "ogen_client", Port: "0", SpecPath: []string{"/cfg/billing.yaml"}},
+		"geo":            {Name: "geo", Type: ds.GrpcTransportType, GeneratorType: "buf_client", Port: "0", SpecPath: []string{"/cfg/geo.proto"}},
+	}
+
+	apps := ds.Apps{
+		{
+			Name:       "billing",
+			Transports: ds.Transports{"billing": transports["billing"]},
+			Kafka: ds.KafkaConfigs{
+				"out": {Name: "out", Type: ds.KafkaTypeProducer, Events: []ds.KafkaEvent{{Name: "paid"}}},
+			},
+			Drivers: ds.Drivers{"s3": {Name: "s3"}},
+		},
+		{
+			Name:       "api",
+			Transports: ds.Transports{"billing_client": transports["billing_client"], "geo": transports["geo"]},
+			Kafka: ds.KafkaConfigs{
+				"in": {Name: "in", Type: ds.KafkaTypeConsumer, Group: "api", Events: []ds.KafkaEvent{{Name: "paid"}}},
+			},
+			Workers: ds.Workers{"mailer": {Name: "mailer"}},
+		},
+	}
+
+	return Build(apps, transports)
+}
+
+func TestBuild(t *testing.T) {
+	g := testGraph()
+
+	wantNodes := []string{
+		"application_billing", "application_api", "transport_billing", "external_geo", "topic_paid", "driver_s3", "worker_mailer",
+	}
+
+	nodes := make([]string, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		nodes = append(nodes, node.ID)
+	}
+
+	if !reflect.DeepEqual(nodes, wantNodes) {
+		t.Errorf("Build() nodes = %v, want %v", nodes, wantNodes)
+	}
+
+	wantEdges := []Edge{
+		{From: "application_billing", To: "transport_billing", Kind: EdgeServes},
+		{From: "application_billing", To: "topic_paid", Kind: EdgeProduces},
+		{From: "application_billing", To: "driver_s3", Kind: EdgeUses},
+		{From: "application_api", To: "transport_billing", Kind: EdgeCalls, Label: "billing_client"},
+		{From: "application_api", To: "external_geo", Kind: EdgeCalls, Label: "geo"},
+		{From: "topic_paid", To: "application_api", Kind: EdgeConsumes, Label: "api"},
+		{From: "application_api", To: "worker_mailer", Kind: EdgeRuns},
+	}
+
+	if !reflect.DeepEqual(g.Edges, wantEdges) {
+		t.Errorf("Build() edges = %+v, want %+v", g.Edges, wantEdges)
+	}
+}
+
+func TestGraph_Mermaid(t *testing.T) {
+	want := `flowchart LR
+    application_billing["billing"]
+    application_api["api"]
+    transport_billing(["rest billing :8081"])
+    external_geo(("geo"))
+    topic_paid[/"paid"/]
+    driver_s3{{"s3"}}
+    worker_mailer[["mailer"]]
+    application_billing -->|"serves"| transport_billing
+    application_billing -->|"produces"| topic_paid
+    application_billing -->|"uses"| driver_s3
+    application_api -->|"calls: billing_client"| transport_billing
+    application_api -->|"calls: geo"| external_geo
+    topic_paid -->|"consumes: api"| application_api
+    application_api -->|"runs"| worker_mailer
+`
+
+	if got := testGraph().Mermaid(); got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGraph_Write(t *testing.T) {
+	g := testGraph()
+
+	var buf bytes.Buffer
+	if err := g.Write(&buf, FormatDot); err != nil {
+		t.Fatalf("Write(dot) error = %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"topic_paid" -> "application_api" [label="consumes: api"];`)) {
+		t.Errorf("Write(dot) =\n%s", buf.String())
+	}
+
+	buf.Reset()
+
+	if err := g.Write(&buf, FormatJSON); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+
+	var decoded Graph
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Write(json) is not valid JSON: %v", err)
+	}
+
+	if len(decoded.Nodes) != len(g.Nodes) || len(decoded.Edges) != len(g.Edges) {
+		t.Errorf("Write(json) = %s", buf.String())
+	}
+
+	if err := g.Write(&buf, "svg"); err == nil {
+		t.Error("Write(svg) error = nil, want error")
+	}
+}
@@ -0,0 +1,181 @@
+// Package watch reruns generation when the files a project is generated from change.
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// DefaultDebounce is how long the files must stay unchanged before a cycle starts.
+// Editors and generators write a file in several steps.
+const DefaultDebounce = 300 * time.Millisecond
+
+// Cycle runs a generation and returns the files to watch for the next one.
+// Changed lists the files that triggered the cycle, it is empty for the first one.
+type Cycle func(changed []string) []string
+
+// Run calls cycle once and then after every change of the files it returned, until ctx is done.
+// The directories of the files are watched rather than the files themselves, so files replaced
+// on save or created later are noticed. A cycle starts only if the content of a file changed:
+// writes of the same content, by the generation itself for example, are ignored.
+// The content is compared with the one before the cycle, so edits made while it runs are not lost.
+func Run(ctx context.Context, debounce time.Duration, cycle Cycle) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "start watcher")
+	}
+	defer fsw.Close()
+
+	w := &watcher{
+		fsw:   fsw,
+		dirs:  make(map[string]struct{}),
+		files: make(map[string]string),
+	}
+
+	var changed []string
+
+	for {
+		before := w.checksums()
+
+		w.watch(cycle(changed), before)
+
+		if changed, err = w.wait(ctx, debounce); err != nil || changed == nil {
+			return err
+		}
+	}
+}
+
+type watcher struct {
+	fsw   *fsnotify.Watcher
+	dirs  map[string]struct{}
+	files map[string]string // watched file -> checksum of its content, empty if it does not exist
+}
+
+// checksums returns a copy of the checksums of the watched files
+func (w *watcher) checksums() map[string]string {
+	sums := make(map[string]string, len(w.files))
+	for path, sum := range w.files {
+		sums[path] = sum
+	}
+
+	return sums
+}
+
+// watch replaces the watched files, adding and removing directory watches as needed.
+// Files already in before keep the checksum from it, the new ones are read now.
+func (w *watcher) watch(paths []string, before map[string]string) {
+	files := make(map[string]string, len(paths))
+	dirs := make(map[string]struct{})
+
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			slog.Warn("watch", "file", path, "err", err)
+
+			continue
+		}
+
+		if sum, ok := before[abs]; ok {
+			files[abs] = sum
+		} else {
+			files[abs] = checksum(abs)
+		}
+		dirs[filepath.Dir(abs)] = struct{}{}
+	}
+
+	for dir := range w.dirs {
+		if _, ok := dirs[dir]; !ok {
+			_ = w.fsw.Remove(dir)
+
+			delete(w.dirs, dir)
+		}
+	}
+
+	for dir := range dirs {
+		if _, ok := w.dirs[dir]; ok {
+			continue
+		}
+
+		if err := w.fsw.Add(dir); err != nil {
+			slog.Warn("watch", "dir", dir, "err", err)
+
+			continue
+		}
+
+		w.dirs[dir] = struct{}{}
+	}
+
+	w.files = files
+}
+
+// wait blocks until the content of watched files changes and returns them sorted.
+// The files are compared once without an event too: they may have changed during the cycle.
+// It returns nil when ctx is done.
+func (w *watcher) wait(ctx context.Context, debounce time.Duration) ([]string, error) {
+	timer := time.NewTimer(debounce)
+
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return nil, errors.New("watcher closed")
+			}
+
+			if _, watched := w.files[filepath.Clean(event.Name)]; watched {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil, errors.New("watcher closed")
+			}
+
+			slog.Warn("watch", "err", err)
+		case <-timer.C:
+			if changed := w.changed(); len(changed) > 0 {
+				return changed, nil
+			}
+		}
+	}
+}
+
+// changed returns the watched files with a new content and remembers it
+func (w *watcher) changed() []string {
+	changed := []string{}
+
+	for path, sum := range w.files {
+		if current := checksum(path); current != sum {
+			w.files[path] = current
+
+			changed = append(changed, path)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// checksum of the file content, empty if the file can not be read
+func checksum(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testDebounce = 50 * time.Millisecond
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "api.yaml")
+	created := filepath.Join(dir, "new.yaml")
+
+	writeFile(t, spec, "openapi: 3.0.0\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cycles := make(chan []string, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- Run(ctx, testDebounce, func(changed []string) []string {
+			cycles <- changed
+
+			return []string{spec, created}
+		})
+	}()
+
+	if changed := nextCycle(t, cycles); len(changed) != 0 {
+		t.Fatalf("first cycle changed = %v, want none", changed)
+	}
+
+	// Writing the same content does not start a cycle
+	writeFile(t, spec, "openapi: 3.0.0\n")
+
+	select {
+	case changed := <-cycles:
+		t.Fatalf("cycle after writing the same content: %v", changed)
+	case <-time.After(5 * testDebounce):
+	}
+
+	writeFile(t, spec, "openapi: 3.1.0\n")
+
+	if changed := nextCycle(t, cycles); len(changed) != 1 || changed[0] != spec {
+		t.Errorf("cycle changed = %v, want [%s]", changed, spec)
+	}
+
+	// Replacing a file on save, as editors do, and creating a watched file are noticed
+	tmp := filepath.Join(dir, ".api.yaml.swp")
+	writeFile(t, tmp, "openapi: 3.1.1\n")
+
+	if err := os.Rename(tmp, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, created, "queues: []\n")
+
+	// The changes may be split between cycles if the debounce ends in between
+	seen := map[string]bool{}
+	for !seen[spec] || !seen[created] {
+		for _, path := range nextCycle(t, cycles) {
+			seen[path] = true
+		}
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+func TestRun_EditDuringCycle(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "api.yaml")
+
+	writeFile(t, spec, "openapi: 3.0.0\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cycles := make(chan []string, 10)
+	release := make(chan struct{})
+	done := make(chan error, 1)
+
+	go func() {
+		done <- Run(ctx, testDebounce, func(changed []string) []string {
+			cycles <- changed
+
+			// The second cycle is blocked until the file is edited again
+			if len(changed) != 0 {
+				<-release
+			}
+
+			return []string{spec}
+		})
+	}()
+
+	nextCycle(t, cycles)
+
+	writeFile(t, spec, "openapi: 3.1.0\n")
+
+	if changed := nextCycle(t, cycles); len(changed) != 1 {
+		t.Fatalf("cycle changed = %v, want [%s]", changed, spec)
+	}
+
+	writeFile(t, spec, "openapi: 3.1.1\n")
+	close(release)
+
+	if changed := nextCycle(t, cycles); len(changed) != 1 || changed[0] != spec {
+		t.Errorf("cycle after the edit during a cycle changed = %v, want [%s]", changed, spec)
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+func nextCycle(t *testing.T, cycles <-chan []string) []string {
+	t.Helper()
+
+	select {
+	case changed := <-cycles:
+		return changed
+	case <-time.After(5 * time.Second):
+		t.Fatal("no cycle")
+
+		return nil
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,170 @@
+// Package starter is the library API of go-project-starter: it loads a project config, renders
+// the project in memory or generates it on disk.
+//
+// The package keeps no global state: configs and generators of different projects may be used
+// concurrently.
+package starter
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/Educentr/go-project-starter/internal/pkg/config"
+	"github.com/Educentr/go-project-starter/internal/pkg/generator"
+	"github.com/Educentr/go-project-starter/internal/pkg/meta"
+)
+
+// DefaultAppInfo names the generator in generated files if Options.AppInfo is empty
+const DefaultAppInfo = "go-project-starter"
+
+// configFileName is the name ParseConfig gives to the config
+const configFileName = "project.yaml"
+
+// LoadConfig reads configPath with its includes; a bare file name is looked up in baseDir.
+// If env is set, the overrides.<env> section of the config is applied.
+func LoadConfig(baseDir, configPath, env string) (Config, error) {
+	cfg, err := config.GetConfigEnv(baseDir, configPath, env)
+
+	return Config{cfg: cfg}, err
+}
+
+// LoadConfigFS reads configPath from fsys. Included files, spec files and the templates
+// directory are read from fsys too, relative to the directory of configPath. Such a config
+// can't use plugins: they are commands run in the config directory.
+func LoadConfigFS(fsys fs.FS, configPath, env string) (Config, error) {
+	cfg, err := config.GetConfigFS(fsys, configPath, env)
+
+	return Config{cfg: cfg}, err
+}
+
+// ParseConfig reads a config from data. The config can't include files or reference specs.
+func ParseConfig(data []byte, env string) (Config, error) {
+	return LoadConfigFS(memFS{configFileName: data}, configFileName, env)
+}
+
+// Validate returns all problems of the config LoadConfig would read, with their positions.
+// The error is returned if the config can't be read at all.
+func Validate(baseDir, configPath, env string) ([]Problem, error) {
+	problems, err := config.Validate(baseDir, configPath, env)
+
+	return newProblems(problems), err
+}
+
+// ValidateFS is Validate for the config LoadConfigFS would read
+func ValidateFS(fsys fs.FS, configPath, env string) ([]Problem, error) {
+	problems, err := config.ValidateFS(fsys, configPath, env)
+
+	return newProblems(problems), err
+}
+
+// Options of a Generator, zero values are the defaults of the command line
+type Options struct {
+	// TargetDir is the directory of the project, main.target_dir of the config if empty.
+	// The state of the last generation is read from its .project-config directory.
+	TargetDir string
+	// AppInfo names the generator in generated files, DefaultAppInfo if empty
+	AppInfo string
+	// PostGenerate selects the post_generate steps Generate runs: nil runs all steps of the config,
+	// an empty list none of them
+	PostGenerate []string
+	// Only regenerates the files selected by any of the filters, in the --only format
+	Only []string
+	// Force overwrites hand-edited generated files, their copies are saved to .project-config/backup
+	Force bool
+	// AllowDirty regenerates over uncommitted git changes in the target
+	AllowDirty bool
+	// Adopt moves content that blocks regeneration to .orphan files instead of failing, implied by Force
+	Adopt bool
+	// Backup backs up the target before writing: "archive" or "branch", none if empty
+	Backup string
+	// Logger receives the messages of the generation and the warnings about deprecated features
+	// used by the config, slog.Default() if nil
+	Logger *slog.Logger
+}
+
+// Generator generates one project
+type Generator struct {
+	gen *generator.Generator
+}
+
+// New returns the generator of the project of cfg
+func New(cfg Config, opts Options) (*Generator, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	project := cfg.cfg
+	project.LogDeprecations(logger)
+
+	if opts.TargetDir != "" {
+		project.SetTargetDir(opts.TargetDir)
+	}
+
+	genMeta, err := meta.GetMeta(filepath.Join(project.Main.TargetDir, ".project-config"), "meta.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load meta: %w", err)
+	}
+
+	appInfo := opts.AppInfo
+	if appInfo == "" {
+		appInfo = DefaultAppInfo
+	}
+
+	gen, err := generator.New(appInfo, project, genMeta, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.PostGenerate != nil {
+		if err = gen.SelectPostGenerate(opts.PostGenerate); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, value := range opts.Only {
+		filter, err := generator.ParseScopeFilter(value)
+		if err != nil {
+			return nil, fmt.Errorf("only: %w", err)
+		}
+
+		gen.Only = append(gen.Only, filter)
+	}
+
+	gen.Force = opts.Force
+	gen.AllowDirty = opts.AllowDirty
+	gen.Adopt = opts.Adopt || opts.Force
+	gen.Backup = opts.Backup
+	gen.Log = logger
+
+	return &Generator{gen: gen}, nil
+}
+
+// Render renders the project in memory, nothing is written. The returned file system holds
+// the files Generate would write, with the user code of the target kept; paths are relative
+// to the target. Specs and the config, which Generate copies to the target, are not included.
+func (g *Generator) Render() (fs.FS, FilesDiff, error) {
+	rendered, filesDiff, err := g.gen.Render()
+	if err != nil {
+		return nil, FilesDiff{}, err
+	}
+
+	return memFS(rendered), FilesDiff(filesDiff), nil
+}
+
+// Generate writes the project to the target directory and runs the post_generate steps.
+// The report is returned for a failed generation too.
+func (g *Generator) Generate() (Report, error) {
+	err := g.gen.Generate()
+
+	return newReport(g.gen.Report), err
+}
+
+// Diff writes a unified diff of the target against the project Generate would write to w.
+// It reports whether generation would change anything.
+func (g *Generator) Diff(w io.Writer) (bool, error) {
+	return g.gen.Diff(w)
+}
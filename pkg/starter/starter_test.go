@@ -0,0 +1,296 @@
+package starter
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func projectFS(name string) fstest.MapFS {
+	return fstest.MapFS{
+		"config/project.yaml": {Data: []byte(fmt.Sprintf(`main:
+  name: %s
+  registry_type: github
+  logger: zerolog
+include: cli.yaml
+applications:
+  - name: admin
+    cli: admin
+`, name))},
+		"config/cli.yaml": {Data: []byte(`cli:
+  - name: admin
+    generator_type: template
+    generator_template: cli
+`)},
+	}
+}
+
+func TestRender(t *testing.T) {
+	names := []string{"shop", "billing", "geo", "mail"}
+	rendered := make([]fs.FS, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			cfg, err := LoadConfigFS(projectFS(name), "config/project.yaml", "")
+			if err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			gen, err := New(cfg, Options{TargetDir: filepath.Join(t.TempDir(), name)})
+			if err != nil {
+				errs[i] = err
+
+				return
+			}
+
+			rendered[i], _, errs[i] = gen.Render()
+		}()
+	}
+
+	wg.Wait()
+
+	for i, name := range names {
+		if errs[i] != nil {
+			t.Fatalf("Render(%s) error = %v", name, errs[i])
+		}
+
+		makefile, err := fs.ReadFile(rendered[i], "Makefile")
+		if err != nil {
+			t.Fatalf("Render(%s) has no Makefile: %v", name, err)
+		}
+
+		if !bytes.Contains(makefile, []byte(name)) {
+			t.Errorf("Render(%s) Makefile does not mention the project", name)
+		}
+
+		if _, err := fs.Stat(rendered[i], "cmd/admin"); err != nil {
+			t.Errorf("Render(%s) has no cmd/admin: %v", name, err)
+		}
+	}
+}
+
+func TestRender_FS(t *testing.T) {
+	cfg, err := LoadConfigFS(projectFS("shop"), "config/project.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen, err := New(cfg, Options{TargetDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, _, err := gen.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = fstest.TestFS(rendered, "Makefile", "cmd/admin"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRender_KeepsUserCode(t *testing.T) {
+	cfg, err := LoadConfigFS(projectFS("shop"), "config/project.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := t.TempDir()
+
+	gen, err := New(cfg, Options{TargetDir: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, filesDiff, err := gen.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(filesDiff.NewFiles) == 0 {
+		t.Error("Render() FilesDiff has no new files for an empty target")
+	}
+
+	// The target is not written
+	if entries, _ := os.ReadDir(target); len(entries) != 0 {
+		t.Errorf("Render() wrote %d entries to the target", len(entries))
+	}
+
+	makefile, err := fs.ReadFile(rendered, "Makefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(filepath.Join(target, "Makefile"), append(makefile, "\nuser-target:\n"...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if rendered, filesDiff, err = gen.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	if makefile, _ = fs.ReadFile(rendered, "Makefile"); !bytes.Contains(makefile, []byte("user-target:")) {
+		t.Error("Render() lost the user code of the Makefile")
+	}
+
+	if _, ok := filesDiff.UserContent[filepath.Join(target, "Makefile")]; !ok {
+		t.Error("Render() FilesDiff has no user content of the Makefile")
+	}
+}
+
+func TestNew_Logger(t *testing.T) {
+	fsys := projectFS("shop")
+	fsys["config/cli.yaml"].Data = append(fsys["config/cli.yaml"].Data,
+		"rest:\n  - name: sys\n    port: 8085\n    version: v1\n    generator_type: template\n    generator_template: sys\n    empty_config_available: false\n"...)
+	fsys["config/project.yaml"].Data = append(fsys["config/project.yaml"].Data, "  - name: api\n    transport:\n      - name: sys\n"...)
+
+	cfg, err := LoadConfigFS(fsys, "config/project.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if _, err = New(cfg, Options{TargetDir: t.TempDir(), Logger: slog.New(slog.NewTextHandler(&buf, nil))}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "feature=empty_config_available") {
+		t.Errorf("New() logged\n%s\nwant the deprecated empty_config_available", buf.String())
+	}
+}
+
+func TestRender_DocsHeadersSecrets(t *testing.T) {
+	// Placeholders of documentation.headers are GitHub secrets, not environment variables of the generation
+	t.Setenv("DOCS_TOKEN", "s3cr3t")
+
+	fsys := projectFS("shop")
+	fsys["config/project.yaml"].Data = append([]byte("documentation:\n  type: minio\n  headers:\n    - \"Authorization: Bearer ${DOCS_TOKEN}\"\n"),
+		fsys["config/project.yaml"].Data...)
+
+	cfg, err := LoadConfigFS(fsys, "config/project.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen, err := New(cfg, Options{TargetDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, _, err := gen.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workflow, err := fs.ReadFile(rendered, ".github/workflows/ci_cd.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(workflow, []byte(`-H "Authorization: Bearer ${{ secrets.DOCS_TOKEN }}"`)) || bytes.Contains(workflow, []byte("s3cr3t")) {
+		t.Error("Render() did not keep the secret placeholder of documentation.headers in the CI workflow")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`main:
+  name: shop
+  registry_type: github
+  logger: zerolog
+overrides:
+  prod:
+    main:
+      name: shop-prod
+`), "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Name() != "shop-prod" {
+		t.Errorf("ParseConfig() name = %s, want shop-prod", cfg.Name())
+	}
+
+	if _, err = ParseConfig([]byte("main:\n  name: shop\n"), ""); err == nil {
+		t.Error("ParseConfig() of an invalid config succeeded")
+	}
+}
+
+func TestValidateFS(t *testing.T) {
+	fsys := projectFS("shop")
+	fsys["config/cli.yaml"] = &fstest.MapFile{Data: []byte("cli:\n  - name: admin\n    generator_type: template\n    generatr_template: cli\n")}
+
+	problems, err := ValidateFS(fsys, "config/project.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(problems) == 0 || problems[0].File != "config/cli.yaml" {
+		t.Errorf("ValidateFS() = %+v, want a problem in config/cli.yaml", problems)
+	}
+}
+
+func TestRender_GoModWebsocket(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		transports string
+		apps       string
+		want       bool
+	}{
+		{name: "without ws", want: false},
+		{
+			name:       "with ws",
+			transports: "ws:\n  - name: chat\n    path: ./chat.yaml\n    port: 8095\n",
+			apps:       "  - name: api\n    transport:\n      - name: chat\n",
+			want:       true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := projectFS("chat")
+			fsys["config/chat.yaml"] = &fstest.MapFile{Data: []byte("messages:\n  - name: join\n")}
+			fsys["config/cli.yaml"].Data = append([]byte(tt.transports), fsys["config/cli.yaml"].Data...)
+			fsys["config/project.yaml"].Data = append(fsys["config/project.yaml"].Data, tt.apps...)
+
+			cfg, err := LoadConfigFS(fsys, "config/project.yaml", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gen, err := New(cfg, Options{TargetDir: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rendered, _, err := gen.Render()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gomod, err := fs.ReadFile(rendered, "go.mod")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := bytes.Contains(gomod, []byte("github.com/gorilla/websocket")); got != tt.want {
+				t.Errorf("go.mod requires gorilla/websocket = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
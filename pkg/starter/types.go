@@ -0,0 +1,138 @@
+package starter
+
+import (
+	"github.com/Educentr/go-project-starter/internal/pkg/config"
+	"github.com/Educentr/go-project-starter/internal/pkg/generator"
+)
+
+// Config is a loaded and validated project config
+type Config struct {
+	cfg config.Config
+}
+
+// Name is main.name of the config
+func (c Config) Name() string {
+	return c.cfg.Main.Name
+}
+
+// TargetDir is main.target_dir of the config, Options.TargetDir takes precedence over it
+func (c Config) TargetDir() string {
+	return c.cfg.Main.TargetDir
+}
+
+// Env is the environment whose overrides section is applied, empty for none
+func (c Config) Env() string {
+	return c.cfg.Env
+}
+
+// Problem is an invalid value of a config found by Validate
+type Problem struct {
+	File    string `json:"file,omitempty"` // file defining the value, empty if it is not found in the config
+	Line    int    `json:"line,omitempty"` // position of the value in File
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path"`             // path of the value, e.g. applications[0].transport[1]
+	Entity  string `json:"entity,omitempty"` // name of the entity the value belongs to
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"` // suggested fix, empty if there is no obvious one
+}
+
+// String formats the problem as file:line:column: path (entity): message
+func (p Problem) String() string {
+	return config.Problem(p).String()
+}
+
+func newProblems(problems []config.Problem) []Problem {
+	if problems == nil {
+		return nil
+	}
+
+	list := make([]Problem, 0, len(problems))
+	for _, p := range problems {
+		list = append(list, Problem(p))
+	}
+
+	return list
+}
+
+// FilesDiff tells what generation does with the files of the target. Paths are absolute.
+type FilesDiff struct {
+	NewFiles       map[string]struct{}          // files the target does not have yet
+	IgnoreFiles    map[string]struct{}          // files the generator never overwrites
+	OtherFiles     map[string]struct{}          // files of the target the generator does not write
+	ObsoleteFiles  map[string]struct{}          // generated files no longer generated, removed by Generate
+	NewDirectory   map[string]struct{}          // directories the target does not have yet
+	OtherDirectory map[string]struct{}          // directories of the target the generator does not create
+	UserContent    map[string][]byte            // user code below the disclaimer, kept by the generation
+	UserRegions    map[string]map[string]string // bodies of named user code regions by file and region id
+	RenameFiles    map[string]string            // old name -> new name of the files moved by layout migrations
+	Orphans        map[string][]byte            // content saved to .orphan files, see Options.Adopt
+}
+
+// Report is what Generate did, it is marshaled to the JSON of --report=json.
+// Paths are relative to the target.
+type Report struct {
+	Target       string          `json:"target"`
+	DryRun       bool            `json:"dry_run"`
+	Created      []string        `json:"created"`
+	Updated      []string        `json:"updated"`
+	Renamed      []FileRename    `json:"renamed"`
+	Removed      []string        `json:"removed"`
+	Ignored      []string        `json:"ignored"`   // files the generator never overwrites
+	Preserved    []PreservedFile `json:"preserved"` // regenerated files with user code
+	Merged       []string        `json:"merged"`    // hand edits merged with the new generation
+	Conflicts    []string        `json:"conflicts"` // hand edits written with conflict markers
+	PostGenerate []StepResult    `json:"post_generate"`
+	Warnings     []string        `json:"warnings"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// FileRename is a file moved to a new name, its content may change too
+type FileRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PreservedFile is a regenerated file with user code below the disclaimer or in named regions
+type PreservedFile struct {
+	Path          string   `json:"path"`
+	UserCodeBytes int      `json:"user_code_bytes"`
+	Regions       []string `json:"regions,omitempty"`
+}
+
+// StepResult is a post_generate command run
+type StepResult struct {
+	Step       string `json:"step"`
+	Command    string `json:"command"`
+	DurationMs int64  `json:"duration_ms"`
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func newReport(r generator.Report) Report {
+	report := Report{
+		Target:    r.Target,
+		DryRun:    r.DryRun,
+		Created:   r.Created,
+		Updated:   r.Updated,
+		Removed:   r.Removed,
+		Ignored:   r.Ignored,
+		Merged:    r.Merged,
+		Conflicts: r.Conflicts,
+		Warnings:  r.Warnings,
+		Error:     r.Error,
+	}
+
+	for _, rename := range r.Renamed {
+		report.Renamed = append(report.Renamed, FileRename(rename))
+	}
+
+	for _, file := range r.Preserved {
+		report.Preserved = append(report.Preserved, PreservedFile(file))
+	}
+
+	for _, step := range r.PostGenerate {
+		report.PostGenerate = append(report.PostGenerate, StepResult(step))
+	}
+
+	return report
+}
@@ -197,6 +197,30 @@ func getTestConfigs() map[string]testConfig {
 			serviceName: "grpcclienttest",
 			projectName: "grpcclienttest",
 		},
+		"grpc-server": {
+			name:        "grpc-server",
+			configDir:   "grpc-server",
+			appName:     "api",
+			requiresTG:  false,
+			serviceName: "grpcservertest",
+			projectName: "grpcservertest",
+		},
+		"ws": {
+			name:        "ws",
+			configDir:   "ws",
+			appName:     "api",
+			requiresTG:  false,
+			serviceName: "wstest",
+			projectName: "wstest",
+		},
+		"kafka-consumer": {
+			name:        "kafka-consumer",
+			configDir:   "kafka-consumer",
+			appName:     "api",
+			requiresTG:  false,
+			serviceName: "kafkaconsumertest",
+			projectName: "kafkaconsumertest",
+		},
 		"worker-telegram": {
 			name:        "worker-telegram",
 			configDir:   "worker-telegram",
@@ -482,6 +506,33 @@ func TestIntegrationGRPCOnly(t *testing.T) {
 	runTest(t, "grpc-only", "grpc-only")
 }
 
+// TestIntegrationGRPCServer tests gRPC server (buf_server) project generation
+func TestIntegrationGRPCServer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	runTest(t, "grpc-server", "grpc-server")
+}
+
+// TestIntegrationWs tests WebSocket server project generation
+func TestIntegrationWs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	runTest(t, "ws", "ws")
+}
+
+// TestIntegrationKafkaConsumer tests Kafka producer and consumer project generation
+func TestIntegrationKafkaConsumer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	runTest(t, "kafka-consumer", "kafka-consumer")
+}
+
 // TestIntegrationWorkerTelegram tests Telegram worker project generation
 func TestIntegrationWorkerTelegram(t *testing.T) {
 	if testing.Short() {
@@ -869,6 +869,7 @@ func TestObsoleteFileCleanup(t *testing.T) {
 		"--target", tmpDir,
 		"--configDir", exampleDir,
 		"--config", "project.yaml",
+		"--allow-dirty",
 	}, "Regenerate project to test obsolete cleanup ("+tmpDir+")")
 	if err != nil {
 		t.Fatalf("Regeneration failed: %s\n%s", err, out)